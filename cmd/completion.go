@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/api/sabnzbd"
+	"sonarr-sabnzbd-cli/internal/api/sonarr"
+	"sonarr-sabnzbd-cli/internal/cache"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// How long completion reuses what it fetched from the servers. The queue
+// changes by the minute, the library and settings rarely.
+const (
+	CompletionCacheTTL      = 10 * time.Minute
+	QueueCompletionCacheTTL = 15 * time.Second
+)
+
+// completionTimeout bounds the requests of a completion, which the shell
+// waits for
+const completionTimeout = 5 * time.Second
+
+// CompleteSonarr returns a completion function offering what list fetches
+// from Sonarr, cached on disk under name for ttl
+func CompleteSonarr(name string, ttl time.Duration, list func(ctx context.Context, client *sonarr.Client) ([]cobra.Completion, error)) cobra.CompletionFunc {
+	return completeFrom("sonarr", name, ttl, func(ctx context.Context) ([]cobra.Completion, error) {
+		client, err := GetSonarrClient()
+		if err != nil {
+			return nil, err
+		}
+		return list(ctx, client)
+	})
+}
+
+// CompleteSabnzbd returns a completion function offering what list
+// fetches from Sabnzbd, cached on disk under name for ttl
+func CompleteSabnzbd(name string, ttl time.Duration, list func(ctx context.Context, client *sabnzbd.Client) ([]cobra.Completion, error)) cobra.CompletionFunc {
+	return completeFrom("sabnzbd", name, ttl, func(ctx context.Context) ([]cobra.Completion, error) {
+		client, err := GetSabnzbdClient()
+		if err != nil {
+			return nil, err
+		}
+		return list(ctx, client)
+	})
+}
+
+// FirstArg limits complete to the first argument, for commands taking one
+func FirstArg(complete cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(command *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(command, args, toComplete)
+	}
+}
+
+// completeFrom returns a completion function offering what fetch returns.
+// Failures offer nothing rather than print into the command line; they
+// are logged with BASH_COMP_DEBUG_FILE.
+func completeFrom(service, name string, ttl time.Duration, fetch func(ctx context.Context) ([]cobra.Completion, error)) cobra.CompletionFunc {
+	return func(command *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		completions, err := cachedCompletions(command, service, name, ttl, fetch)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// cachedCompletions returns the completions cached for the server of the
+// current instance, or fetches and caches them. Completion runs without
+// the configuration, so it is loaded here first.
+func cachedCompletions(command *cobra.Command, service, name string, ttl time.Duration, fetch func(ctx context.Context) ([]cobra.Completion, error)) ([]cobra.Completion, error) {
+	// Nobody would see a passphrase prompt while the shell completes
+	secrets.SetPrompts(false)
+	defer secrets.SetPrompts(true)
+
+	configFile, _ := command.Flags().GetString("config")
+	config.SetConfigFile(configFile)
+	if err := loadConfig(true); err != nil {
+		return nil, err
+	}
+
+	scope, err := ServerScope(service)
+	if err != nil {
+		return nil, err
+	}
+	key := "completion/" + service + "/" + name + "/" + scope
+	store, storeErr := cache.Default()
+	var completions []cobra.Completion
+	if storeErr == nil && store.Get(key, ttl, &completions) {
+		return completions, nil
+	}
+
+	ctx := command.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+	completions, err = fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if storeErr == nil {
+		store.Set(key, completions)
+	}
+	return completions, nil
+}
+
+// ServerScope identifies the configuration file and the sonarr or sabnzbd
+// server of the current instance, so that cached completions and other
+// state are not shared between servers that happen to have the same
+// instance name. It is hashed, as cache keys are stored in plain text and
+// URLs may hold credentials.
+func ServerScope(service string) (string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", err
+	}
+	name := config.InstanceName(cfg, instanceFlag(""))
+	selected, err := config.SelectInstance(cfg, name)
+	if err != nil {
+		return "", err
+	}
+
+	server := []any{selected.Sonarr.URL, selected.Sonarr.Host, selected.Sonarr.Port, selected.Sonarr.URLBase}
+	if service == "sabnzbd" {
+		server = []any{selected.Sabnzbd.URL, selected.Sabnzbd.Host, selected.Sabnzbd.Port, selected.Sabnzbd.URLBase}
+	}
+	sum := sha256.Sum256(fmt.Appendln(nil, append([]any{path, name}, server...)...))
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// completeWith returns the completions of a command run with --config path,
+// counting the fetches in fetches
+func completeWith(t *testing.T, path string, ttl time.Duration, fetches *int) []cobra.Completion {
+	t.Helper()
+	command := &cobra.Command{}
+	command.Flags().String("config", "", "")
+	if path != "" {
+		command.Flags().Set("config", path)
+	}
+	t.Cleanup(func() { config.SetConfigFile("") })
+
+	completions, err := cachedCompletions(command, "sabnzbd", "test", ttl, func(ctx context.Context) ([]cobra.Completion, error) {
+		*fetches++
+		return []cobra.Completion{fmt.Sprint("fetch", *fetches)}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return completions
+}
+
+func TestCompletionCache(t *testing.T) {
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	fetches := 0
+
+	completeWith(t, "", time.Hour, &fetches)
+	if got := completeWith(t, "", time.Hour, &fetches); fetches != 1 || got[0] != "fetch1" {
+		t.Errorf("second completion fetched again: %v after %d fetches", got, fetches)
+	}
+
+	if completeWith(t, "", time.Nanosecond, &fetches); fetches != 2 {
+		t.Errorf("expired completions were not fetched again: %d fetches", fetches)
+	}
+}
+
+func TestCompletionCacheScope(t *testing.T) {
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	fetches := 0
+	completeWith(t, "", time.Hour, &fetches)
+
+	// Another file for the same instance, pointing at another server
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(t.TempDir(), "other.yaml")
+	port := fmt.Sprintf("port: %d", env.Sabnzbd.Config().Port)
+	if err := os.WriteFile(other, []byte(strings.Replace(string(data), port, "port: 1", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := completeWith(t, other, time.Hour, &fetches); fetches != 2 || got[0] != "fetch2" {
+		t.Errorf("completions of another configuration were reused: %v after %d fetches", got, fetches)
+	}
+	if got := completeWith(t, "", time.Hour, &fetches); fetches != 2 || got[0] != "fetch1" {
+		t.Errorf("completions of the first configuration were lost: %v after %d fetches", got, fetches)
+	}
+}
+
+func TestCompletionWithoutPrompts(t *testing.T) {
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	stdin := os.Stdin
+	os.Stdin = null
+	defer func() { os.Stdin = stdin }()
+	t.Setenv(secrets.PassphraseEnv, "")
+
+	command := &cobra.Command{}
+	command.Flags().String("config", "", "")
+	_, err = cachedCompletions(command, "sabnzbd", "test", time.Hour, func(ctx context.Context) ([]cobra.Completion, error) {
+		_, err := secrets.Passphrase("")
+		return nil, err
+	})
+	if err == nil || !strings.Contains(err.Error(), "secret store is locked") {
+		t.Errorf("completion error = %v, want the store to stay locked", err)
+	}
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/output"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change the CLI configuration",
+	Long: `Commands for the configuration file of this CLI.
+
+Keys are the paths of the configuration file, such as ui.max_results,
+sonarr.timeout or instances.4k.sonarr.port. These commands run even when
+the configuration fails validation, so they can be used to repair it. To
+manage Sabnzbd's own settings, use "sabnzbd config".
+
+Examples:
+  sonarr-sabnzbd-cli config view
+  sonarr-sabnzbd-cli config get ui.max_results
+  sonarr-sabnzbd-cli config set sonarr.timeout 10s
+  sonarr-sabnzbd-cli config edit
+  sonarr-sabnzbd-cli config path
+  sonarr-sabnzbd-cli config validate`,
+	Annotations: map[string]string{SkipValidationAnnotation: "true"},
+}
+
+// configViewCmd represents the config view command
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Show the configuration with secrets masked",
+	Long: `Show the whole configuration, including default values, as YAML. API keys
+and passwords are masked unless --show-secrets is given.
+
+With --origin, every key is listed with its value and where the value came
+from: a flag, an environment variable, the configuration file or the
+defaults.
+
+Examples:
+  sonarr-sabnzbd-cli config view
+  sonarr-sabnzbd-cli config view --origin
+  sonarr-sabnzbd-cli config view -o json
+  SONCLI_SONARR_URL=http://nas:8989 sonarr-sabnzbd-cli config view --origin`,
+	Args: cobra.NoArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		showSecrets, _ := command.Flags().GetBool("show-secrets")
+		if origin, _ := command.Flags().GetBool("origin"); origin {
+			return printOrigins(showSecrets, OutputFormat(command))
+		}
+		shown := cfg
+		if !showSecrets {
+			shown = config.Redact(cfg)
+		}
+		return printConfig(OutputFormat(command), shown)
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Show a configuration value or section",
+	Long: `Show a single value, or a whole section as YAML.
+
+Examples:
+  sonarr-sabnzbd-cli config get ui.max_results
+  sonarr-sabnzbd-cli config get sonarr`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		showSecrets, _ := command.Flags().GetBool("show-secrets")
+		shown := cfg
+		if !showSecrets {
+			shown = config.Redact(cfg)
+		}
+
+		value, err := config.Get(shown, args[0])
+		if err != nil {
+			return err
+		}
+
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Struct, reflect.Map, reflect.Pointer:
+			return printConfig(OutputFormat(command), value)
+		}
+		if format := OutputFormat(command); !format.Text() {
+			return output.Print(format, value, nil)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a configuration value",
+	Long: `Change a single value. The value is parsed as the type of the key, so
+durations need a unit and numbers and booleans are checked. A change that
+makes the configuration invalid is not saved.
+
+Examples:
+  sonarr-sabnzbd-cli config set sonarr.timeout 10s
+  sonarr-sabnzbd-cli config set ui.max_results 20
+  sonarr-sabnzbd-cli config set instances.4k.sonarr.port 8990`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(command *cobra.Command, args []string) error {
+		key, value := strings.ToLower(args[0]), args[1]
+
+		// Problems that were already there don't block the change
+		before := config.Validate(cfg, nil)
+		if err := config.Set(cfg, key, value); err != nil {
+			return err
+		}
+		var problems []config.Problem
+		for _, problem := range config.Validate(cfg, nil) {
+			if !slices.Contains(before, problem) {
+				problems = append(problems, problem)
+			}
+		}
+		if len(problems) > 0 {
+			return &config.ValidationError{Problems: problems}
+		}
+
+		if err := config.SaveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		saved, err := config.Get(config.Redact(cfg), key)
+		if err != nil {
+			return err
+		}
+		return Done(command, map[string]any{"key": key, "value": saved}, "✅ Set %s to %v", key, saved)
+	},
+}
+
+// configEditCmd represents the config edit command
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the configuration file in $EDITOR",
+	Long: `Open a copy of the configuration file in $VISUAL or $EDITOR. When the
+editor exits the copy is validated, and only replaces the configuration
+file if it is valid. Otherwise you can edit it again or give up.`,
+	Args: cobra.NoArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+
+		original, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			original, err = yaml.Marshal(cfg)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+
+		tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.Write(original)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write temporary file: %w", err)
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			if err := runEditor(tmp.Name()); err != nil {
+				return err
+			}
+			edited, err := os.ReadFile(tmp.Name())
+			if err != nil {
+				return fmt.Errorf("failed to read edited config: %w", err)
+			}
+			if bytes.Equal(edited, original) {
+				fmt.Println("Configuration unchanged.")
+				return nil
+			}
+
+			err = config.ValidateData(edited)
+			if err == nil {
+				if err := secrets.WriteFile(path, edited); err != nil {
+					return fmt.Errorf("failed to save config: %w", err)
+				}
+				fmt.Printf("✅ Saved %s\n", path)
+				return nil
+			}
+
+			fmt.Printf("❌ %v\n", err)
+			if !confirm(reader, "Edit again?", true) {
+				return err
+			}
+		}
+	},
+}
+
+// configPathCmd represents the config path command
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the path of the configuration file",
+	Args:  cobra.NoArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		return Done(command, map[string]any{"path": path}, "%s", path)
+	},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration file for problems",
+	Long: `Check the configuration file and report every problem with its path,
+such as unknown keys, invalid URLs, ports and durations, and API keys that
+are not set anywhere. Exits with code 11 if there are problems.
+
+Examples:
+  sonarr-sabnzbd-cli config validate`,
+	RunE: func(command *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+
+		var problems []config.Problem
+		loaded, err := config.LoadConfig()
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			problems = invalid.Problems
+		} else if err != nil {
+			return err
+		}
+		problems = append(problems, config.CheckSecrets(loaded)...)
+
+		if len(problems) > 0 {
+			return &config.ValidationError{File: path, Problems: problems}
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("⚠️  No configuration file at %s; run setup to create one\n", path)
+			return nil
+		}
+		fmt.Printf("✅ %s is valid\n", path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configViewCmd.Flags().Bool("show-secrets", false, "Show API keys and passwords")
+	configViewCmd.Flags().Bool("origin", false, "Show where each value came from")
+	configGetCmd.Flags().Bool("show-secrets", false, "Show API keys and passwords")
+}
+
+// printOrigins lists every key with its value and origin
+func printOrigins(showSecrets bool, format output.Format) error {
+	shown := cfg
+	if !showSecrets {
+		shown = config.Redact(cfg)
+	}
+	origins := config.Origins(shown)
+	for i, origin := range origins {
+		if slices.Contains(config.SecretNames, origin.Key) && !showSecrets && origin.Value != "" {
+			origins[i].Value = config.Redacted
+		}
+	}
+
+	table := output.NewTable("KEY", "VALUE", "ORIGIN")
+	for _, origin := range origins {
+		table.Row(origin.Key, origin.Value, origin.Origin)
+	}
+	return output.Print(format, origins, table)
+}
+
+// printConfig writes v as YAML, like the configuration file, unless
+// another structured format is selected. The keys are those of the file
+// in every format.
+func printConfig(format output.Format, v any) error {
+	if format.Text() || format == output.FormatYAML {
+		return printYAML(v)
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	var plain any
+	if err := yaml.Unmarshal(data, &plain); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return output.Print(format, plain, nil)
+}
+
+// printYAML writes v to stdout, indented like the configuration file
+func printYAML(v any) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	return encoder.Encode(v)
+}
+
+// runEditor opens path in the user's editor and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Editors such as "code --wait" come with arguments
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
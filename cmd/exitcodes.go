@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/config"
+)
+
+// Exit codes returned by the CLI. Scripts rely on these values, so existing
+// codes must never be renumbered.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitAuth        = 3
+	ExitNotFound    = 4
+	ExitValidation  = 5
+	ExitConflict    = 6
+	ExitConnection  = 7
+	ExitTimeout     = 8
+	ExitServer      = 9
+	ExitAPI         = 10
+	ExitConfig      = 11
+	ExitInterrupted = 130
+)
+
+// usageError marks an error caused by invalid flags or arguments
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// ExitCode maps an error returned by Execute to an exit code
+func ExitCode(err error) int {
+	var usage *usageError
+	var invalid *config.ValidationError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.As(err, &usage):
+		return ExitUsage
+	case errors.As(err, &invalid):
+		return ExitConfig
+	case errors.Is(err, apierror.ErrAuth):
+		return ExitAuth
+	case errors.Is(err, apierror.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, apierror.ErrValidation):
+		return ExitValidation
+	case errors.Is(err, apierror.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, apierror.ErrConnection):
+		return ExitConnection
+	case errors.Is(err, apierror.ErrTimeout):
+		return ExitTimeout
+	case errors.Is(err, apierror.ErrServer):
+		return ExitServer
+	case errors.Is(err, apierror.ErrAPI):
+		return ExitAPI
+	default:
+		return ExitError
+	}
+}
+
+// wrapUsageError is installed as the flag error handler so that invalid
+// flags exit with ExitUsage
+func wrapUsageError(_ *cobra.Command, err error) error {
+	return &usageError{err: err}
+}
+
+// wrapArgsOnce wraps the argument validators once every command is added
+var wrapArgsOnce sync.Once
+
+// wrapArgsErrors makes the argument validators of command and its
+// subcommands, such as cobra.ExactArgs, return usage errors, so that a
+// wrong number of arguments exits with ExitUsage like an invalid flag.
+func wrapArgsErrors(command *cobra.Command) {
+	if validate := command.Args; validate != nil {
+		command.Args = func(command *cobra.Command, args []string) error {
+			err := validate(command, args)
+			var usage *usageError
+			if err == nil || errors.As(err, &usage) {
+				return err
+			}
+			return &usageError{err: err}
+		}
+	}
+	for _, child := range command.Commands() {
+		wrapArgsErrors(child)
+	}
+}
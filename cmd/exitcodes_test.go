@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/config"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{&usageError{err: errors.New("unknown flag")}, ExitUsage},
+		{fmt.Errorf("failed to get series: %w", apierror.ErrAuth), ExitAuth},
+		{fmt.Errorf("failed to get series: %w", apierror.ErrNotFound), ExitNotFound},
+		{apierror.ErrValidation, ExitValidation},
+		{apierror.ErrConflict, ExitConflict},
+		{apierror.ErrConnection, ExitConnection},
+		{apierror.ErrTimeout, ExitTimeout},
+		{apierror.ErrServer, ExitServer},
+		{apierror.ErrAPI, ExitAPI},
+		{fmt.Errorf("failed to load config: %w", &config.ValidationError{}), ExitConfig},
+		{fmt.Errorf("failed to get queue: %w", context.Canceled), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestExitCodeUnknownFlag(t *testing.T) {
+	_, err := env.Run(t, "status", "--bogus")
+	if got := ExitCode(err); got != ExitUsage {
+		t.Errorf("ExitCode(%v) = %d, want %d", err, got, ExitUsage)
+	}
+}
+
+func TestExitCodeWrongArgs(t *testing.T) {
+	tests := [][]string{
+		{"context", "use"},
+		{"context", "use", "4k", "hd"},
+		{"status", "extra"},
+	}
+	for _, args := range tests {
+		_, err := env.Run(t, args...)
+		if got := ExitCode(err); got != ExitUsage {
+			t.Errorf("%v: ExitCode(%v) = %d, want %d", args, err, got, ExitUsage)
+		}
+	}
+}
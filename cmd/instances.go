@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/api/httpclient"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Switch between named Sonarr and Sabnzbd instances",
+	Long: `Switch between named instances defined in the configuration file.
+
+The top-level sonarr and sabnzbd settings form the "default" instance. Named
+instances go under "instances"; a service an instance leaves out is shared
+with the default instance:
+
+  instances:
+    4k:
+      sonarr:
+        port: 8990
+        api_key_cmd: "pass show sonarr-4k"
+
+A single command can use another instance with --instance (or --context).
+Read commands such as "sonarr series" and "sabnzbd queue" query every
+instance with --all-instances.
+
+Examples:
+  sonarr-sabnzbd-cli context list
+  sonarr-sabnzbd-cli context use 4k
+  sonarr-sabnzbd-cli sonarr series --instance default
+  sonarr-sabnzbd-cli sonarr series --all-instances`,
+}
+
+// contextListCmd represents the context list command
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List instances",
+	RunE: func(command *cobra.Command, args []string) error {
+		current := config.InstanceName(cfg, "")
+
+		type instanceInfo struct {
+			Name       string `json:"name"`
+			Current    bool   `json:"current"`
+			SonarrURL  string `json:"sonarrUrl"`
+			SabnzbdURL string `json:"sabnzbdUrl"`
+		}
+		var instances []instanceInfo
+		for _, name := range config.InstanceNames(cfg) {
+			selected, err := config.SelectInstance(cfg, name)
+			if err != nil {
+				return err
+			}
+			instances = append(instances, instanceInfo{
+				Name:       name,
+				Current:    name == current,
+				SonarrURL:  serviceURL(selected.Sonarr.URL, selected.Sonarr.Host, selected.Sonarr.Port, selected.Sonarr.URLBase),
+				SabnzbdURL: serviceURL(selected.Sabnzbd.URL, selected.Sabnzbd.Host, selected.Sabnzbd.Port, selected.Sabnzbd.URLBase),
+			})
+		}
+
+		table := output.NewTable("CURRENT", "NAME", "SONARR", "SABNZBD")
+		for _, instance := range instances {
+			mark := ""
+			if instance.Current {
+				mark = output.Green("*")
+			}
+			table.Row(mark, instance.Name, instance.SonarrURL, instance.SabnzbdURL)
+		}
+		return output.Print(OutputFormat(command), instances, table)
+	},
+}
+
+// contextCurrentCmd represents the context current command
+var contextCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the current instance",
+	RunE: func(command *cobra.Command, args []string) error {
+		name := config.InstanceName(cfg, "")
+		return Done(command, map[string]any{"name": name}, "%s", name)
+	},
+}
+
+// contextUseCmd represents the context use command
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make an instance the current one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if _, err := config.SelectInstance(cfg, name); err != nil {
+			return err
+		}
+
+		cfg.CurrentInstance = name
+		if name == config.DefaultInstance {
+			cfg.CurrentInstance = ""
+		}
+		if err := config.SaveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		return Done(command, map[string]any{"name": name}, "✅ Switched to instance '%s'", name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextListCmd)
+	contextCmd.AddCommand(contextCurrentCmd)
+	contextCmd.AddCommand(contextUseCmd)
+}
+
+// instanceFlag returns name, or the instance given with --instance or
+// --context if name is empty
+func instanceFlag(name string) string {
+	if name != "" {
+		return name
+	}
+	if name, _ := rootCmd.PersistentFlags().GetString("instance"); name != "" {
+		return name
+	}
+	name, _ = rootCmd.PersistentFlags().GetString("context")
+	return name
+}
+
+// serviceURL returns the base URL of a service for display
+func serviceURL(rawURL, host string, port int, urlBase string) string {
+	u, err := httpclient.BaseURL(rawURL, host, port, urlBase)
+	if err != nil {
+		return "invalid URL: " + err.Error()
+	}
+	return u.String()
+}
+
+// AllInstances reports whether a read command was run with
+// --all-instances
+func AllInstances(command *cobra.Command) bool {
+	all, _ := command.Flags().GetBool("all-instances")
+	return all
+}
+
+// SonarrInstances returns the instances a read command queries: those with
+// their own Sonarr server with --all-instances, otherwise the current one
+func SonarrInstances(command *cobra.Command) []string {
+	return queryInstances(command, config.SonarrInstances)
+}
+
+// SabnzbdInstances returns the instances a read command queries: those
+// with their own Sabnzbd server with --all-instances, otherwise the current
+// one
+func SabnzbdInstances(command *cobra.Command) []string {
+	return queryInstances(command, config.SabnzbdInstances)
+}
+
+// queryInstances returns the instances to query
+func queryInstances(command *cobra.Command, all func(*models.Config) []string) []string {
+	if cfg == nil || !AllInstances(command) {
+		return []string{""}
+	}
+	return all(cfg)
+}
+
+// InstanceResult is the result of a read command for one instance
+type InstanceResult[T any] struct {
+	Instance string
+	Value    T
+}
+
+// FanOut calls fn for every instance concurrently and returns the results
+// in the order of instances. An instance that fails is reported on stderr
+// and left out; an error is only returned if every instance failed.
+func FanOut[T any](instances []string, fn func(instance string) (T, error)) ([]InstanceResult[T], error) {
+	values := make([]T, len(instances))
+	errs := make([]error, len(instances))
+
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = fn(instance)
+		}()
+	}
+	wg.Wait()
+
+	var results []InstanceResult[T]
+	for i, instance := range instances {
+		if errs[i] != nil {
+			if len(instances) > 1 {
+				fmt.Fprintf(os.Stderr, "⚠️  %s: %v\n", instance, errs[i])
+			}
+			continue
+		}
+		results = append(results, InstanceResult[T]{Instance: instance, Value: values[i]})
+	}
+	if len(instances) == 1 && errs[0] != nil {
+		return nil, errs[0]
+	}
+	if len(results) == 0 && len(instances) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
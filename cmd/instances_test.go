@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestContext(t *testing.T) {
+	restoreConfig(t)
+	fourK := testutil.NewFakeSonarr(t)
+	config := fourK.Config()
+	if err := env.WriteConfig(fmt.Sprintf("instances:\n  4k:\n    sonarr:\n      port: %d\n      api_key: %s\n", config.Port, config.APIKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := env.MustRun(t, "context", "current")
+	testutil.AssertContains(t, out, "default")
+
+	out = env.MustRun(t, "context", "list")
+	testutil.AssertContains(t, out,
+		"*         default",
+		fmt.Sprintf("          4k        http://127.0.0.1:%d", config.Port))
+
+	out = env.MustRun(t, "context", "use", "4K")
+	testutil.AssertContains(t, out, "Switched to instance '4k'")
+	out = env.MustRun(t, "context", "current")
+	testutil.AssertContains(t, out, "4k")
+
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, string(data), "current_instance: 4k")
+
+	if _, err := env.Run(t, "context", "use", "anime"); err == nil {
+		t.Error("switching to an unknown instance succeeded")
+	}
+}
+
+func TestContextFlagOverrides(t *testing.T) {
+	restoreConfig(t)
+	if err := env.WriteConfig("instances:\n  4k:\n    sonarr:\n      port: 8990\n      api_key: 4k-key\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	out := env.MustRun(t, "context", "list", "--sonarr-url", "http://nas:9999")
+	testutil.AssertContains(t, out, "4k        http://nas:9999")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/api/httpclient"
+)
+
+// logLevel returns the log level selected by --verbose, --debug and
+// --trace-http
+func logLevel(cmd *cobra.Command) slog.Level {
+	flags := cmd.Flags()
+	if trace, _ := flags.GetBool("trace-http"); trace {
+		return httpclient.LevelTrace
+	}
+	if debug, _ := flags.GetBool("debug"); debug {
+		return slog.LevelDebug
+	}
+	if verbose, _ := flags.GetBool("verbose"); verbose {
+		return slog.LevelInfo
+	}
+	return slog.LevelWarn
+}
+
+// setupLogging sets the default logger. Logs go to stderr unless logFile is
+// set, in which case they are appended to that file.
+func setupLogging(cmd *cobra.Command, logFile string) error {
+	var w io.Writer = os.Stderr
+	if logFile != "" {
+		if rest, ok := strings.CutPrefix(logFile, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				logFile = filepath.Join(home, rest)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(logFile), 0700); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		// The file is left open for the lifetime of the process
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: logLevel(cmd),
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == httpclient.LevelTrace {
+				a.Value = slog.StringValue("TRACE")
+			}
+			return a
+		},
+	})
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
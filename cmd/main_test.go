@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// env is shared by the tests in this package; see testutil.CLIEnv
+var env *testutil.CLIEnv
+
+func TestMain(m *testing.M) {
+	var err error
+	env, err = testutil.StartCLI(RootCmd())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	env.Close()
+	os.Exit(code)
+}
+
+// restoreConfig rewrites the configuration for the fake servers when the
+// test finishes
+func restoreConfig(t *testing.T) {
+	t.Cleanup(func() {
+		if err := env.WriteConfig(""); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// outputFormat is set by --output
+var outputFormat = output.FormatTable
+
+// OutputFormat returns the format selected by --output, or JSON when the
+// deprecated --json flag is given
+func OutputFormat(command *cobra.Command) output.Format {
+	if jsonOutput, _ := command.Flags().GetBool("json"); jsonOutput {
+		return output.FormatJSON
+	}
+	return outputFormat
+}
+
+// completeOutputFormats completes the values of --output. Template
+// formats complete up to their equals sign, without a trailing space.
+func completeOutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var formats []string
+	directive := cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	for _, format := range output.Formats {
+		name := string(format)
+		if format.TakesTemplate() {
+			name += "="
+		}
+		if !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		formats = append(formats, name)
+		if !format.TakesTemplate() {
+			directive = cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return formats, directive
+}
+
+// Done reports the outcome of a command that changes something: message
+// in the text formats, or result in the others
+func Done(command *cobra.Command, result any, message string, args ...any) error {
+	format := OutputFormat(command)
+	if format.Text() {
+		fmt.Printf(message+"\n", args...)
+		return nil
+	}
+	return output.Print(format, result, nil)
+}
+
+// Progress prints what a command is doing. It goes to stderr in the
+// formats meant for scripts, leaving stdout to the result.
+func Progress(command *cobra.Command, message string, args ...any) {
+	if OutputFormat(command).Text() {
+		fmt.Printf(message+"\n", args...)
+	} else {
+		fmt.Fprintf(os.Stderr, message+"\n", args...)
+	}
+}
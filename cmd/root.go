@@ -1,24 +1,39 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
-	"strings"
-	"time"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/internal/api/sabnzbd"
 	"sonarr-sabnzbd-cli/internal/api/sonarr"
+	"sonarr-sabnzbd-cli/internal/cache"
 	"sonarr-sabnzbd-cli/internal/config"
 	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
+// SkipConfigAnnotation marks commands that run without loading the
+// configuration, such as completion and docs
+const SkipConfigAnnotation = "skip-config"
+
+// SkipValidationAnnotation marks commands that run with a configuration
+// that fails validation, such as those that inspect or repair it
+const SkipValidationAnnotation = "skip-validation"
+
 var (
-	cfg           *models.Config
-	sonarrClient  *sonarr.Client
-	sabnzbdClient *sabnzbd.Client
+	cfg *models.Config
+
+	// Clients are cached per instance
+	clientsMu      sync.Mutex
+	sonarrClients  = make(map[string]*sonarr.Client)
+	sabnzbdClients = make(map[string]*sabnzbd.Client)
 )
 
 var rootCmd = &cobra.Command{
@@ -27,35 +42,86 @@ var rootCmd = &cobra.Command{
 	Long: `A command-line interface for managing Sonarr (TV show automation)
 and Sabnzbd (binary newsreader) with unified commands and interactive features.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		var err error
-		cfg, err = config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+		if err := setupLogging(cmd, ""); err != nil {
+			return err
+		}
+
+		// --config also decides where setup saves the configuration
+		configFile, _ := cmd.Flags().GetString("config")
+		config.SetConfigFile(configFile)
+
+		if skipConfig(cmd) {
+			output.SetColors(true)
+			return nil
 		}
 
-		// Initialize API clients
-		sonarrClient = sonarr.NewClient(
-			cfg.Sonarr.Host,
-			cfg.Sonarr.Port,
-			cfg.Sonarr.APIKey,
-			cfg.Sonarr.Timeout,
-		)
-
-		sabnzbdClient = sabnzbd.NewClient(
-			cfg.Sabnzbd.Host,
-			cfg.Sabnzbd.Port,
-			cfg.Sabnzbd.APIKey,
-			cfg.Sabnzbd.Username,
-			cfg.Sabnzbd.Password,
-			cfg.Sabnzbd.Timeout,
-		)
+		if err := loadConfig(hasAnnotation(cmd, SkipValidationAnnotation)); err != nil {
+			return err
+		}
+
+		output.SetColors(cfg.UI.Colors)
+
+		if cfg.UI.LogFile != "" {
+			return setupLogging(cmd, cfg.UI.LogFile)
+		}
 
 		return nil
 	},
 }
 
+// loadConfig loads the configuration, accepting one that fails validation
+// when allowInvalid is set. Clients are created on first use so commands
+// only touch the services they need.
+func loadConfig(allowInvalid bool) error {
+	var err error
+	var invalid *config.ValidationError
+	cfg, err = config.LoadConfig()
+	if errors.As(err, &invalid) && allowInvalid {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Flags may point the clients of a previous run elsewhere
+	clientsMu.Lock()
+	clear(sonarrClients)
+	clear(sabnzbdClients)
+	clientsMu.Unlock()
+	return nil
+}
+
+// skipConfig reports whether cmd or one of its parents is annotated to run
+// without configuration
+func skipConfig(cmd *cobra.Command) bool {
+	if hasAnnotation(cmd, SkipConfigAnnotation) {
+		return true
+	}
+	return cmd.Name() == "help" || cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// hasAnnotation reports whether cmd or one of its parents has annotation
+func hasAnnotation(cmd *cobra.Command, annotation string) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[annotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute runs the root command with a context that is cancelled on
+// interrupt or termination, so in-flight API requests abort cleanly. The
+// first signal restores the default handling, so a second one ends the
+// process even while it waits on a prompt.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 // GetConfig returns the loaded configuration
@@ -63,154 +129,82 @@ func GetConfig() *models.Config {
 	return cfg
 }
 
-// GetSonarrClient returns the Sonarr API client
-func GetSonarrClient() *sonarr.Client {
-	return sonarrClient
+// GetSonarrClient returns the Sonarr API client of the current instance
+func GetSonarrClient() (*sonarr.Client, error) {
+	return GetSonarrClientFor("")
 }
 
-// GetSabnzbdClient returns the Sabnzbd API client
-func GetSabnzbdClient() *sabnzbd.Client {
-	return sabnzbdClient
+// GetSabnzbdClient returns the Sabnzbd API client of the current instance
+func GetSabnzbdClient() (*sabnzbd.Client, error) {
+	return GetSabnzbdClientFor("")
 }
 
-// RootCmd returns the root command
-// runSetupWizard runs an interactive setup wizard
-func runSetupWizard() error {
-	fmt.Println("🚀 Sonarr-Sabnzbd CLI Setup Wizard")
-	fmt.Println("===================================")
-	fmt.Println()
-
-	reader := bufio.NewReader(os.Stdin)
-
-	// Sonarr setup
-	fmt.Println("📺 Sonarr Configuration")
-	fmt.Println("----------------------")
-
-	sonarrHost := promptWithDefault(reader, "Sonarr Host", "localhost")
-	sonarrPortStr := promptWithDefault(reader, "Sonarr Port", "8989")
-	sonarrAPIKey := promptRequired(reader, "Sonarr API Key (get from Settings > General > API Key)")
-
-	sonarrPort, err := strconv.Atoi(sonarrPortStr)
-	if err != nil {
-		return fmt.Errorf("invalid port number: %w", err)
+// GetSonarrClientFor returns the Sonarr API client of the named instance,
+// or the current instance if name is empty, creating it on first use. The
+// API key is resolved from its secret source here, so commands only unlock
+// the secrets they need. The detected API version is cached on disk for
+// sonarr.version_cache_ttl.
+func GetSonarrClientFor(name string) (*sonarr.Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration not loaded")
 	}
+	name = config.InstanceName(cfg, instanceFlag(name))
 
-	// Sabnzbd setup
-	fmt.Println()
-	fmt.Println("📥 Sabnzbd Configuration")
-	fmt.Println("-----------------------")
-
-	sabnzbdHost := promptWithDefault(reader, "Sabnzbd Host", "localhost")
-	sabnzbdPortStr := promptWithDefault(reader, "Sabnzbd Port", "8080")
-	sabnzbdAPIKey := promptRequired(reader, "Sabnzbd API Key (get from Config > General > API Key)")
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := sonarrClients[name]; ok {
+		return client, nil
+	}
 
-	sabnzbdPort, err := strconv.Atoi(sabnzbdPortStr)
+	selected, err := config.SelectInstance(cfg, name)
 	if err != nil {
-		return fmt.Errorf("invalid port number: %w", err)
+		return nil, err
 	}
-
-	// Optional authentication for Sabnzbd
-	sabnzbdUsername := promptOptional(reader, "Sabnzbd Username (leave empty if no auth)")
-	sabnzbdPassword := ""
-	if sabnzbdUsername != "" {
-		sabnzbdPassword = promptOptional(reader, "Sabnzbd Password")
+	if err := config.ResolveSonarr(&selected.Sonarr, selected.Secrets); err != nil {
+		return nil, err
 	}
-
-	// Create config
-	cfg := &models.Config{
-		Sonarr: models.SonarrConfig{
-			Host:    sonarrHost,
-			Port:    sonarrPort,
-			APIKey:  sonarrAPIKey,
-			Timeout: 30 * time.Second,
-		},
-		Sabnzbd: models.SabnzbdConfig{
-			Host:     sabnzbdHost,
-			Port:     sabnzbdPort,
-			APIKey:   sabnzbdAPIKey,
-			Username: sabnzbdUsername,
-			Password: sabnzbdPassword,
-			Timeout:  30 * time.Second,
-		},
-		UI: models.UIConfig{
-			Colors:     true,
-			MaxResults: 10,
-		},
+	client, err := sonarr.NewClient(selected.Sonarr, selected.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sonarr client: %w", err)
 	}
-
-	// Test connections
-	fmt.Println()
-	fmt.Println("🔍 Testing Connections...")
-	fmt.Println("------------------------")
-
-	// Test Sonarr
-	fmt.Print("📺 Testing Sonarr connection... ")
-	sonarrClient := sonarr.NewClient(sonarrHost, sonarrPort, sonarrAPIKey, 30*time.Second)
-	if _, err := sonarrClient.GetSystemStatus(); err != nil {
-		fmt.Printf("❌ Failed: %v\n", err)
-		fmt.Println("⚠️  Configuration saved but Sonarr connection failed. Check your settings.")
-	} else {
-		fmt.Println("✅ Success!")
+	if store, err := cache.Default(); err == nil && selected.Sonarr.VersionCacheTTL > 0 {
+		client.UseVersionCache(store, selected.Sonarr.VersionCacheTTL)
 	}
+	sonarrClients[name] = client
+	return client, nil
+}
 
-	// Test Sabnzbd
-	fmt.Print("📥 Testing Sabnzbd connection... ")
-	sabnzbdClient := sabnzbd.NewClient(sabnzbdHost, sabnzbdPort, sabnzbdAPIKey, sabnzbdUsername, sabnzbdPassword, 30*time.Second)
-	if _, err := sabnzbdClient.GetVersion(); err != nil {
-		fmt.Printf("❌ Failed: %v\n", err)
-		fmt.Println("⚠️  Configuration saved but Sabnzbd connection failed. Check your settings.")
-	} else {
-		fmt.Println("✅ Success!")
+// GetSabnzbdClientFor returns the Sabnzbd API client of the named
+// instance, or the current instance if name is empty, creating it on first
+// use after resolving its API key and password
+func GetSabnzbdClientFor(name string) (*sabnzbd.Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("configuration not loaded")
 	}
+	name = config.InstanceName(cfg, instanceFlag(name))
 
-	// Save config
-	fmt.Println()
-	fmt.Print("💾 Saving configuration... ")
-	if err := config.SaveConfig(cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if client, ok := sabnzbdClients[name]; ok {
+		return client, nil
 	}
-	fmt.Println("✅ Saved!")
 
-	fmt.Println()
-	fmt.Println("🎉 Setup complete! You can now use the CLI:")
-	fmt.Println("   sonarr-sabnzbd-cli status    # Check service status")
-	fmt.Println("   sonarr search \"Breaking Bad\" --add 1    # Search and add shows")
-	fmt.Println("   sabnzbd queue               # View download queue")
-
-	return nil
-}
-
-// promptWithDefault prompts user with a default value
-func promptWithDefault(reader *bufio.Reader, prompt, defaultValue string) string {
-	fmt.Printf("%s [%s]: ", prompt, defaultValue)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return defaultValue
+	selected, err := config.SelectInstance(cfg, name)
+	if err != nil {
+		return nil, err
 	}
-	return input
-}
-
-// promptRequired prompts user for required input
-func promptRequired(reader *bufio.Reader, prompt string) string {
-	for {
-		fmt.Printf("%s: ", prompt)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		if input != "" {
-			return input
-		}
-		fmt.Println("This field is required. Please enter a value.")
+	if err := config.ResolveSabnzbd(&selected.Sabnzbd, selected.Secrets); err != nil {
+		return nil, err
 	}
+	client, err := sabnzbd.NewClient(selected.Sabnzbd, selected.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sabnzbd client: %w", err)
+	}
+	sabnzbdClients[name] = client
+	return client, nil
 }
 
-// promptOptional prompts user for optional input
-func promptOptional(reader *bufio.Reader, prompt string) string {
-	fmt.Printf("%s (optional): ", prompt)
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
-}
-
+// RootCmd returns the root command
 func RootCmd() *cobra.Command {
 	return rootCmd
 }
@@ -219,49 +213,80 @@ func RootCmd() *cobra.Command {
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check the status of Sonarr and Sabnzbd services",
-	Long:  `Check the connectivity and status of both Sonarr and Sabnzbd services.`,
-	RunE: func(command *cobra.Command, args []string) error {
-		fmt.Println("Checking service status...")
+	Long: `Check the connectivity and status of both Sonarr and Sabnzbd services.
 
-		// Check Sonarr status
-		fmt.Print("Sonarr: ")
-		sonarrStatus, err := sonarrClient.GetSystemStatus()
+Examples:
+  sonarr-sabnzbd-cli status
+  sonarr-sabnzbd-cli status -o json
+  sonarr-sabnzbd-cli status --watch 10s   # Keep checking every 10 seconds`,
+	RunE: func(command *cobra.Command, args []string) error {
+		interval, err := WatchInterval(command, args)
 		if err != nil {
-			fmt.Printf("❌ Error - %v\n", err)
-		} else {
-			fmt.Printf("✅ Connected - Version %s\n", sonarrStatus.Version)
+			return err
 		}
-
-		// Check Sabnzbd status
-		fmt.Print("Sabnzbd: ")
-		sabnzbdVersion, err := sabnzbdClient.GetVersion()
-		if err != nil {
-			fmt.Printf("❌ Error - %v\n", err)
-		} else {
-			fmt.Printf("✅ Connected - Version %s\n", sabnzbdVersion)
+		if interval == 0 {
+			return printStatus(command, os.Stdout)
 		}
-
-		return nil
+		return Watch(command, interval, func(w io.Writer) error {
+			return printStatus(command, w)
+		})
 	},
 }
 
-// setupCmd represents the setup command
-var setupCmd = &cobra.Command{
-	Use:   "setup",
-	Short: "Interactive setup wizard",
-	Long: `Run an interactive setup wizard to configure Sonarr and Sabnzbd connections.
+// printStatus checks both services and writes the result to w
+func printStatus(command *cobra.Command, w io.Writer) error {
+	ctx := command.Context()
 
-This command will guide you through:
-- Configuring your Sonarr server connection
-- Configuring your Sabnzbd server connection
-- Testing the connections
-- Saving the configuration
+	sonarrStatus := serviceStatus{Service: "Sonarr"}
+	if status, err := checkSonarr(ctx); err != nil {
+		sonarrStatus.Error = err.Error()
+	} else {
+		sonarrStatus.Connected, sonarrStatus.Version = true, status.Version
+	}
 
-Examples:
-  sonarr-sabnzbd-cli setup`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSetupWizard()
-	},
+	sabnzbdStatus := serviceStatus{Service: "Sabnzbd"}
+	if version, err := checkSabnzbd(ctx); err != nil {
+		sabnzbdStatus.Error = err.Error()
+	} else {
+		sabnzbdStatus.Connected, sabnzbdStatus.Version = true, version
+	}
+
+	statuses := []serviceStatus{sonarrStatus, sabnzbdStatus}
+	table := output.NewTable("SERVICE", "STATUS", "VERSION", "ERROR")
+	for _, status := range statuses {
+		state := output.Green("connected")
+		if !status.Connected {
+			state = output.Red("error")
+		}
+		table.Row(status.Service, state, status.Version, status.Error)
+	}
+	return output.Render(w, OutputFormat(command), statuses, table)
+}
+
+// serviceStatus is the result of checking a service
+type serviceStatus struct {
+	Service   string `json:"service"`
+	Connected bool   `json:"connected"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// checkSonarr returns the Sonarr system status
+func checkSonarr(ctx context.Context) (*models.SystemStatus, error) {
+	client, err := GetSonarrClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetSystemStatus(ctx)
+}
+
+// checkSabnzbd returns the Sabnzbd version
+func checkSabnzbd(ctx context.Context) (string, error) {
+	client, err := GetSabnzbdClient()
+	if err != nil {
+		return "", err
+	}
+	return client.GetVersion(ctx)
 }
 
 // completionCmd represents the completion command
@@ -307,6 +332,7 @@ PowerShell:
   PS> sonarr-sabnzbd-cli completion powershell > sonarr-sabnzbd-cli.ps1
   # and source this file from your PowerShell profile.
 `,
+	Annotations:           map[string]string{SkipConfigAnnotation: "true"},
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.ExactValidArgs(1),
@@ -326,7 +352,30 @@ PowerShell:
 
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log each HTTP request with its status and latency, and retries")
+	rootCmd.PersistentFlags().Bool("debug", false, "Also log request and response headers")
+	rootCmd.PersistentFlags().Bool("trace-http", false, "Also log request and response bodies")
+	rootCmd.PersistentFlags().String("instance", "", "Named instance to use instead of the current one")
+	rootCmd.PersistentFlags().String("context", "", "Alias for --instance")
+	rootCmd.PersistentFlags().String("config", "", "Configuration file to use instead of ~/.config/sonarr-sabnzbd-cli/config.yaml")
+	rootCmd.PersistentFlags().String("sonarr-url", "", "Sonarr URL, overriding the configuration")
+	rootCmd.PersistentFlags().String("sonarr-api-key", "", "Sonarr API key, overriding the configuration")
+	rootCmd.PersistentFlags().String("sabnzbd-url", "", "Sabnzbd URL, overriding the configuration")
+	rootCmd.PersistentFlags().String("sabnzbd-api-key", "", "Sabnzbd API key, overriding the configuration")
+	rootCmd.PersistentFlags().VarP(&outputFormat, "output", "o", "Output format: table, wide, json, yaml, csv, ndjson, template=TEMPLATE or jsonpath=EXPRESSION")
+	rootCmd.PersistentFlags().Bool("json", false, "Output results in JSON format")
+	rootCmd.PersistentFlags().MarkDeprecated("json", "use --output json instead")
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutputFormats)
+	config.BindFlag("sonarr.url", rootCmd.PersistentFlags().Lookup("sonarr-url"))
+	config.BindFlag(config.SonarrAPIKey, rootCmd.PersistentFlags().Lookup("sonarr-api-key"))
+	config.BindFlag("sabnzbd.url", rootCmd.PersistentFlags().Lookup("sabnzbd-url"))
+	config.BindFlag(config.SabnzbdAPIKey, rootCmd.PersistentFlags().Lookup("sabnzbd-api-key"))
+	rootCmd.SetFlagErrorFunc(wrapUsageError)
+	// Subcommands are added by the init functions of other packages, so the
+	// validators are wrapped when the first command runs, before cobra
+	// validates its arguments
+	cobra.OnInitialize(func() { wrapArgsOnce.Do(func() { wrapArgsErrors(rootCmd) }) })
 	rootCmd.AddCommand(statusCmd)
-	rootCmd.AddCommand(setupCmd)
+	AddWatchFlag(statusCmd)
 	rootCmd.AddCommand(completionCmd)
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestStatus(t *testing.T) {
+	env.Reset()
+	out := env.MustRun(t, "status")
+	testutil.AssertContains(t, out,
+		"SERVICE   STATUS      VERSION       ERROR",
+		"Sonarr    connected   3.0.10.1567",
+		"Sabnzbd   connected   4.3.2")
+}
+
+func TestStatusLogFile(t *testing.T) {
+	restoreConfig(t)
+	logFile := filepath.Join(t.TempDir(), "logs", "cli.log")
+	if err := env.WriteConfig("ui:\n  log_file: " + logFile + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	env.Reset()
+	env.MustRun(t, "status", "--verbose")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, string(data), "http request", "/api/v3/system/status")
+	if strings.Contains(string(data), testutil.SabnzbdAPIKey) {
+		t.Errorf("log contains the API key:\n%s", data)
+	}
+}
+
+func TestCompletionWithoutConfig(t *testing.T) {
+	restoreConfig(t)
+	if err := os.Remove(env.ConfigPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := env.MustRun(t, "completion", "bash")
+	testutil.AssertContains(t, out, "bash completion")
+
+	if _, err := os.Stat(env.ConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("completion created a configuration file: %v", err)
+	}
+}
+
+func TestStatusWatch(t *testing.T) {
+	env.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	out, err := env.RunContext(t, ctx, "status", "--watch", "100ms", "-o", "ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Scripts get one snapshot after another, without headers
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], `{"service":"Sonarr","connected":true`) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
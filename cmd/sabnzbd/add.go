@@ -23,26 +23,30 @@ Examples:
   sabnzbd add "https://example.com/file.nzb" --category movies`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		nzbURL := args[0]
 
 		// Add NZB to Sabnzbd
-		nzoIDs, err := cmd.GetSabnzbdClient().AddNZB(nzbURL, addCategory)
+		nzoIDs, err := client.AddNZB(ctx, nzbURL, addCategory)
 		if err != nil {
 			return fmt.Errorf("failed to add NZB: %w", err)
 		}
 
-		fmt.Printf("✅ Successfully added NZB to queue\n")
-		fmt.Printf("NZB IDs: %v\n", nzoIDs)
-
+		message := fmt.Sprintf("✅ Successfully added NZB to queue\nNZB IDs: %v", nzoIDs)
 		if addCategory != "" {
-			fmt.Printf("Category: %s\n", addCategory)
+			message += fmt.Sprintf("\nCategory: %s", addCategory)
 		}
-
-		return nil
+		return cmd.Done(command, map[string]any{"nzo_ids": nzoIDs, "cat": addCategory}, "%s", message)
 	},
 }
 
 func init() {
 	sabnzbdCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVarP(&addCategory, "category", "c", "", "Category for the download")
+	addCmd.RegisterFlagCompletionFunc("category", completeCategories)
 }
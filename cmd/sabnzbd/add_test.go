@@ -0,0 +1,26 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestAdd(t *testing.T) {
+	out := mustRun(t, "add", "https://indexer.example.com/get/1.nzb", "--category", "tv")
+	testutil.AssertContains(t, out,
+		"✅ Successfully added NZB to queue",
+		"NZB IDs: [SABnzbd_nzo_101]",
+		"Category: tv")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("mode") != "addurl" || call.Get("name") != "https://indexer.example.com/get/1.nzb" || call.Get("cat") != "tv" {
+		t.Errorf("last call = %v, want addurl with the URL and category", call)
+	}
+}
+
+func TestAddRequiresURL(t *testing.T) {
+	if _, err := run(t, "add"); err == nil {
+		t.Error("expected an error without a URL")
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // categoriesCmd represents the categories command
@@ -16,24 +17,29 @@ var categoriesCmd = &cobra.Command{
 Examples:
   sabnzbd categories`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		// Get categories from Sabnzbd
-		categories, err := cmd.GetSabnzbdClient().GetCategories()
+		categories, err := client.GetCategories(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get categories: %w", err)
 		}
 
-		if len(categories) == 0 {
+		format := cmd.OutputFormat(command)
+		if len(categories) == 0 && format.Text() {
 			fmt.Println("No categories configured.")
 			return nil
 		}
 
-		fmt.Printf("Available Categories (%d):\n\n", len(categories))
-
-		for i, category := range categories {
-			fmt.Printf("%d. %s\n", i+1, category)
+		table := output.NewTable("CATEGORY")
+		for _, category := range categories {
+			table.Row(category)
 		}
-
-		return nil
+		return output.Print(format, categories, table)
 	},
 }
 
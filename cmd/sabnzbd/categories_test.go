@@ -0,0 +1,24 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestCategories(t *testing.T) {
+	out := mustRun(t, "categories")
+	testutil.AssertContains(t, out,
+		"CATEGORY\n*\nmovies\ntv\n")
+}
+
+func TestCategoriesEmpty(t *testing.T) {
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) { s.Categories = nil })
+
+	out, err := env.Run(t, "sabnzbd", "categories")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "No categories configured.")
+}
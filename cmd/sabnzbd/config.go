@@ -0,0 +1,502 @@
+package sabnzbd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// redacted replaces secret values in exported configuration
+const redacted = "********"
+
+// secretKeys lists configuration keys whose values are never exported
+var secretKeys = map[string]bool{
+	"password":  true,
+	"api_key":   true,
+	"nzb_key":   true,
+	"email_pwd": true,
+}
+
+// namedSections lists sections made up of named items rather than plain keys
+var namedSections = map[string]bool{
+	"servers":    true,
+	"categories": true,
+	"rss":        true,
+}
+
+// exportSections lists the sections written by config export
+var exportSections = []string{"misc", "categories", "servers"}
+
+var (
+	configExportFile string
+	configApplyYes   bool
+	configApplyDry   bool
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change Sabnzbd configuration",
+	Long: `Read, change, export and apply Sabnzbd configuration through the API.
+
+Keys are given as section.key for plain sections such as misc, and as
+section.name.key for named items in the servers, categories and rss sections.
+
+Examples:
+  sabnzbd config get misc.cache_limit
+  sabnzbd config set misc.cache_limit 1G
+  sabnzbd config set categories.tv.priority 1
+  sabnzbd config export > sabnzbd.yaml
+  sabnzbd config apply sabnzbd.yaml --dry-run`,
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get [section[.key]]",
+	Short: "Show configuration values",
+	Long: `Display the whole configuration, a section or a single value.
+
+Examples:
+  sabnzbd config get                  # Show everything
+  sabnzbd config get misc             # Show the misc section
+  sabnzbd config get misc.cache_limit # Show a single value
+  sabnzbd config get misc -o json     # Show the misc section as JSON`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		section, key := "", ""
+		if len(args) == 1 {
+			section, key, _ = strings.Cut(args[0], ".")
+		}
+
+		config, err := client.GetConfig(ctx, section, "")
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		config = redactConfig(config)
+		var value any = config
+		if section != "" {
+			value = config[section]
+			if key != "" {
+				value, err = lookupConfigKey(section, value, key)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if format := cmd.OutputFormat(command); !format.Text() {
+			return output.Print(format, value, nil)
+		}
+
+		switch v := value.(type) {
+		case map[string]any, []any:
+			return yaml.NewEncoder(os.Stdout).Encode(v)
+		default:
+			fmt.Println(formatConfigValue(v))
+		}
+		return nil
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <section.key> <value>",
+	Short: "Change a configuration value",
+	Long: `Change a single configuration value.
+
+Examples:
+  sabnzbd config set misc.cache_limit 1G
+  sabnzbd config set servers.news.example.com.connections 20`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		section, rest, ok := strings.Cut(args[0], ".")
+		if !ok || rest == "" {
+			return fmt.Errorf("invalid key '%s': expected section.key", args[0])
+		}
+
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		if namedSections[section] {
+			idx := strings.LastIndex(rest, ".")
+			if idx <= 0 {
+				return fmt.Errorf("invalid key '%s': expected %s.name.key", args[0], section)
+			}
+			name, key := rest[:idx], rest[idx+1:]
+			if err := client.SetConfigItem(ctx, section, name, map[string]string{key: args[1]}); err != nil {
+				return fmt.Errorf("failed to set %s: %w", args[0], err)
+			}
+		} else {
+			if err := client.SetConfigValue(ctx, section, rest, args[1]); err != nil {
+				return fmt.Errorf("failed to set %s: %w", args[0], err)
+			}
+		}
+
+		return cmd.Done(command, map[string]any{"key": args[0], "value": args[1]},
+			"✅ Successfully set %s to %s", args[0], args[1])
+	},
+}
+
+// configExportCmd represents the config export command
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export configuration to YAML",
+	Long: `Export general settings, categories, servers and scripts to YAML.
+
+Passwords and API keys are replaced with "` + redacted + `" and are skipped when the
+file is applied again.
+
+Examples:
+  sabnzbd config export                       # Write to stdout
+  sabnzbd config export --file sabnzbd.yaml   # Write to a file`,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		config, err := client.GetConfig(ctx, "", "")
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		scripts, err := client.GetScripts(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get scripts: %w", err)
+		}
+
+		config = redactConfig(config)
+		export := make(map[string]any)
+		for _, section := range exportSections {
+			if value, ok := config[section]; ok {
+				export[section] = value
+			}
+		}
+		export["scripts"] = scripts
+
+		out := os.Stdout
+		if configExportFile != "" {
+			out, err = os.Create(configExportFile)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", configExportFile, err)
+			}
+			defer out.Close()
+		}
+
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(export); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+
+		if configExportFile != "" {
+			fmt.Printf("✅ Successfully exported configuration to %s\n", configExportFile)
+		}
+		return nil
+	},
+}
+
+// configApplyCmd represents the config apply command
+var configApplyCmd = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Apply a YAML configuration file",
+	Long: `Compare a YAML configuration file with the live server, show the
+differences and apply them.
+
+Only settings present in the file are changed; servers, categories and RSS
+feeds missing from the file are left alone. Redacted values and scripts are
+ignored.
+
+Examples:
+  sabnzbd config apply sabnzbd.yaml --dry-run   # Preview changes only
+  sabnzbd config apply sabnzbd.yaml             # Preview and confirm
+  sabnzbd config apply sabnzbd.yaml --yes       # Apply without asking`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		var desired map[string]any
+		if err := yaml.Unmarshal(data, &desired); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", args[0], err)
+		}
+
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		live, err := client.GetConfig(ctx, "", "")
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		changes := diffConfig(live, desired)
+		if len(changes) == 0 {
+			fmt.Println("✅ Configuration is up to date")
+			return nil
+		}
+
+		fmt.Printf("📝 %d change(s) to apply:\n\n", len(changes))
+		for _, change := range changes {
+			from, to := change.from, change.to
+			if secretKeys[change.key] {
+				from, to = redacted, redacted
+			}
+			fmt.Printf("  %s: %q → %q\n", change.path(), from, to)
+		}
+		fmt.Println()
+
+		if configApplyDry {
+			return nil
+		}
+
+		if !configApplyYes && !confirm("Apply these changes?") {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		if err := applyConfig(ctx, changes); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Successfully applied %d change(s)\n", len(changes))
+		return nil
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configApplyCmd)
+	configExportCmd.Flags().StringVarP(&configExportFile, "file", "f", "", "Write the export to a file instead of stdout")
+	configApplyCmd.Flags().BoolVarP(&configApplyYes, "yes", "y", false, "Apply changes without asking for confirmation")
+	configApplyCmd.Flags().BoolVar(&configApplyDry, "dry-run", false, "Only show the changes that would be made")
+}
+
+// configChange represents a single difference between a file and the server
+type configChange struct {
+	section string
+	name    string
+	key     string
+	from    string
+	to      string
+}
+
+// path returns the dotted key of the change as accepted by config set
+func (c configChange) path() string {
+	if c.name != "" {
+		return fmt.Sprintf("%s.%s.%s", c.section, c.name, c.key)
+	}
+	return fmt.Sprintf("%s.%s", c.section, c.key)
+}
+
+// diffConfig returns the changes needed to bring live in line with desired
+func diffConfig(live, desired map[string]any) []configChange {
+	var changes []configChange
+
+	for _, section := range sortedKeys(desired) {
+		if section == "scripts" {
+			continue
+		}
+
+		switch want := desired[section].(type) {
+		case map[string]any:
+			have, _ := live[section].(map[string]any)
+			for _, key := range sortedKeys(want) {
+				to := formatConfigValue(want[key])
+				from := formatConfigValue(have[key])
+				if to != redacted && to != from {
+					changes = append(changes, configChange{section: section, key: key, from: from, to: to})
+				}
+			}
+		case []any:
+			have := namedItems(live[section])
+			for _, item := range want {
+				fields, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				name := formatConfigValue(fields["name"])
+				if name == "" {
+					continue
+				}
+				current := have[name]
+				for _, key := range sortedKeys(fields) {
+					if key == "name" {
+						continue
+					}
+					to := formatConfigValue(fields[key])
+					from := formatConfigValue(current[key])
+					if to != redacted && to != from {
+						changes = append(changes, configChange{section: section, name: name, key: key, from: from, to: to})
+					}
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+// applyConfig sends the changes to the server, grouping fields of named items
+func applyConfig(ctx context.Context, changes []configChange) error {
+	client, err := cmd.GetSabnzbdClient()
+	if err != nil {
+		return err
+	}
+
+	items := make(map[[2]string]map[string]string)
+	var order [][2]string
+
+	for _, change := range changes {
+		if change.name == "" {
+			if err := client.SetConfigValue(ctx, change.section, change.key, change.to); err != nil {
+				return fmt.Errorf("failed to set %s: %w", change.path(), err)
+			}
+			continue
+		}
+		id := [2]string{change.section, change.name}
+		if _, ok := items[id]; !ok {
+			items[id] = make(map[string]string)
+			order = append(order, id)
+		}
+		items[id][change.key] = change.to
+	}
+
+	for _, id := range order {
+		if err := client.SetConfigItem(ctx, id[0], id[1], items[id]); err != nil {
+			return fmt.Errorf("failed to update %s.%s: %w", id[0], id[1], err)
+		}
+	}
+	return nil
+}
+
+// namedItems indexes a list of named configuration items by name
+func namedItems(value any) map[string]map[string]any {
+	result := make(map[string]map[string]any)
+	list, _ := value.([]any)
+	for _, item := range list {
+		if fields, ok := item.(map[string]any); ok {
+			result[formatConfigValue(fields["name"])] = fields
+		}
+	}
+	return result
+}
+
+// lookupConfigKey returns a single value from a section
+func lookupConfigKey(section string, value any, key string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if result, ok := v[key]; ok {
+			return result, nil
+		}
+	case []any:
+		name, field, hasField := key, "", false
+		if idx := strings.LastIndex(key, "."); idx > 0 {
+			name, field, hasField = key[:idx], key[idx+1:], true
+		}
+		items := namedItems(v)
+		if item, ok := items[key]; ok {
+			return item, nil
+		}
+		if item, ok := items[name]; ok && hasField {
+			if result, ok := item[field]; ok {
+				return result, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("key '%s' not found in section '%s'", key, section)
+}
+
+// redactConfig replaces secret values throughout a configuration tree
+func redactConfig(config map[string]any) map[string]any {
+	return redactValue(config).(map[string]any)
+}
+
+// redactValue recursively copies a value, replacing secrets
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			if secretKeys[key] && formatConfigValue(item) != "" {
+				result[key] = redacted
+			} else {
+				result[key] = redactValue(item)
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// formatConfigValue formats a configuration value the way set_config expects
+func formatConfigValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatConfigValue(item)
+		}
+		return strings.Join(parts, ",")
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// confirm asks the user a yes/no question on stdin
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
@@ -0,0 +1,143 @@
+package sabnzbd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestConfigGet(t *testing.T) {
+	out := mustRun(t, "config", "get", "misc.cache_limit")
+	if strings.TrimSpace(out) != "1G" {
+		t.Errorf("misc.cache_limit = %q, want 1G", out)
+	}
+
+	out = mustRun(t, "config", "get", "servers.news.example.com.connections")
+	if strings.TrimSpace(out) != "20" {
+		t.Errorf("connections = %q, want 20", out)
+	}
+
+	if _, err := run(t, "config", "get", "misc.missing"); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+func TestConfigGetRedactsSecrets(t *testing.T) {
+	out := mustRun(t, "config", "get", "misc")
+	testutil.AssertContains(t, out, "cache_limit: 1G", "api_key: '"+redacted+"'")
+	if strings.Contains(out, testutil.SabnzbdAPIKey) {
+		t.Errorf("output contains the API key:\n%s", out)
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	out := mustRun(t, "config", "set", "misc.cache_limit", "2G")
+	testutil.AssertContains(t, out, "✅ Successfully set misc.cache_limit to 2G")
+
+	misc := env.Sabnzbd.State().Config["misc"].(map[string]any)
+	if misc["cache_limit"] != "2G" {
+		t.Errorf("cache_limit = %v, want 2G", misc["cache_limit"])
+	}
+
+	out = mustRun(t, "config", "set", "categories.tv.priority", "1")
+	testutil.AssertContains(t, out, "✅ Successfully set categories.tv.priority to 1")
+
+	if _, err := run(t, "config", "set", "cache_limit", "2G"); err == nil {
+		t.Error("expected an error for a key without a section")
+	}
+}
+
+func TestConfigExport(t *testing.T) {
+	out := mustRun(t, "config", "export")
+
+	var export map[string]any
+	if err := yaml.Unmarshal([]byte(out), &export); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	for _, section := range []string{"misc", "servers", "categories", "scripts"} {
+		if _, ok := export[section]; !ok {
+			t.Errorf("export is missing %s", section)
+		}
+	}
+	if strings.Contains(out, "secret") || strings.Contains(out, testutil.SabnzbdAPIKey) {
+		t.Errorf("export contains secrets:\n%s", out)
+	}
+}
+
+func TestConfigExportFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sabnzbd.yaml")
+	out := mustRun(t, "config", "export", "--file", file)
+	testutil.AssertContains(t, out, "✅ Successfully exported configuration to "+file)
+
+	if _, err := os.Stat(file); err != nil {
+		t.Error(err)
+	}
+}
+
+// writeApplyFile writes a configuration file changing the cache limit and
+// returns its path
+func writeApplyFile(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "sabnzbd.yaml")
+	data := "misc:\n  cache_limit: 2G\n  api_key: '" + redacted + "'\n"
+	if err := os.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// cacheLimit returns the cache limit stored by the fake server
+func cacheLimit() any {
+	return env.Sabnzbd.State().Config["misc"].(map[string]any)["cache_limit"]
+}
+
+func TestConfigApplyDryRun(t *testing.T) {
+	out := mustRun(t, "config", "apply", writeApplyFile(t), "--dry-run")
+	testutil.AssertContains(t, out, "1 change(s) to apply", `misc.cache_limit: "1G" → "2G"`)
+
+	if cacheLimit() != "1G" {
+		t.Errorf("cache_limit = %v, want 1G after a dry run", cacheLimit())
+	}
+}
+
+func TestConfigApplyConfirm(t *testing.T) {
+	file := writeApplyFile(t)
+
+	env.Reset()
+	out, err := env.RunWithInput(t, "n\n", "sabnzbd", "config", "apply", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "Apply these changes? [y/N]", "Aborted.")
+	if cacheLimit() != "1G" {
+		t.Errorf("cache_limit = %v, want 1G after aborting", cacheLimit())
+	}
+
+	out, err = env.RunWithInput(t, "y\n", "sabnzbd", "config", "apply", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "✅ Successfully applied 1 change(s)")
+	if cacheLimit() != "2G" {
+		t.Errorf("cache_limit = %v, want 2G", cacheLimit())
+	}
+}
+
+func TestConfigApplyUpToDate(t *testing.T) {
+	file := writeApplyFile(t)
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) {
+		s.Config["misc"].(map[string]any)["cache_limit"] = "2G"
+	})
+
+	out, err := env.Run(t, "sabnzbd", "config", "apply", file, "--yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "✅ Configuration is up to date")
+}
@@ -20,19 +20,26 @@ Examples:
   sabnzbd delete SABnzbd_nzo_67890`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		nzoID := args[0]
 
 		// Delete from queue
-		err := cmd.GetSabnzbdClient().DeleteFromQueue(nzoID)
+		err = client.DeleteFromQueue(ctx, nzoID)
 		if err != nil {
 			return fmt.Errorf("failed to delete job %s: %w", nzoID, err)
 		}
 
-		fmt.Printf("✅ Successfully deleted job %s from queue\n", nzoID)
-		return nil
+		return cmd.Done(command, map[string]any{"nzo_id": nzoID, "deleted": true},
+			"✅ Successfully deleted job %s from queue", nzoID)
 	},
 }
 
 func init() {
 	sabnzbdCmd.AddCommand(deleteCmd)
+	deleteCmd.ValidArgsFunction = cmd.FirstArg(completeJobs)
 }
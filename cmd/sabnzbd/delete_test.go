@@ -0,0 +1,17 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestDelete(t *testing.T) {
+	out := mustRun(t, "delete", "SABnzbd_nzo_2")
+	testutil.AssertContains(t, out, "✅ Successfully deleted job SABnzbd_nzo_2 from queue")
+
+	slots := env.Sabnzbd.State().Queue.Slots
+	if len(slots) != 1 || slots[0].ID != "SABnzbd_nzo_1" {
+		t.Errorf("queue = %+v, want only SABnzbd_nzo_1", slots)
+	}
+}
@@ -0,0 +1,135 @@
+package sabnzbd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+var (
+	filesDelete    []string
+	filesDeleteSet string
+	filesUp        []string
+	filesDown      []string
+	filesPositions int
+)
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files <nzo-id>",
+	Short: "List and manage files within a job",
+	Long: `Display the individual files of a queued job, or delete and reorder them.
+
+This is useful when a single RAR set in a large job is broken: the set can
+be dropped without deleting the whole job. File IDs (nzf_id) are shown in
+the listing.
+
+Examples:
+  sabnzbd files SABnzbd_nzo_12345                          # List files in a job
+  sabnzbd files SABnzbd_nzo_12345 -o json                  # Output in JSON format
+  sabnzbd files SABnzbd_nzo_12345 --delete SABnzbd_nzf_1   # Delete a single file
+  sabnzbd files SABnzbd_nzo_12345 --delete-set "show.s01"  # Delete every file in a set
+  sabnzbd files SABnzbd_nzo_12345 --up SABnzbd_nzf_1       # Move a file up one place
+  sabnzbd files SABnzbd_nzo_12345 --down SABnzbd_nzf_1 --positions 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		nzoID := args[0]
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		// Delete an entire set by resolving its file IDs first
+		toDelete := filesDelete
+		if filesDeleteSet != "" {
+			files, err := client.GetFiles(ctx, nzoID)
+			if err != nil {
+				return fmt.Errorf("failed to get files: %w", err)
+			}
+			for _, file := range files {
+				if file.Set == filesDeleteSet && file.Status != "finished" {
+					toDelete = append(toDelete, file.ID)
+				}
+			}
+			if len(toDelete) == 0 {
+				return fmt.Errorf("no pending files found in set '%s'", filesDeleteSet)
+			}
+		}
+
+		if len(toDelete) > 0 {
+			if err := client.DeleteFiles(ctx, nzoID, toDelete); err != nil {
+				return fmt.Errorf("failed to delete files: %w", err)
+			}
+			return cmd.Done(command, map[string]any{"nzo_id": nzoID, "deleted": toDelete},
+				"✅ Successfully deleted %d file(s) from job %s", len(toDelete), nzoID)
+		}
+
+		if len(filesUp) > 0 {
+			if err := client.MoveFiles(ctx, nzoID, filesUp, "up", filesPositions); err != nil {
+				return fmt.Errorf("failed to move files: %w", err)
+			}
+			return cmd.Done(command, map[string]any{"nzo_id": nzoID, "moved_up": filesUp},
+				"✅ Successfully moved %d file(s) up", len(filesUp))
+		}
+
+		if len(filesDown) > 0 {
+			if err := client.MoveFiles(ctx, nzoID, filesDown, "down", filesPositions); err != nil {
+				return fmt.Errorf("failed to move files: %w", err)
+			}
+			return cmd.Done(command, map[string]any{"nzo_id": nzoID, "moved_down": filesDown},
+				"✅ Successfully moved %d file(s) down", len(filesDown))
+		}
+
+		files, err := client.GetFiles(ctx, nzoID)
+		if err != nil {
+			return fmt.Errorf("failed to get files: %w", err)
+		}
+
+		format := cmd.OutputFormat(command)
+		if len(files) == 0 && format.Text() {
+			fmt.Printf("No files found for job %s.\n", nzoID)
+			return nil
+		}
+
+		table := output.NewTable("ID", "FILENAME", "STATUS", "SIZE", "LEFT").Wide("SET", "AGE")
+		for _, file := range files {
+			table.Row(file.ID, file.Filename, colorStatus(file.Status),
+				output.FormatBytes(parseFileBytes(file)), formatMB(file.MBLeft), file.Set, file.Age)
+		}
+		return output.Print(format, files, table)
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(filesCmd)
+	filesCmd.ValidArgsFunction = cmd.FirstArg(completeJobs)
+	filesCmd.Flags().StringSliceVar(&filesDelete, "delete", nil, "Delete the files with the given nzf_ids")
+	filesCmd.Flags().StringVar(&filesDeleteSet, "delete-set", "", "Delete all pending files belonging to a set")
+	filesCmd.Flags().StringSliceVar(&filesUp, "up", nil, "Move the files with the given nzf_ids up")
+	filesCmd.Flags().StringSliceVar(&filesDown, "down", nil, "Move the files with the given nzf_ids down")
+	filesCmd.Flags().IntVar(&filesPositions, "positions", 1, "Number of positions to move files by")
+	filesCmd.MarkFlagsMutuallyExclusive("delete", "delete-set", "up", "down")
+}
+
+// parseFileBytes returns the size of a file in bytes
+func parseFileBytes(file models.JobFile) int64 {
+	bytes, err := strconv.ParseFloat(file.Bytes, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(bytes)
+}
+
+// formatMB formats a megabyte string returned by Sabnzbd
+func formatMB(mb string) string {
+	value, err := strconv.ParseFloat(mb, 64)
+	if err != nil {
+		return mb
+	}
+	return output.FormatBytes(int64(value * 1024 * 1024))
+}
@@ -0,0 +1,72 @@
+package sabnzbd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestFiles(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_1")
+	testutil.AssertContains(t, out,
+		"ID              FILENAME                 STATUS     SIZE       LEFT",
+		"SABnzbd_nzf_1   show.s01e01.part01.rar   finished   100.0 MB   0 B",
+		"SABnzbd_nzf_2   show.s01e01.part02.rar   active     100.0 MB   50.0 MB",
+		"SABnzbd_nzf_3   show.s01e01.par2         queued")
+}
+
+func TestFilesJSON(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_1", "-o", "json")
+
+	var files []models.JobFile
+	if err := json.Unmarshal([]byte(out), &files); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(files) != 3 {
+		t.Errorf("got %d files, want 3", len(files))
+	}
+}
+
+func TestFilesEmpty(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_2")
+	testutil.AssertContains(t, out, "No files found for job SABnzbd_nzo_2.")
+}
+
+func TestFilesDelete(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_1", "--delete", "SABnzbd_nzf_3")
+	testutil.AssertContains(t, out, "✅ Successfully deleted 1 file(s) from job SABnzbd_nzo_1")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("name") != "delete_nzf" || call.Get("value2") != "SABnzbd_nzf_3" {
+		t.Errorf("last call = %v, want delete_nzf of SABnzbd_nzf_3", call)
+	}
+}
+
+func TestFilesDeleteSet(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_1", "--delete-set", "show.s01e01")
+	testutil.AssertContains(t, out, "✅ Successfully deleted 1 file(s) from job SABnzbd_nzo_1")
+
+	// Finished files are left alone
+	if value := env.Sabnzbd.LastCall().Get("value2"); value != "SABnzbd_nzf_2" {
+		t.Errorf("deleted %q, want SABnzbd_nzf_2", value)
+	}
+
+	if _, err := run(t, "files", "SABnzbd_nzo_1", "--delete-set", "missing"); err == nil {
+		t.Error("expected an error for a set without pending files")
+	}
+}
+
+func TestFilesMove(t *testing.T) {
+	out := mustRun(t, "files", "SABnzbd_nzo_1", "--up", "SABnzbd_nzf_3", "--positions", "2")
+	testutil.AssertContains(t, out, "✅ Successfully moved 1 file(s) up")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("mode") != "move_nzf_bulk" || call.Get("nzf_ids") != "SABnzbd_nzf_3" || call.Get("size") != "2" {
+		t.Errorf("last call = %v, want move_nzf_bulk of SABnzbd_nzf_3 by 2", call)
+	}
+
+	out = mustRun(t, "files", "SABnzbd_nzo_1", "--down", "SABnzbd_nzf_1")
+	testutil.AssertContains(t, out, "✅ Successfully moved 1 file(s) down")
+}
@@ -2,11 +2,11 @@ package sabnzbd
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // historyCmd represents the history command
@@ -17,77 +17,46 @@ var historyCmd = &cobra.Command{
 
 Examples:
   sabnzbd history                    # View recent download history
+  sabnzbd history -o wide            # Add category, path and failures
+  sabnzbd history -o csv             # Output in CSV format
   sabnzbd history | head -20         # View last 20 downloads`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		// Get history from Sabnzbd
-		history, err := cmd.GetSabnzbdClient().GetHistory()
+		history, err := client.GetHistory(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get history: %w", err)
 		}
 
-		if len(history.Slots) == 0 {
+		format := cmd.OutputFormat(command)
+		if len(history.Slots) == 0 && format.Text() {
 			fmt.Println("Download history is empty.")
 			return nil
 		}
 
-		fmt.Printf("📚 Download History (%d completed)\n", len(history.Slots))
-		fmt.Println(strings.Repeat("─", 80))
-
-		for i, slot := range history.Slots {
-			// Format completion time
-			completionTime := "Unknown"
+		table := output.NewTable("ID", "NAME", "STATUS", "SIZE", "COMPLETED").
+			Wide("CATEGORY", "PATH", "FAIL MESSAGE")
+		for _, slot := range history.Slots {
+			completed := "Unknown"
 			if slot.Completed > 0 {
-				t := time.Unix(slot.Completed, 0)
-				completionTime = t.Format("2006-01-02 15:04:05")
+				completed = time.Unix(slot.Completed, 0).Format("2006-01-02 15:04:05")
 			}
-
-			status := getHistoryStatusIcon(slot.Status)
-
-			fmt.Printf("%d. %s %s\n", i+1, status, slot.Name)
-			fmt.Printf("   📏 Size: %s | ⏰ Completed: %s\n",
-				formatBytes(slot.Bytes), completionTime)
-			if slot.Category != "" && slot.Category != "*" {
-				fmt.Printf("   📂 Category: %s\n", slot.Category)
+			category := slot.Category
+			if category == "*" {
+				category = ""
 			}
-			fmt.Println()
+			table.Row(slot.ID, slot.Name, colorStatus(slot.Status), output.FormatBytes(slot.Bytes), completed,
+				category, slot.Storage, slot.FailMessage)
 		}
-
-		return nil
+		return output.Print(format, history.Slots, table)
 	},
 }
 
 func init() {
 	sabnzbdCmd.AddCommand(historyCmd)
 }
-
-// getHistoryStatusIcon returns an appropriate icon for history status
-func getHistoryStatusIcon(status string) string {
-	switch strings.ToLower(status) {
-	case "completed":
-		return "✅"
-	case "failed":
-		return "❌"
-	case "repairing":
-		return "🔧"
-	case "extracting":
-		return "📦"
-	case "verifying":
-		return "🔍"
-	default:
-		return "📄"
-	}
-}
-
-// formatBytes formats bytes into human readable format
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
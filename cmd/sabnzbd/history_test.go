@@ -0,0 +1,41 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestHistory(t *testing.T) {
+	out := mustRun(t, "history")
+	testutil.AssertContains(t, out,
+		"ID              NAME               STATUS      SIZE     COMPLETED",
+		"SABnzbd_nzo_3   Movie.2023.1080p   Completed   8.0 GB",
+		"SABnzbd_nzo_4   Broken.Release     Failed      0 B      Unknown")
+}
+
+func TestHistoryEmpty(t *testing.T) {
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) { s.History.Slots = nil })
+
+	out, err := env.Run(t, "sabnzbd", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "Download history is empty.")
+}
+
+func TestHistoryFormats(t *testing.T) {
+	out := mustRun(t, "history", "-o", "wide")
+	testutil.AssertContains(t, out,
+		"CATEGORY   PATH   FAIL MESSAGE",
+		"tv                Repair failed, not enough repair blocks")
+
+	out = mustRun(t, "history", "-o", "csv")
+	testutil.AssertContains(t, out,
+		"id,name,status,size,completed,category,path,fail_message",
+		"SABnzbd_nzo_4,Broken.Release,Failed,0 B,Unknown,tv,,\"Repair failed, not enough repair blocks\"")
+
+	out = mustRun(t, "history", "-o", "yaml")
+	testutil.AssertContains(t, out, "- nzo_id: SABnzbd_nzo_3", "  cat: movies")
+}
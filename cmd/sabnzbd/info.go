@@ -1,73 +1,474 @@
 package sabnzbd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/api/sabnzbd"
+	"sonarr-sabnzbd-cli/internal/cache"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+var (
+	infoCheck          bool
+	infoMinFree        float64
+	infoClearWarnings  bool
+	infoDismissWarning int
 )
 
 // infoCmd represents the info command
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show Sabnzbd system info",
-	Long: `Display system information and status for Sabnzbd.
+	Long: `Display system information and status for Sabnzbd, including news
+server connections and article errors, disk space, system load, loaded
+modules and active warnings.
+
+Use --check in monitoring scripts: it exits with a non-zero status when free
+disk space is below --min-free or when Sabnzbd reports warnings.
+
+Sabnzbd can only clear all of its warnings at once. --dismiss-warning hides a
+single warning, by its number in the list, from info and --check instead,
+until Sabnzbd no longer reports it.
 
 Examples:
-  sabnzbd info`,
+  sabnzbd info
+  sabnzbd info -o yaml                # Output in YAML format
+  sabnzbd info --watch                # Refresh every 2 seconds
+  sabnzbd info --clear-warnings       # Dismiss all warnings
+  sabnzbd info --dismiss-warning 2    # Hide the second warning
+  sabnzbd info --check                # Exit non-zero on problems
+  sabnzbd info --check --min-free 50  # Require 50 GB free`,
 	RunE: func(command *cobra.Command, args []string) error {
-		client := cmd.GetSabnzbdClient()
+		interval, err := cmd.WatchInterval(command, args)
+		if err != nil {
+			return err
+		}
 
-		// Get version
-		version, err := client.GetVersion()
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
 		if err != nil {
-			return fmt.Errorf("failed to get version: %w", err)
+			return err
+		}
+
+		if infoClearWarnings {
+			if err := client.ClearWarnings(ctx); err != nil {
+				return fmt.Errorf("failed to clear warnings: %w", err)
+			}
+			if key, err := dismissedKey(); err == nil {
+				if store, err := cache.Default(); err == nil {
+					store.Delete(key)
+				}
+			}
+			return cmd.Done(command, map[string]any{"cleared": true}, "✅ Successfully cleared all warnings")
+		}
+		if infoDismissWarning != 0 {
+			warning, err := dismissWarning(ctx, client, infoDismissWarning)
+			if err != nil {
+				return err
+			}
+			return cmd.Done(command, map[string]any{"dismissed": warning},
+				"✅ Dismissed warning %d: %s", infoDismissWarning, warning.Text)
 		}
 
-		// Get queue status
-		queue, err := client.GetQueue()
+		format := cmd.OutputFormat(command)
+		if interval > 0 {
+			speeds := &speedHistory{}
+			return cmd.Watch(command, interval, func(w io.Writer) error {
+				info, err := getInfo(ctx, client)
+				if err != nil {
+					return err
+				}
+				speeds.add(info.Queue.KBPerSec)
+				return printInfo(w, format, info, speeds)
+			})
+		}
+
+		info, err := getInfo(ctx, client)
 		if err != nil {
-			return fmt.Errorf("failed to get queue: %w", err)
+			return err
+		}
+		if err := printInfo(os.Stdout, format, info, nil); err != nil {
+			return err
+		}
+
+		if infoCheck {
+			problems := checkHealth(info.Status, info.Warnings, infoMinFree)
+			if len(problems) > 0 {
+				command.SilenceUsage = true
+				return fmt.Errorf("health check failed: %s", strings.Join(problems, "; "))
+			}
+			if format.Text() {
+				fmt.Println()
+				fmt.Println("✅ Health check passed")
+			}
 		}
 
-		// Cool header
-		fmt.Println("🚀 Sabnzbd System Information")
-		fmt.Println(strings.Repeat("═", 50))
+		return nil
+	},
+}
 
-		// Status with icon
-		statusIcon := "🟢"
-		if queue.Paused {
-			statusIcon = "⏸️"
+func init() {
+	sabnzbdCmd.AddCommand(infoCmd)
+	infoCmd.Flags().BoolVar(&infoCheck, "check", false, "Exit non-zero on low disk space or active warnings")
+	infoCmd.Flags().Float64Var(&infoMinFree, "min-free", 10, "Minimum free disk space in GB for --check")
+	infoCmd.Flags().BoolVar(&infoClearWarnings, "clear-warnings", false, "Dismiss all active warnings")
+	infoCmd.Flags().IntVar(&infoDismissWarning, "dismiss-warning", 0, "Hide the warning with this number from info and --check")
+	cmd.AddWatchFlag(infoCmd)
+	infoCmd.MarkFlagsMutuallyExclusive("watch", "check")
+	infoCmd.MarkFlagsMutuallyExclusive("watch", "clear-warnings")
+	infoCmd.MarkFlagsMutuallyExclusive("watch", "dismiss-warning")
+	infoCmd.MarkFlagsMutuallyExclusive("clear-warnings", "dismiss-warning")
+}
+
+// getInfo gets everything info shows
+func getInfo(ctx context.Context, client *sabnzbd.Client) (sabnzbdInfo, error) {
+	// Get version
+	version, err := client.GetVersion(ctx)
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	// Get queue status
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	// Get full status
+	status, err := client.GetFullStatus(ctx)
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get full status: %w", err)
+	}
+
+	// Get article errors, which fullstatus does not report. Statistics are
+	// kept per server section, while fullstatus names servers by their
+	// display name.
+	stats, err := client.GetServerStats(ctx)
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get server stats: %w", err)
+	}
+	servers, err := client.GetConfig(ctx, "servers", "")
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get servers: %w", err)
+	}
+	names := displayNames(servers["servers"])
+	articleErrors := make(map[string]int64, len(stats.Servers))
+	for name, server := range stats.Servers {
+		if display, ok := names[name]; ok {
+			name = display
 		}
+		articleErrors[name] = server.ArticleErrors()
+	}
+
+	// Get warnings, leaving out those dismissed
+	warnings, err := client.GetWarnings(ctx)
+	if err != nil {
+		return sabnzbdInfo{}, fmt.Errorf("failed to get warnings: %w", err)
+	}
+	active := activeWarnings(warnings, loadDismissed())
 
-		fmt.Printf("📦 Version: %s\n", version)
-		fmt.Printf("📊 Status: %s %s\n", statusIcon, queue.Status)
-		fmt.Printf("⚡ Speed: %s\n", queue.Speed)
+	return sabnzbdInfo{Version: version, Queue: queueSummary(queue), Status: status,
+		ArticleErrors: articleErrors, Warnings: active, DismissedWarnings: len(warnings) - len(active)}, nil
+}
 
-		if queue.SpeedLimit != "" && queue.SpeedLimit != "100" {
-			fmt.Printf("🎛️  Speed Limit: %s%%\n", queue.SpeedLimit)
+// displayNames maps the name of each server section to the name fullstatus
+// shows, which is its display name when it has one
+func displayNames(servers any) map[string]string {
+	names := make(map[string]string)
+	items, _ := servers.([]any)
+	for _, item := range items {
+		server, _ := item.(map[string]any)
+		name, _ := server["name"].(string)
+		if display, _ := server["displayname"].(string); name != "" && display != "" {
+			names[name] = display
 		}
+	}
+	return names
+}
+
+// dismissedKey returns the cache key of the warnings dismissed on the
+// Sabnzbd server of the current instance
+func dismissedKey() (string, error) {
+	scope, err := cmd.ServerScope("sabnzbd")
+	if err != nil {
+		return "", err
+	}
+	return "sabnzbd/dismissed-warnings/" + scope, nil
+}
 
-		fmt.Printf("📥 Active Downloads: %d\n", len(queue.Slots))
+// warningID identifies a warning among those Sabnzbd reports
+func warningID(warning models.Warning) string {
+	return fmt.Sprintf("%d %s %s", warning.Time, warning.Type, warning.Text)
+}
+
+// loadDismissed returns the IDs of the dismissed warnings
+func loadDismissed() map[string]bool {
+	dismissed := make(map[string]bool)
+	key, err := dismissedKey()
+	if err != nil {
+		return dismissed
+	}
+	store, err := cache.Default()
+	if err != nil {
+		return dismissed
+	}
+	var ids []string
+	store.Get(key, 0, &ids)
+	for _, id := range ids {
+		dismissed[id] = true
+	}
+	return dismissed
+}
 
-		if queue.TimeLeft != "" && queue.TimeLeft != "0:00:00" {
-			fmt.Printf("⏰ Time Left: %s\n", queue.TimeLeft)
+// activeWarnings returns the warnings that have not been dismissed
+func activeWarnings(warnings []models.Warning, dismissed map[string]bool) []models.Warning {
+	active := make([]models.Warning, 0, len(warnings))
+	for _, warning := range warnings {
+		if !dismissed[warningID(warning)] {
+			active = append(active, warning)
 		}
+	}
+	return active
+}
 
-		if queue.SizeLeft != "" && queue.SizeLeft != "0 B" {
-			fmt.Printf("💾 Size Left: %s\n", queue.SizeLeft)
+// dismissWarning hides the active warning numbered n, counting from 1 as
+// info lists them. Warnings Sabnzbd no longer reports are forgotten.
+func dismissWarning(ctx context.Context, client *sabnzbd.Client, n int) (models.Warning, error) {
+	warnings, err := client.GetWarnings(ctx)
+	if err != nil {
+		return models.Warning{}, fmt.Errorf("failed to get warnings: %w", err)
+	}
+	dismissed := loadDismissed()
+	active := activeWarnings(warnings, dismissed)
+	if n < 1 || n > len(active) {
+		return models.Warning{}, fmt.Errorf("no warning %d: there are %d active warning(s)", n, len(active))
+	}
+
+	warning := active[n-1]
+	dismissed[warningID(warning)] = true
+	var ids []string
+	for _, w := range warnings {
+		if id := warningID(w); dismissed[id] {
+			ids = append(ids, id)
 		}
+	}
+
+	key, err := dismissedKey()
+	if err != nil {
+		return models.Warning{}, err
+	}
+	store, err := cache.Default()
+	if err != nil {
+		return models.Warning{}, fmt.Errorf("failed to dismiss warning: %w", err)
+	}
+	if err := store.Set(key, ids); err != nil {
+		return models.Warning{}, fmt.Errorf("failed to dismiss warning: %w", err)
+	}
+	return warning, nil
+}
+
+// printInfo writes info to w, with the news servers and warnings as
+// tables of their own in the text formats. When watching, speeds is drawn
+// as a sparkline next to the speed.
+func printInfo(w io.Writer, format output.Format, info sabnzbdInfo, speeds *speedHistory) error {
+	if err := output.Render(w, format, info, infoTable(info, speeds)); err != nil {
+		return err
+	}
+	if format.Text() {
+		printServers(w, info.Status.Servers, info.ArticleErrors)
+		printWarnings(w, info.Warnings, info.DismissedWarnings)
+	}
+	return nil
+}
+
+// sabnzbdInfo is everything info shows, for the structured formats
+type sabnzbdInfo struct {
+	Version string             `json:"version"`
+	Queue   queueInfo          `json:"queue"`
+	Status  *models.FullStatus `json:"status"`
+	// ArticleErrors counts the articles each news server failed to deliver
+	ArticleErrors map[string]int64 `json:"article_errors"`
+	Warnings      []models.Warning `json:"warnings"`
+	// DismissedWarnings counts the warnings hidden by --dismiss-warning
+	DismissedWarnings int `json:"dismissed_warnings"`
+}
+
+// queueInfo is the state of the queue without its slots
+type queueInfo struct {
+	Status     string `json:"status"`
+	Paused     bool   `json:"paused"`
+	Speed      string `json:"speed"`
+	KBPerSec   string `json:"kbpersec"`
+	SpeedLimit string `json:"speedlimit"`
+	Slots      int    `json:"slots"`
+	TimeLeft   string `json:"timeleft"`
+	SizeLeft   string `json:"sizeleft"`
+	Size       string `json:"size"`
+}
+
+// queueSummary returns the state of queue
+func queueSummary(queue *models.Queue) queueInfo {
+	return queueInfo{
+		Status:     queue.Status,
+		Paused:     queue.Paused,
+		Speed:      queue.Speed,
+		KBPerSec:   queue.KBPerSec,
+		SpeedLimit: queue.SpeedLimit,
+		Slots:      len(queue.Slots),
+		TimeLeft:   queue.TimeLeft,
+		SizeLeft:   queue.SizeLeft,
+		Size:       queue.Size,
+	}
+}
+
+// infoTable lists the version, queue state, disk space and system load
+func infoTable(info sabnzbdInfo, speeds *speedHistory) *output.Table {
+	queue, status := info.Queue, info.Status
+	table := output.NewTable("FIELD", "VALUE")
 
-		// Queue size info
-		if queue.Size != "" {
-			fmt.Printf("📊 Total Queue Size: %s\n", queue.Size)
+	state := output.Green(queue.Status)
+	if queue.Paused {
+		state = output.Yellow(queue.Status)
+	}
+	table.Row("Version", info.Version)
+	table.Row("Status", state)
+	if speeds != nil {
+		table.Row("Speed", queue.Speed+"   "+speeds.sparkline())
+	} else {
+		table.Row("Speed", queue.Speed)
+	}
+	if queue.SpeedLimit != "" && queue.SpeedLimit != "100" {
+		table.Row("Speed Limit", queue.SpeedLimit+"%")
+	}
+	table.Row("Active Downloads", queue.Slots)
+	if queue.TimeLeft != "" && queue.TimeLeft != "0:00:00" {
+		table.Row("Time Left", queue.TimeLeft)
+	}
+	if queue.SizeLeft != "" && queue.SizeLeft != "0 B" {
+		table.Row("Size Left", queue.SizeLeft)
+	}
+	if queue.Size != "" {
+		table.Row("Total Queue Size", queue.Size)
+	}
+
+	if status.CPUModel != "" {
+		table.Row("CPU", status.CPUModel)
+	}
+	if status.Pystone > 0 {
+		table.Row("Pystone", status.Pystone)
+	}
+	if status.LoadAvg != "" {
+		table.Row("Load", status.LoadAvg)
+	}
+	if status.Uptime != "" {
+		table.Row("Uptime", status.Uptime)
+	}
+	if len(status.Modules) > 0 {
+		modules := make([]string, 0, len(status.Modules))
+		for name, version := range status.Modules {
+			modules = append(modules, name+" "+version)
 		}
+		sort.Strings(modules)
+		table.Row("Modules", strings.Join(modules, ", "))
+	}
+	table.Row("Download Dir", fmt.Sprintf("%s (%s GB free of %s GB)",
+		status.DownloadDir, status.DiskSpace1, status.DiskSpaceTotal1))
+	table.Row("Complete Dir", fmt.Sprintf("%s (%s GB free of %s GB)",
+		status.CompleteDir, status.DiskSpace2, status.DiskSpaceTotal2))
+	return table
+}
 
-		return nil
-	},
+// printServers writes the connection status and article errors of each
+// news server
+func printServers(w io.Writer, servers []models.ServerStatus, articleErrors map[string]int64) {
+	if len(servers) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, output.Bold(fmt.Sprintf("News Servers (%d)", len(servers))))
+	table := output.NewTable("NAME", "PRIORITY", "STATE", "CONNECTIONS", "ARTICLE ERRORS", "ERROR")
+	for _, server := range servers {
+		state := output.Green("active")
+		if !server.Active {
+			state = output.Faint("disabled")
+		} else if server.Error != "" {
+			state = output.Red("error")
+		}
+		table.Row(server.Name, server.Priority, state,
+			fmt.Sprintf("%d/%d", server.ActiveConnections, server.TotalConnections),
+			articleErrors[server.Name], server.Error)
+	}
+	output.Render(w, output.FormatTable, nil, table)
 }
 
-func init() {
-	sabnzbdCmd.AddCommand(infoCmd)
+// printWarnings writes the active warnings, numbered for
+// --dismiss-warning, and how many were dismissed
+func printWarnings(w io.Writer, warnings []models.Warning, dismissed int) {
+	fmt.Fprintln(w)
+	hidden := ""
+	if dismissed > 0 {
+		hidden = fmt.Sprintf(" (%d dismissed)", dismissed)
+	}
+	if len(warnings) == 0 {
+		fmt.Fprintln(w, "✅ No active warnings"+hidden)
+		return
+	}
+
+	fmt.Fprintln(w, output.Bold(fmt.Sprintf("Warnings (%d)", len(warnings)))+hidden)
+	table := output.NewTable("#", "TIME", "TYPE", "TEXT")
+	for i, warning := range warnings {
+		when := ""
+		if warning.Time > 0 {
+			when = time.Unix(warning.Time, 0).Format("2006-01-02 15:04:05")
+		}
+		table.Row(i+1, when, colorWarning(warning.Type), warning.Text)
+	}
+	output.Render(w, output.FormatTable, nil, table)
+}
+
+// colorWarning colors a warning type by its severity
+func colorWarning(kind string) string {
+	if strings.EqualFold(kind, "error") {
+		return output.Red(kind)
+	}
+	return output.Yellow(kind)
+}
+
+// checkHealth returns a list of problems found in the status and warnings
+func checkHealth(status *models.FullStatus, warnings []models.Warning, minFree float64) []string {
+	var problems []string
+
+	dirs := []struct {
+		name string
+		free string
+	}{
+		{"download dir", status.DiskSpace1},
+		{"complete dir", status.DiskSpace2},
+	}
+	for _, dir := range dirs {
+		free, err := strconv.ParseFloat(dir.free, 64)
+		if err != nil {
+			continue
+		}
+		if free < minFree {
+			problems = append(problems, fmt.Sprintf("%s has %.2f GB free (minimum %.2f GB)", dir.name, free, minFree))
+		}
+	}
+
+	if len(warnings) > 0 {
+		problems = append(problems, fmt.Sprintf("%d active warning(s)", len(warnings)))
+	}
+
+	return problems
 }
@@ -0,0 +1,135 @@
+package sabnzbd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestInfo(t *testing.T) {
+	out := mustRun(t, "info")
+	testutil.AssertContains(t, out,
+		"Version            4.3.2",
+		"Speed              12.5 M",
+		"Active Downloads   2",
+		"Download Dir       /downloads/incomplete (120.50 GB free of 500.00 GB)",
+		"Modules            7zip 23.01, sabctools 8.2.0, unrar 7.00",
+		"News Servers (1)",
+		"NAME               PRIORITY   STATE    CONNECTIONS   ARTICLE ERRORS",
+		"news.example.com   0          active   8/20          42",
+		"✅ No active warnings")
+}
+
+func TestInfoDisplayName(t *testing.T) {
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) {
+		s.FullStatus.Servers[0].Name = "Example News"
+		s.Config["servers"].([]any)[0].(map[string]any)["displayname"] = "Example News"
+	})
+
+	out, err := env.Run(t, "sabnzbd", "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "Example News   0          active   8/20          42")
+}
+
+func TestInfoCheck(t *testing.T) {
+	out := mustRun(t, "info", "--check")
+	testutil.AssertContains(t, out, "✅ Health check passed")
+
+	_, err := run(t, "info", "--check", "--min-free", "200")
+	if err == nil || !strings.Contains(err.Error(), "download dir has 120.50 GB free") {
+		t.Errorf("error = %v, want a low disk space failure", err)
+	}
+}
+
+func TestInfoWarnings(t *testing.T) {
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) {
+		s.Warnings = []models.Warning{{Text: "Server timed out", Type: "WARNING"}}
+	})
+
+	out, err := env.Run(t, "sabnzbd", "info", "--check")
+	testutil.AssertContains(t, out, "Warnings (1)", "WARNING   Server timed out")
+	if err == nil || !strings.Contains(err.Error(), "1 active warning(s)") {
+		t.Errorf("error = %v, want an active warning failure", err)
+	}
+}
+
+func TestInfoClearWarnings(t *testing.T) {
+	out := mustRun(t, "info", "--clear-warnings")
+	testutil.AssertContains(t, out, "✅ Successfully cleared all warnings")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("mode") != "warnings" || call.Get("name") != "clear" {
+		t.Errorf("last call = %v, want warnings clear", call)
+	}
+}
+
+func TestInfoDismissWarning(t *testing.T) {
+	env.Reset()
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	warnings := []models.Warning{
+		{Text: "Server timed out", Type: "WARNING", Time: 1700000000},
+		{Text: "Disk almost full", Type: "WARNING", Time: 1700000100},
+	}
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) { s.Warnings = warnings })
+
+	out, err := env.Run(t, "sabnzbd", "info", "--dismiss-warning", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "✅ Dismissed warning 1: Server timed out")
+
+	out, err = env.Run(t, "sabnzbd", "info", "--check")
+	testutil.AssertContains(t, out, "Warnings (1) (1 dismissed)", "1   ")
+	if strings.Contains(out, "Server timed out") {
+		t.Errorf("dismissed warning is shown:\n%s", out)
+	}
+	if err == nil || !strings.Contains(err.Error(), "1 active warning(s)") {
+		t.Errorf("error = %v, want one active warning", err)
+	}
+
+	out, err = env.Run(t, "sabnzbd", "info", "--dismiss-warning", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "Dismissed warning 1: Disk almost full")
+	if out, err = env.Run(t, "sabnzbd", "info", "--check"); err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "✅ No active warnings (2 dismissed)")
+
+	if _, err := env.Run(t, "sabnzbd", "info", "--dismiss-warning", "1"); err == nil {
+		t.Error("dismissing a warning that is not listed succeeded")
+	}
+	if state := env.Sabnzbd.State(); len(state.Warnings) != 2 {
+		t.Errorf("Sabnzbd warnings = %v, want both kept", state.Warnings)
+	}
+}
+
+func TestInfoWatch(t *testing.T) {
+	env.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	out, err := env.RunContext(t, ctx, "sabnzbd", "info", "--watch=100ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out,
+		"Every 100ms: sabnzbd info",
+		"Speed              12.5 M   █",
+		"News Servers (1)")
+
+	if _, err := run(t, "info", "--watch", "--check"); err == nil {
+		t.Error("expected --watch and --check to be rejected together")
+	}
+}
@@ -0,0 +1,151 @@
+package sabnzbd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+var (
+	orphansAll bool
+)
+
+// orphansCmd represents the orphans command
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Manage orphaned jobs",
+	Long: `List, re-add or delete orphaned jobs left in the incomplete folder.
+
+Orphaned jobs are usually left behind after a crash and keep using disk space
+until they are re-added to the queue or deleted.
+
+Examples:
+  sabnzbd orphans list
+  sabnzbd orphans add "Some.Show.S01E01"
+  sabnzbd orphans delete --all`,
+}
+
+// orphansListCmd represents the orphans list command
+var orphansListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List orphaned jobs",
+	Long: `Display all orphaned job folders in the incomplete folder.
+
+Examples:
+  sabnzbd orphans list
+  sabnzbd orphans list -o json`,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		orphans, err := client.GetOrphans(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get orphaned jobs: %w", err)
+		}
+
+		format := cmd.OutputFormat(command)
+		if len(orphans) == 0 && format.Text() {
+			fmt.Println("No orphaned jobs found.")
+			return nil
+		}
+
+		table := output.NewTable("FOLDER")
+		for _, folder := range orphans {
+			table.Row(folder)
+		}
+		return output.Print(format, orphans, table)
+	},
+}
+
+// orphansAddCmd represents the orphans add command
+var orphansAddCmd = &cobra.Command{
+	Use:   "add [folder]",
+	Short: "Re-add orphaned jobs to the queue",
+	Long: `Re-add an orphaned job folder to the download queue.
+
+Examples:
+  sabnzbd orphans add "Some.Show.S01E01"
+  sabnzbd orphans add --all`,
+	Args: orphanArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		folder := ""
+		if len(args) == 1 {
+			folder = args[0]
+		}
+
+		if err := client.AddOrphan(ctx, folder); err != nil {
+			return fmt.Errorf("failed to add orphaned job: %w", err)
+		}
+
+		result := map[string]any{"folder": folder, "added": true}
+		if folder == "" {
+			return cmd.Done(command, result, "✅ Successfully re-added all orphaned jobs")
+		}
+		return cmd.Done(command, result, "✅ Successfully re-added orphaned job %s", folder)
+	},
+}
+
+// orphansDeleteCmd represents the orphans delete command
+var orphansDeleteCmd = &cobra.Command{
+	Use:   "delete [folder]",
+	Short: "Delete orphaned jobs from disk",
+	Long: `Delete an orphaned job folder from the incomplete folder.
+
+Examples:
+  sabnzbd orphans delete "Some.Show.S01E01"
+  sabnzbd orphans delete --all`,
+	Args: orphanArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		folder := ""
+		if len(args) == 1 {
+			folder = args[0]
+		}
+
+		if err := client.DeleteOrphan(ctx, folder); err != nil {
+			return fmt.Errorf("failed to delete orphaned job: %w", err)
+		}
+
+		result := map[string]any{"folder": folder, "deleted": true}
+		if folder == "" {
+			return cmd.Done(command, result, "✅ Successfully deleted all orphaned jobs")
+		}
+		return cmd.Done(command, result, "✅ Successfully deleted orphaned job %s", folder)
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(orphansCmd)
+	orphansCmd.AddCommand(orphansListCmd)
+	orphansCmd.AddCommand(orphansAddCmd)
+	orphansCmd.AddCommand(orphansDeleteCmd)
+	orphansAddCmd.Flags().BoolVar(&orphansAll, "all", false, "Re-add all orphaned jobs")
+	orphansDeleteCmd.Flags().BoolVar(&orphansAll, "all", false, "Delete all orphaned jobs")
+}
+
+// orphanArgs requires either a folder or the --all flag, but not both
+func orphanArgs(command *cobra.Command, args []string) error {
+	if orphansAll && len(args) > 0 {
+		return fmt.Errorf("cannot specify a folder together with --all")
+	}
+	if !orphansAll && len(args) != 1 {
+		return fmt.Errorf("specify a folder or use --all")
+	}
+	return nil
+}
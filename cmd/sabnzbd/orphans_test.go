@@ -0,0 +1,59 @@
+package sabnzbd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestOrphansList(t *testing.T) {
+	out := mustRun(t, "orphans", "list")
+	testutil.AssertContains(t, out,
+		"FOLDER\nOrphaned.Job.1\nOrphaned.Job.2\n")
+}
+
+func TestOrphansListJSON(t *testing.T) {
+	out := mustRun(t, "orphans", "list", "-o", "json")
+
+	var orphans []string
+	if err := json.Unmarshal([]byte(out), &orphans); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(orphans) != 2 {
+		t.Errorf("got %d orphans, want 2", len(orphans))
+	}
+}
+
+func TestOrphansAdd(t *testing.T) {
+	out := mustRun(t, "orphans", "add", "Orphaned.Job.1")
+	testutil.AssertContains(t, out, "✅ Successfully re-added orphaned job Orphaned.Job.1")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("name") != "add_orphan" || call.Get("value") != "Orphaned.Job.1" {
+		t.Errorf("last call = %v, want add_orphan", call)
+	}
+
+	out = mustRun(t, "orphans", "add", "--all")
+	testutil.AssertContains(t, out, "✅ Successfully re-added all orphaned jobs")
+}
+
+func TestOrphansDelete(t *testing.T) {
+	out := mustRun(t, "orphans", "delete", "Orphaned.Job.2")
+	testutil.AssertContains(t, out, "✅ Successfully deleted orphaned job Orphaned.Job.2")
+
+	out = mustRun(t, "orphans", "delete", "--all")
+	testutil.AssertContains(t, out, "✅ Successfully deleted all orphaned jobs")
+	if name := env.Sabnzbd.LastCall().Get("name"); name != "delete_all_orphan" {
+		t.Errorf("name = %q, want delete_all_orphan", name)
+	}
+}
+
+func TestOrphansArgs(t *testing.T) {
+	if _, err := run(t, "orphans", "add"); err == nil {
+		t.Error("expected an error without a folder or --all")
+	}
+	if _, err := run(t, "orphans", "delete", "Orphaned.Job.1", "--all"); err == nil {
+		t.Error("expected an error for a folder together with --all")
+	}
+}
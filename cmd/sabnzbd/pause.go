@@ -16,14 +16,19 @@ var pauseCmd = &cobra.Command{
 Examples:
   sabnzbd pause`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		// Pause the queue
-		err := cmd.GetSabnzbdClient().PauseQueue()
+		err = client.PauseQueue(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to pause queue: %w", err)
 		}
 
-		fmt.Println("✅ Successfully paused all downloads")
-		return nil
+		return cmd.Done(command, map[string]any{"paused": true}, "✅ Successfully paused all downloads")
 	},
 }
 
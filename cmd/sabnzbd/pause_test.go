@@ -0,0 +1,16 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestPause(t *testing.T) {
+	out := mustRun(t, "pause")
+	testutil.AssertContains(t, out, "✅ Successfully paused all downloads")
+
+	if mode := env.Sabnzbd.LastCall().Get("mode"); mode != "pause" {
+		t.Errorf("mode = %q, want pause", mode)
+	}
+}
@@ -1,14 +1,17 @@
 package sabnzbd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // queueCmd represents the queue command
@@ -17,93 +20,159 @@ var queueCmd = &cobra.Command{
 	Short: "View current download queue",
 	Long: `Display all downloads currently in your Sabnzbd queue.
 
+With --watch the queue is redrawn in place until Ctrl-C, with the overall
+progress, a sparkline of recent download speeds and the ETA at that speed.
+
 Examples:
    sabnzbd queue                    # View all queued downloads
-   sabnzbd queue --json             # Output in JSON format
+   sabnzbd queue --watch            # Refresh every 2 seconds
+   sabnzbd queue --watch 5s         # Refresh every 5 seconds
+   sabnzbd queue -o wide            # Add category and time and size left
+   sabnzbd queue -o json            # Output in JSON format
+   sabnzbd queue -o jsonpath='{.slots[*].nzo_id}'  # Just the job IDs
+   sabnzbd queue --all-instances    # Merge the queues of every instance
    sabnzbd queue | head -10         # View first 10 downloads`,
 	RunE: func(command *cobra.Command, args []string) error {
-		jsonOutput, _ := command.Flags().GetBool("json")
-
-		// Get queue from Sabnzbd
-		queue, err := cmd.GetSabnzbdClient().GetQueue()
+		interval, err := cmd.WatchInterval(command, args)
 		if err != nil {
-			return fmt.Errorf("failed to get queue: %w", err)
+			return err
 		}
+		if interval == 0 {
+			return printQueue(command, os.Stdout, nil)
+		}
+		speeds := map[string]*speedHistory{}
+		return cmd.Watch(command, interval, func(w io.Writer) error {
+			return printQueue(command, w, speeds)
+		})
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(queueCmd)
+	queueCmd.Flags().Bool("all-instances", false, "Show the queue of every instance")
+	cmd.AddWatchFlag(queueCmd)
+}
 
-		// JSON output mode
-		if jsonOutput {
-			return json.NewEncoder(os.Stdout).Encode(queue)
+// printQueue writes the queue of every instance queried to w. When
+// watching, speeds holds the recent speed of each instance, drawn as a
+// sparkline next to the overall progress and the ETA at that speed.
+func printQueue(command *cobra.Command, w io.Writer, speeds map[string]*speedHistory) error {
+	ctx := command.Context()
+	allInstances := cmd.AllInstances(command)
+
+	// Get the queue of every instance queried
+	results, err := cmd.FanOut(cmd.SabnzbdInstances(command), func(instance string) (*models.Queue, error) {
+		client, err := cmd.GetSabnzbdClientFor(instance)
+		if err != nil {
+			return nil, err
 		}
+		queue, err := client.GetQueue(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get queue: %w", err)
+		}
+		return queue, nil
+	})
+	if err != nil {
+		return err
+	}
 
-		if len(queue.Slots) == 0 {
-			fmt.Println("Download queue is empty.")
-			return nil
+	format := cmd.OutputFormat(command)
+	table := queueTable(results, allInstances)
+
+	// Queues are tagged with their instance when several are queried
+	if !format.Text() {
+		if !allInstances {
+			return output.Render(w, format, results[0].Value, table)
+		}
+		queues := make([]instanceQueue, len(results))
+		for i, result := range results {
+			queues[i] = instanceQueue{Instance: result.Instance, Queue: result.Value}
 		}
+		return output.Render(w, format, queues, table)
+	}
 
-		fmt.Printf("📥 Download Queue (%d active)\n", len(queue.Slots))
-		fmt.Println(strings.Repeat("─", 80))
+	if table.Len() == 0 {
+		fmt.Fprintln(w, "Download queue is empty.")
+		return nil
+	}
+	if err := output.Render(w, format, nil, table); err != nil {
+		return err
+	}
 
-		for i, slot := range queue.Slots {
-			status := getStatusIcon(slot.Status)
-			statusText := slot.Status
-			if statusText == "" {
-				statusText = "Queued"
+	// Overall status of each queue
+	fmt.Fprintln(w)
+	for _, result := range results {
+		queue := result.Value
+		status := output.Green("Downloading")
+		if queue.Paused {
+			status = output.Yellow("Paused")
+		}
+		prefix := ""
+		if allInstances {
+			prefix = "[" + result.Instance + "] "
+		}
+		fmt.Fprintf(w, "%sStatus: %s | Speed: %s | Time Left: %s | Size Left: %s\n",
+			prefix, status, queue.Speed, queue.TimeLeft, queue.SizeLeft)
+
+		if speeds != nil {
+			history := speeds[result.Instance]
+			if history == nil {
+				history = &speedHistory{}
+				speeds[result.Instance] = history
 			}
+			history.add(queue.KBPerSec)
+			fmt.Fprintf(w, "%sProgress: %s | Speed History: %s | ETA: %s\n",
+				prefix, queueProgress(queue), history.sparkline(), history.eta(queue))
+		}
+	}
+	return nil
+}
 
-			// Progress bar
-			progressBar := ""
-			if slot.Percentage != "" && slot.Percentage != "0" {
-				percentage, _ := strconv.Atoi(slot.Percentage)
-				progressBar = createProgressBar(percentage, 20)
-			}
+// instanceQueue is a queue tagged with the instance it belongs to
+type instanceQueue struct {
+	Instance string `json:"instance"`
+	*models.Queue
+}
 
-			fmt.Printf("%d. %s %s\n", i+1, status, slot.Name)
-			fmt.Printf("   📏 Size: %s | ⏱️  ETA: %s\n", slot.Size, slot.ETA)
+// queueTable lists the slots of every queue
+func queueTable(results []cmd.InstanceResult[*models.Queue], allInstances bool) *output.Table {
+	headers := []string{"ID", "NAME", "STATUS", "PROGRESS", "SIZE", "ETA"}
+	if allInstances {
+		headers = append([]string{"INSTANCE"}, headers...)
+	}
+	table := output.NewTable(headers...).Wide("CATEGORY", "TIME LEFT", "SIZE LEFT")
 
-			if progressBar != "" {
-				fmt.Printf("   %s %s%%\n", progressBar, slot.Percentage)
+	for _, result := range results {
+		for _, slot := range result.Value.Slots {
+			status := slot.Status
+			if status == "" {
+				status = "Queued"
 			}
+			percentage, _ := strconv.Atoi(slot.Percentage)
+			progress := fmt.Sprintf("%s %3d%%", createProgressBar(percentage, 10), percentage)
 
-			if slot.Category != "" && slot.Category != "*" {
-				fmt.Printf("   📂 Category: %s\n", slot.Category)
+			row := []any{slot.ID, slot.Name, colorStatus(status), progress, slot.Size, slot.ETA,
+				slot.Category, slot.TimeLeft, slot.SizeLeft}
+			if allInstances {
+				row = append([]any{result.Instance}, row...)
 			}
-			fmt.Println()
-		}
-
-		// Show overall queue status with cool formatting
-		fmt.Println(strings.Repeat("─", 80))
-		status := "🚀 Downloading"
-		if queue.Paused {
-			status = "⏸️  Paused"
+			table.Row(row...)
 		}
-		fmt.Printf("📊 Queue Status: %s\n", status)
-		fmt.Printf("⚡ Speed: %s\n", queue.Speed)
-		fmt.Printf("⏰ Time Left: %s\n", queue.TimeLeft)
-		fmt.Printf("💾 Size Left: %s\n", queue.SizeLeft)
-
-		return nil
-	},
-}
-
-func init() {
-	sabnzbdCmd.AddCommand(queueCmd)
+	}
+	return table
 }
 
-// getStatusIcon returns an appropriate icon for the download status
-func getStatusIcon(status string) string {
+// colorStatus colors a job status by whether it is going well
+func colorStatus(status string) string {
 	switch strings.ToLower(status) {
-	case "downloading":
-		return "⬇️"
-	case "queued":
-		return "⏳"
-	case "paused":
-		return "⏸️"
+	case "downloading", "completed", "active", "finished":
+		return output.Green(status)
+	case "paused", "queued", "repairing", "extracting", "verifying":
+		return output.Yellow(status)
 	case "failed":
-		return "❌"
-	case "completed":
-		return "✅"
+		return output.Red(status)
 	default:
-		return "📄"
+		return status
 	}
 }
 
@@ -122,3 +191,55 @@ func createProgressBar(percentage int, width int) string {
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", empty)
 	return fmt.Sprintf("[%s]", bar)
 }
+
+// queueProgress draws how much of the whole queue is downloaded
+func queueProgress(queue *models.Queue) string {
+	total, _ := strconv.ParseFloat(queue.MB, 64)
+	left, _ := strconv.ParseFloat(queue.MBLeft, 64)
+	percentage := 100
+	if total > 0 {
+		percentage = int((total - left) / total * 100)
+	}
+	return fmt.Sprintf("%s %3d%%", createProgressBar(percentage, 20), percentage)
+}
+
+// speedSamples is how many refreshes of speed a speedHistory keeps
+const speedSamples = 30
+
+// speedHistory is the download speed in KB/s at the last refreshes
+type speedHistory struct {
+	samples []float64
+}
+
+// add records a speed as Sabnzbd reports it in kbpersec
+func (h *speedHistory) add(kbpersec string) {
+	speed, _ := strconv.ParseFloat(kbpersec, 64)
+	h.samples = append(h.samples, speed)
+	if len(h.samples) > speedSamples {
+		h.samples = h.samples[len(h.samples)-speedSamples:]
+	}
+}
+
+// sparkline draws the recorded speeds
+func (h *speedHistory) sparkline() string {
+	return output.Sparkline(h.samples)
+}
+
+// eta returns when the queue will be done at the average recorded speed,
+// which is steadier than the current speed Sabnzbd bases its estimate on
+func (h *speedHistory) eta(queue *models.Queue) string {
+	left, _ := strconv.ParseFloat(queue.MBLeft, 64)
+	if left <= 0 {
+		return "done"
+	}
+	total := 0.0
+	for _, speed := range h.samples {
+		total += speed
+	}
+	if queue.Paused || total <= 0 {
+		return "unknown"
+	}
+	average := total / float64(len(h.samples))
+	remaining := time.Duration(left * 1024 / average * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("%s (%s)", time.Now().Add(remaining).Format("15:04:05"), remaining)
+}
@@ -0,0 +1,81 @@
+package sabnzbd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestQueue(t *testing.T) {
+	out := mustRun(t, "queue")
+	testutil.AssertContains(t, out,
+		"ID              NAME                STATUS        PROGRESS",
+		"SABnzbd_nzo_1   Show.S01E01.1080p   Downloading",
+		"SABnzbd_nzo_2   Show.S01E02.1080p   Queued",
+		"Status: Downloading | Speed: 12.5 M | Time Left: 0:04:20")
+}
+
+func TestQueueJSON(t *testing.T) {
+	out := mustRun(t, "queue", "-o", "json")
+
+	var queue models.Queue
+	if err := json.Unmarshal([]byte(out), &queue); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(queue.Slots) != 2 {
+		t.Errorf("got %d slots, want 2", len(queue.Slots))
+	}
+}
+
+func TestQueueJSONPath(t *testing.T) {
+	out := mustRun(t, "queue", "-o", "jsonpath={.slots[*].nzo_id}")
+	if out != "SABnzbd_nzo_1 SABnzbd_nzo_2\n" {
+		t.Errorf("got %q", out)
+	}
+
+	out = mustRun(t, "queue", "-o", `jsonpath={range .slots[?(@.status=="Queued")]}{.filename}{"\t"}{.size}{"\n"}{end}`)
+	if out != "Show.S01E02.1080p\t3.0 GB\n" {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestQueueEmpty(t *testing.T) {
+	env.Reset()
+	env.Sabnzbd.Update(func(s *testutil.SabnzbdState) { s.Queue.Slots = nil })
+
+	out, err := env.Run(t, "sabnzbd", "queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "Download queue is empty.")
+}
+
+func TestQueueWatch(t *testing.T) {
+	env.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+
+	// Interrupting the watch is not an error
+	out, err := env.RunContext(t, ctx, "sabnzbd", "queue", "--watch", "100ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frames := strings.Count(out, "Every 100ms: sabnzbd queue"); frames < 2 {
+		t.Errorf("got %d frames, want at least 2:\n%s", frames, out)
+	}
+	testutil.AssertContains(t, out,
+		"SABnzbd_nzo_1   Show.S01E01.1080p   Downloading",
+		"Progress: [███████░░░░░░░░░░░░░]  36% | Speed History: ██",
+		"(4m22s)")
+
+	for _, args := range [][]string{{"--watch", "10ms"}, {"--watch", "soon"}, {"extra"}} {
+		if _, err := run(t, append([]string{"queue"}, args...)...); err == nil {
+			t.Errorf("queue %v: expected an error", args)
+		}
+	}
+}
@@ -16,14 +16,19 @@ var resumeCmd = &cobra.Command{
 Examples:
   sabnzbd resume`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		// Resume the queue
-		err := cmd.GetSabnzbdClient().ResumeQueue()
+		err = client.ResumeQueue(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to resume queue: %w", err)
 		}
 
-		fmt.Println("✅ Successfully resumed all downloads")
-		return nil
+		return cmd.Done(command, map[string]any{"paused": false}, "✅ Successfully resumed all downloads")
 	},
 }
 
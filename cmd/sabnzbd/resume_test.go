@@ -0,0 +1,16 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestResume(t *testing.T) {
+	out := mustRun(t, "resume")
+	testutil.AssertContains(t, out, "✅ Successfully resumed all downloads")
+
+	if mode := env.Sabnzbd.LastCall().Get("mode"); mode != "resume" {
+		t.Errorf("mode = %q, want resume", mode)
+	}
+}
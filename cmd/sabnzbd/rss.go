@@ -0,0 +1,158 @@
+package sabnzbd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+var (
+	rssCategory string
+)
+
+// rssCmd represents the rss command
+var rssCmd = &cobra.Command{
+	Use:   "rss",
+	Short: "Manage RSS feeds",
+	Long: `List, add, remove and run the RSS feeds configured in Sabnzbd.
+
+Examples:
+  sabnzbd rss list
+  sabnzbd rss add myfeed "https://indexer.example.com/rss?t=5000" --category tv
+  sabnzbd rss remove myfeed
+  sabnzbd rss run`,
+}
+
+// rssListCmd represents the rss list command
+var rssListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List RSS feeds",
+	Long: `Display all RSS feeds configured in Sabnzbd.
+
+Examples:
+  sabnzbd rss list
+  sabnzbd rss list -o json`,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		feeds, err := client.GetRSSFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get RSS feeds: %w", err)
+		}
+
+		format := cmd.OutputFormat(command)
+		if len(feeds) == 0 && format.Text() {
+			fmt.Println("No RSS feeds configured.")
+			return nil
+		}
+
+		table := output.NewTable("NAME", "ENABLED", "CATEGORY", "URL").Wide("PRIORITY", "SCRIPT")
+		for _, feed := range feeds {
+			enabled := output.Green("yes")
+			if feed.Enable == 0 {
+				enabled = output.Faint("no")
+			}
+			category := feed.Category
+			if category == "*" {
+				category = ""
+			}
+			table.Row(feed.Name, enabled, category, strings.Join(feed.URI, " "), feed.Priority, feed.Script)
+		}
+		return output.Print(format, feeds, table)
+	},
+}
+
+// rssAddCmd represents the rss add command
+var rssAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add an RSS feed",
+	Long: `Add a new, enabled RSS feed to Sabnzbd.
+
+Examples:
+  sabnzbd rss add myfeed "https://indexer.example.com/rss?t=5000"
+  sabnzbd rss add myfeed "https://indexer.example.com/rss?t=5000" --category tv`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		name, uri := args[0], args[1]
+
+		if err := client.AddRSSFeed(ctx, name, uri, rssCategory); err != nil {
+			return fmt.Errorf("failed to add RSS feed: %w", err)
+		}
+
+		return cmd.Done(command, map[string]any{"name": name, "uri": uri, "cat": rssCategory},
+			"✅ Successfully added RSS feed %s", name)
+	},
+}
+
+// rssRemoveCmd represents the rss remove command
+var rssRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an RSS feed",
+	Long: `Remove an RSS feed from Sabnzbd.
+
+Examples:
+  sabnzbd rss remove myfeed`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+
+		if err := client.RemoveRSSFeed(ctx, name); err != nil {
+			return fmt.Errorf("failed to remove RSS feed: %w", err)
+		}
+
+		return cmd.Done(command, map[string]any{"name": name, "removed": true},
+			"✅ Successfully removed RSS feed %s", name)
+	},
+}
+
+// rssRunCmd represents the rss run command
+var rssRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Scan all RSS feeds now",
+	Long: `Trigger an immediate scan of all RSS feeds.
+
+Examples:
+  sabnzbd rss run`,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.RunRSS(ctx); err != nil {
+			return fmt.Errorf("failed to run RSS feeds: %w", err)
+		}
+
+		return cmd.Done(command, map[string]any{"started": true}, "✅ Successfully triggered RSS scan")
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(rssCmd)
+	rssCmd.AddCommand(rssListCmd)
+	rssCmd.AddCommand(rssAddCmd)
+	rssCmd.AddCommand(rssRemoveCmd)
+	rssCmd.AddCommand(rssRunCmd)
+	rssAddCmd.Flags().StringVarP(&rssCategory, "category", "c", "", "Category for downloads from the feed")
+	rssAddCmd.RegisterFlagCompletionFunc("category", completeCategories)
+}
@@ -0,0 +1,56 @@
+package sabnzbd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestRSSList(t *testing.T) {
+	out := mustRun(t, "rss", "list")
+	testutil.AssertContains(t, out,
+		"NAME    ENABLED   CATEGORY   URL",
+		"shows   yes       tv         https://indexer.example.com/rss")
+}
+
+func TestRSSListJSON(t *testing.T) {
+	out := mustRun(t, "rss", "list", "-o", "json")
+
+	var feeds []models.RSSFeed
+	if err := json.Unmarshal([]byte(out), &feeds); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(feeds) != 1 || feeds[0].Name != "shows" {
+		t.Errorf("feeds = %+v, want shows", feeds)
+	}
+}
+
+func TestRSSAdd(t *testing.T) {
+	out := mustRun(t, "rss", "add", "movies", "https://indexer.example.com/movies", "--category", "movies")
+	testutil.AssertContains(t, out, "✅ Successfully added RSS feed movies")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("mode") != "set_config" || call.Get("keyword") != "movies" || call.Get("cat") != "movies" {
+		t.Errorf("last call = %v, want set_config of the movies feed", call)
+	}
+}
+
+func TestRSSRemove(t *testing.T) {
+	out := mustRun(t, "rss", "remove", "shows")
+	testutil.AssertContains(t, out, "✅ Successfully removed RSS feed shows")
+
+	if rss := env.Sabnzbd.State().Config["rss"].([]any); len(rss) != 0 {
+		t.Errorf("rss = %v, want no feeds", rss)
+	}
+}
+
+func TestRSSRun(t *testing.T) {
+	out := mustRun(t, "rss", "run")
+	testutil.AssertContains(t, out, "✅ Successfully triggered RSS scan")
+
+	if runs := env.Sabnzbd.State().RSSRuns; runs != 1 {
+		t.Errorf("RSSRuns = %d, want 1", runs)
+	}
+}
@@ -1,8 +1,11 @@
 package sabnzbd
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/api/sabnzbd"
 )
 
 // sabnzbdCmd represents the sabnzbd command
@@ -15,3 +18,31 @@ var sabnzbdCmd = &cobra.Command{
 func init() {
 	cmd.RootCmd().AddCommand(sabnzbdCmd)
 }
+
+// completeJobs completes the nzo_ids of the queued jobs, described by name
+var completeJobs = cmd.CompleteSabnzbd("queue", cmd.QueueCompletionCacheTTL, func(ctx context.Context, client *sabnzbd.Client) ([]cobra.Completion, error) {
+	queue, err := client.GetQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, len(queue.Slots))
+	for i, slot := range queue.Slots {
+		completions[i] = cobra.CompletionWithDesc(slot.ID, slot.Name)
+	}
+	return completions, nil
+})
+
+// completeCategories completes the categories, leaving out the default "*"
+var completeCategories = cmd.CompleteSabnzbd("categories", cmd.CompletionCacheTTL, func(ctx context.Context, client *sabnzbd.Client) ([]cobra.Completion, error) {
+	categories, err := client.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var completions []cobra.Completion
+	for _, category := range categories {
+		if category != "*" {
+			completions = append(completions, category)
+		}
+	}
+	return completions, nil
+})
@@ -0,0 +1,41 @@
+package sabnzbd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// complete resets the fake servers and the completion cache and asks for
+// the completions of a sabnzbd command line
+func complete(t *testing.T, args ...string) string {
+	t.Helper()
+	env.Reset()
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	return env.MustRun(t, append([]string{"__complete", "sabnzbd"}, args...)...)
+}
+
+func TestCompleteJobs(t *testing.T) {
+	out := complete(t, "delete", "")
+	testutil.AssertContains(t, out,
+		"SABnzbd_nzo_1\tShow.S01E01.1080p",
+		"SABnzbd_nzo_2\tShow.S01E02.1080p",
+		":4")
+
+	out = complete(t, "files", "")
+	testutil.AssertContains(t, out, "SABnzbd_nzo_1\t")
+}
+
+func TestCompleteCategories(t *testing.T) {
+	out := complete(t, "add", "https://example.com/x.nzb", "--category", "")
+	testutil.AssertContains(t, out, "movies\n", "tv\n")
+	if strings.Contains(out, "*\n") {
+		t.Errorf("the default category should not be offered:\n%s", out)
+	}
+
+	out = complete(t, "rss", "add", "feed", "https://example.com/rss", "-c", "")
+	testutil.AssertContains(t, out, "tv\n")
+}
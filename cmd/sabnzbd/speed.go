@@ -23,21 +23,25 @@ Examples:
   sabnzbd speed 0       # Remove speed limit`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
 		limit := args[0]
 
 		// Set speed limit
-		err := cmd.GetSabnzbdClient().SetSpeedLimit(limit)
+		err = client.SetSpeedLimit(ctx, limit)
 		if err != nil {
 			return fmt.Errorf("failed to set speed limit: %w", err)
 		}
 
+		result := map[string]any{"speedlimit": limit}
 		if limit == "0" {
-			fmt.Println("✅ Successfully removed speed limit")
-		} else {
-			fmt.Printf("✅ Successfully set speed limit to %s\n", limit)
+			return cmd.Done(command, result, "✅ Successfully removed speed limit")
 		}
-
-		return nil
+		return cmd.Done(command, result, "✅ Successfully set speed limit to %s", limit)
 	},
 }
 
@@ -0,0 +1,22 @@
+package sabnzbd
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestSpeed(t *testing.T) {
+	out := mustRun(t, "speed", "50")
+	testutil.AssertContains(t, out, "✅ Successfully set speed limit to 50")
+
+	call := env.Sabnzbd.LastCall()
+	if call.Get("mode") != "speedlimit" || call.Get("value") != "50" {
+		t.Errorf("last call = %v, want speedlimit 50", call)
+	}
+}
+
+func TestSpeedRemoveLimit(t *testing.T) {
+	out := mustRun(t, "speed", "0")
+	testutil.AssertContains(t, out, "✅ Successfully removed speed limit")
+}
@@ -0,0 +1,283 @@
+package sabnzbd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+var (
+	statsServer  string
+	statsDays    int
+	statsDaily   bool
+	statsBlocks  map[string]string
+	statsWarnPct float64
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show bandwidth usage per news server",
+	Long: `Display total, monthly, weekly and daily download volume for each news
+server, with a daily breakdown chart.
+
+Block accounts can be tracked with --block: give the server name and the size
+of the block. The command exits with a non-zero status when usage of any block
+reaches the --warn percentage.
+
+Examples:
+  sabnzbd stats                                     # Summary and 14 day chart
+  sabnzbd stats --days 30 --server news.example.com # Chart for one server
+  sabnzbd stats -o json                             # Output in JSON format
+  sabnzbd stats -o csv                              # Summary as CSV
+  sabnzbd stats -o csv --daily                      # Daily breakdown as CSV
+  sabnzbd stats --block blocknews=500G --warn 90    # Alert at 90% of a block`,
+	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSabnzbdClient()
+		if err != nil {
+			return err
+		}
+
+		format := cmd.OutputFormat(command)
+		if csvOutput, _ := command.Flags().GetBool("csv"); csvOutput {
+			format = output.FormatCSV
+		}
+
+		// Get server statistics from Sabnzbd
+		stats, err := client.GetServerStats(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get server stats: %w", err)
+		}
+
+		if statsServer != "" {
+			server, ok := stats.Servers[statsServer]
+			if !ok {
+				return fmt.Errorf("server '%s' not found in statistics", statsServer)
+			}
+			stats.Servers = map[string]models.ServerStatistics{statsServer: server}
+		}
+
+		// Blocks are checked in every format, as scripts rely on the alert
+		blocks, err := blockUsage(stats, statsBlocks)
+		if err != nil {
+			return err
+		}
+
+		// CSV keeps sizes in bytes for spreadsheets
+		table := statsTable(stats, statsDaily, format == output.FormatCSV)
+		if err := output.Print(format, stats, table); err != nil {
+			return err
+		}
+		if format.Text() {
+			if !statsDaily {
+				printDailyChart(stats, statsDays)
+			}
+			if len(blocks) > 0 {
+				printBlockUsage(blocks, statsWarnPct)
+			}
+		}
+
+		var alerts []string
+		for _, block := range blocks {
+			if block.Used >= statsWarnPct {
+				alerts = append(alerts, fmt.Sprintf("%s at %.1f%%", block.Server, block.Used))
+			}
+		}
+		if len(alerts) > 0 {
+			command.SilenceUsage = true
+			return fmt.Errorf("block account usage alert: %s", strings.Join(alerts, "; "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	sabnzbdCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("csv", false, "Output results in CSV format")
+	statsCmd.Flags().MarkDeprecated("csv", "use --output csv instead")
+	statsCmd.Flags().BoolVar(&statsDaily, "daily", false, "List the daily breakdown instead of the summary")
+	statsCmd.Flags().StringVar(&statsServer, "server", "", "Only show statistics for this server")
+	statsCmd.Flags().IntVar(&statsDays, "days", 14, "Number of days to include in the chart")
+	statsCmd.Flags().StringToStringVar(&statsBlocks, "block", nil, "Block account size per server (e.g. name=500G)")
+	statsCmd.Flags().Float64Var(&statsWarnPct, "warn", 90, "Block usage percentage at which to alert")
+}
+
+// sortedServerNames returns the server names in alphabetical order
+func sortedServerNames(stats *models.ServerStats) []string {
+	names := make([]string, 0, len(stats.Servers))
+	for name := range stats.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// statsTable lists the totals of each server, or with daily the bytes
+// downloaded per server and day. Sizes are in bytes when raw.
+func statsTable(stats *models.ServerStats, daily, raw bool) *output.Table {
+	size := output.FormatBytes
+	if raw {
+		size = func(bytes int64) string { return strconv.FormatInt(bytes, 10) }
+	}
+	names := sortedServerNames(stats)
+
+	if daily {
+		table := output.NewTable("SERVER", "DATE", "BYTES")
+		for _, name := range names {
+			s := stats.Servers[name]
+			dates := make([]string, 0, len(s.Daily))
+			for date := range s.Daily {
+				dates = append(dates, date)
+			}
+			sort.Strings(dates)
+			for _, date := range dates {
+				table.Row(name, date, size(s.Daily[date]))
+			}
+		}
+		return table
+	}
+
+	table := output.NewTable("SERVER", "TOTAL", "MONTH", "WEEK", "DAY")
+	for _, name := range names {
+		s := stats.Servers[name]
+		table.Row(name, size(s.Total), size(s.Month), size(s.Week), size(s.Day))
+	}
+	// The totals cover every server, so they are left out when --server
+	// picks one, and from CSV, which can be summed
+	if !raw && statsServer == "" {
+		table.Row("All servers", size(stats.Total), size(stats.Month), size(stats.Week), size(stats.Day))
+	}
+	return table
+}
+
+// printDailyChart prints a bar chart of bytes downloaded per day
+func printDailyChart(stats *models.ServerStats, days int) {
+	if days <= 0 {
+		return
+	}
+
+	totals := make(map[string]int64)
+	for _, s := range stats.Servers {
+		for date, bytes := range s.Daily {
+			totals[date] += bytes
+		}
+	}
+
+	var max int64
+	dates := make([]string, 0, days)
+	today := time.Now()
+	for i := days - 1; i >= 0; i-- {
+		date := today.AddDate(0, 0, -i).Format("2006-01-02")
+		dates = append(dates, date)
+		if totals[date] > max {
+			max = totals[date]
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("📈 Daily Usage (last %d days)\n", days)
+	fmt.Println(strings.Repeat("─", 80))
+
+	const width = 50
+	for _, date := range dates {
+		filled := 0
+		if max > 0 {
+			filled = int(totals[date] * width / max)
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+		fmt.Printf("%s %s %s\n", date, bar, output.FormatBytes(totals[date]))
+	}
+}
+
+// block is the usage of a block account
+type block struct {
+	Server string
+	Size   int64
+	Total  int64
+	Used   float64
+}
+
+// blockUsage works out the usage of each block account given by --block,
+// in order of server name
+func blockUsage(stats *models.ServerStats, blocks map[string]string) ([]block, error) {
+	names := make([]string, 0, len(blocks))
+	for name := range blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usage := make([]block, 0, len(names))
+	for _, name := range names {
+		size, err := parseSize(blocks[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid block size for %s: %w", name, err)
+		}
+		server, ok := stats.Servers[name]
+		if !ok {
+			return nil, fmt.Errorf("server '%s' not found in statistics", name)
+		}
+		usage = append(usage, block{
+			Server: name,
+			Size:   size,
+			Total:  server.Total,
+			Used:   float64(server.Total) / float64(size) * 100,
+		})
+	}
+	return usage, nil
+}
+
+// printBlockUsage prints the usage of each block account, marking those at
+// or above the warning percentage
+func printBlockUsage(blocks []block, warnPct float64) {
+	fmt.Println()
+	fmt.Println("📦 Block Accounts")
+	fmt.Println(strings.Repeat("─", 80))
+
+	for _, b := range blocks {
+		icon := "🟢"
+		if b.Used >= warnPct {
+			icon = "🔴"
+		}
+		remaining := max(b.Size-b.Total, 0)
+		fmt.Printf("%s %s %s %.1f%% (%s left of %s)\n", icon, truncate(b.Server, 30),
+			createProgressBar(int(b.Used), 20), b.Used, output.FormatBytes(remaining), output.FormatBytes(b.Size))
+	}
+}
+
+// parseSize parses a size such as "500G", "1.5T" or "800MB" into bytes
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "B")
+
+	multiplier := int64(1)
+	if s != "" {
+		if idx := strings.IndexByte("KMGTPE", s[len(s)-1]); idx >= 0 {
+			for i := 0; i <= idx; i++ {
+				multiplier *= 1024
+			}
+			s = s[:len(s)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("must be a positive size such as 500G")
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// truncate shortens a string to the given length
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length-3] + "..."
+}
@@ -0,0 +1,75 @@
+package sabnzbd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestStats(t *testing.T) {
+	out := mustRun(t, "stats")
+	testutil.AssertContains(t, out,
+		"SERVER             TOTAL    MONTH      WEEK      DAY",
+		"news.example.com",
+		"2.0 TB",
+		"300.0 GB",
+		"All servers",
+		"Daily Usage (last 14 days)")
+}
+
+func TestStatsJSON(t *testing.T) {
+	out := mustRun(t, "stats", "-o", "json")
+
+	var stats models.ServerStats
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if _, ok := stats.Servers["news.example.com"]; !ok {
+		t.Errorf("servers = %v, want news.example.com", stats.Servers)
+	}
+}
+
+func TestStatsCSV(t *testing.T) {
+	out := mustRun(t, "stats", "-o", "csv")
+	testutil.AssertContains(t, out,
+		"server,total,month,week,day",
+		"news.example.com,2199023255552,322122547200,53687091200,5368709120")
+
+	out = mustRun(t, "stats", "-o", "csv", "--daily")
+	testutil.AssertContains(t, out, "server,date,bytes", ",5368709120")
+}
+
+func TestStatsUnknownServer(t *testing.T) {
+	if _, err := run(t, "stats", "--server", "missing"); err == nil {
+		t.Error("expected an error for an unknown server")
+	}
+}
+
+func TestStatsBlocks(t *testing.T) {
+	// The --block map is not reset between runs
+	t.Cleanup(func() { clear(statsBlocks) })
+
+	out := mustRun(t, "stats", "--block", "news.example.com=4T")
+	testutil.AssertContains(t, out, "Block Accounts", "🟢 news.example.com", "50.0%")
+
+	clear(statsBlocks)
+	_, err := run(t, "stats", "--block", "news.example.com=2T")
+	if err == nil || !strings.Contains(err.Error(), "block account usage alert") {
+		t.Errorf("error = %v, want a block usage alert", err)
+	}
+
+	// Scripts using structured output get the alert too
+	for _, args := range [][]string{{"-o", "json"}, {"-o", "csv"}, {"--csv"}} {
+		clear(statsBlocks)
+		out, err := run(t, append([]string{"stats", "--block", "news.example.com=2T"}, args...)...)
+		if err == nil || !strings.Contains(err.Error(), "block account usage alert") {
+			t.Errorf("%v: error = %v, want a block usage alert", args, err)
+		}
+		if strings.Contains(out, "Block Accounts") {
+			t.Errorf("%v: structured output has the block section:\n%s", args, out)
+		}
+	}
+}
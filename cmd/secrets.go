@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/output"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the encrypted secret store",
+	Long: `Keep API keys and passwords out of the configuration file.
+
+Each secret is resolved from the first of these sources that is set:
+
+  1. The environment, e.g. SONCLI_SONARR_API_KEY
+  2. A command in the config file, e.g. api_key_cmd: "pass show sonarr"
+  3. A file in the config file, e.g. api_key_file: /run/secrets/sonarr
+  4. The value in the config file, e.g. api_key: "..."
+  5. The encrypted secret store managed by these commands
+
+The store passphrase is read from SONCLI_PASSPHRASE, from the output of
+secrets.passphrase_cmd, or asked for on the terminal.
+
+Secrets: ` + strings.Join(config.SecretNames, ", ") + `
+Secrets of a named instance are prefixed with its name, e.g. 4k.sonarr.api_key.
+
+Examples:
+  sonarr-sabnzbd-cli secrets set sonarr.api_key
+  pass show sabnzbd | sonarr-sabnzbd-cli secrets set sabnzbd.api_key
+  sonarr-sabnzbd-cli secrets list
+  sonarr-sabnzbd-cli secrets migrate   # Move plaintext secrets into the store`,
+}
+
+// secretsSetCmd represents the secrets set command
+var secretsSetCmd = &cobra.Command{
+	Use:       "set <name>",
+	Short:     "Store a secret",
+	Long:      `Store a secret in the encrypted store. The value is read from stdin.`,
+	Args:      secretNameArgs,
+	ValidArgs: slices.Clone(config.SecretNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		var value string
+		var err error
+		if secrets.IsTerminal() {
+			value, err = secrets.ReadSecret(fmt.Sprintf("Value for %s: ", name))
+		} else {
+			value, err = bufio.NewReader(os.Stdin).ReadString('\n')
+			value = strings.TrimRight(value, "\r\n")
+			if value != "" {
+				err = nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read secret: %w", err)
+		}
+		if value == "" {
+			return fmt.Errorf("secret value is empty")
+		}
+
+		store, err := config.OpenStore(cfg.Secrets)
+		if err != nil {
+			return fmt.Errorf("failed to open secret store: %w", err)
+		}
+		store.Set(name, value)
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("failed to save secret store: %w", err)
+		}
+
+		return Done(cmd, map[string]any{"name": name, "stored": true}, "✅ Successfully stored %s", name)
+	},
+}
+
+// secretsDeleteCmd represents the secrets delete command
+var secretsDeleteCmd = &cobra.Command{
+	Use:       "delete <name>",
+	Short:     "Remove a secret from the store",
+	Args:      secretNameArgs,
+	ValidArgs: slices.Clone(config.SecretNames),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		store, err := config.OpenStore(cfg.Secrets)
+		if err != nil {
+			return fmt.Errorf("failed to open secret store: %w", err)
+		}
+		if !store.Delete(name) {
+			return fmt.Errorf("secret '%s' is not in the store", name)
+		}
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("failed to save secret store: %w", err)
+		}
+
+		return Done(cmd, map[string]any{"name": name, "deleted": true}, "✅ Successfully deleted %s", name)
+	},
+}
+
+// secretsListCmd represents the secrets list command
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show where each secret comes from",
+	Long:  `Show the source each secret is resolved from. Values are never printed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.StorePath(cfg.Secrets)
+		if err != nil {
+			return err
+		}
+
+		var stored []string
+		if _, err := os.Stat(path); err == nil {
+			store, err := config.OpenStore(cfg.Secrets)
+			if err != nil {
+				return fmt.Errorf("failed to open secret store: %w", err)
+			}
+			stored = store.Names()
+		}
+
+		type secretInfo struct {
+			Name   string `json:"name"`
+			Set    bool   `json:"set"`
+			Source string `json:"source"`
+		}
+		var secrets []secretInfo
+		table := output.NewTable("NAME", "SOURCE")
+		for _, name := range config.AllSecretNames(cfg) {
+			source := config.SecretOrigin(cfg, name, slices.Contains(stored, name))
+			secrets = append(secrets, secretInfo{Name: name, Set: source != "", Source: source})
+			if source == "" {
+				source = output.Faint("not set")
+			}
+			table.Row(name, source)
+		}
+
+		format := OutputFormat(cmd)
+		if err := output.Print(format, secrets, table); err != nil {
+			return err
+		}
+		if format.Text() {
+			fmt.Printf("\nStore: %s\n", path)
+		}
+		return nil
+	},
+}
+
+// secretsMigrateCmd represents the secrets migrate command
+var secretsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move plaintext secrets from the config file into the store",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plain := make(map[string]*string)
+		var moved []string
+		for _, name := range config.AllSecretNames(cfg) {
+			if value, _, _, _ := config.SecretFields(cfg, name); *value != "" {
+				plain[name] = value
+				moved = append(moved, name)
+			}
+		}
+		if len(moved) == 0 {
+			return Done(cmd, map[string]any{"moved": []string{}}, "✅ No plaintext secrets in the config file")
+		}
+
+		store, err := config.OpenStore(cfg.Secrets)
+		if err != nil {
+			return fmt.Errorf("failed to open secret store: %w", err)
+		}
+		for _, name := range moved {
+			store.Set(name, *plain[name])
+		}
+		// Save the store before removing anything from the config file
+		if err := store.Save(); err != nil {
+			return fmt.Errorf("failed to save secret store: %w", err)
+		}
+		for _, name := range moved {
+			*plain[name] = ""
+		}
+		if err := config.SaveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		return Done(cmd, map[string]any{"moved": moved},
+			"✅ Successfully moved %s into the secret store", strings.Join(moved, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsDeleteCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsMigrateCmd)
+}
+
+// secretNameArgs accepts a single known secret name
+func secretNameArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+		return err
+	}
+	if names := config.AllSecretNames(cfg); !slices.Contains(names, args[0]) {
+		return fmt.Errorf("unknown secret '%s': expected one of %s", args[0], strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/secrets"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestSecrets(t *testing.T) {
+	restoreConfig(t)
+	t.Setenv(secrets.PassphraseEnv, "correct horse")
+	storePath := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := env.WriteConfig("secrets:\n  file: " + storePath + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	out := env.MustRun(t, "secrets", "list")
+	testutil.AssertContains(t, out, "sonarr.api_key     config file (plaintext)", "sabnzbd.password   not set")
+
+	out, err := env.RunWithInput(t, "hunter2\n", "secrets", "set", "sabnzbd.password")
+	if err != nil {
+		t.Fatalf("secrets set: %v\n%s", err, out)
+	}
+	testutil.AssertContains(t, out, "Successfully stored sabnzbd.password")
+
+	out = env.MustRun(t, "secrets", "list")
+	testutil.AssertContains(t, out, "sabnzbd.password   secret store")
+
+	out = env.MustRun(t, "secrets", "migrate")
+	testutil.AssertContains(t, out, "Successfully moved sonarr.api_key, sabnzbd.api_key into the secret store")
+
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), testutil.SonarrAPIKey) || strings.Contains(string(data), testutil.SabnzbdAPIKey) {
+		t.Errorf("config still contains API keys:\n%s", data)
+	}
+
+	store, err := secrets.Open(storePath, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get("sonarr.api_key"); got != testutil.SonarrAPIKey {
+		t.Errorf("stored sonarr.api_key = %q, want %q", got, testutil.SonarrAPIKey)
+	}
+	if got, _ := store.Get("sabnzbd.password"); got != "hunter2" {
+		t.Errorf("stored sabnzbd.password = %q, want %q", got, "hunter2")
+	}
+
+	env.MustRun(t, "secrets", "delete", "sabnzbd.password")
+	if _, err := env.Run(t, "secrets", "delete", "sabnzbd.password"); err == nil {
+		t.Error("deleting a missing secret succeeded")
+	}
+	if _, err := env.Run(t, "secrets", "set", "sonarr.password"); err == nil {
+		t.Error("setting an unknown secret succeeded")
+	}
+}
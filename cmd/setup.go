@@ -0,0 +1,534 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/api/httpclient"
+	"sonarr-sabnzbd-cli/internal/api/sabnzbd"
+	"sonarr-sabnzbd-cli/internal/api/sonarr"
+	"sonarr-sabnzbd-cli/internal/config"
+	"sonarr-sabnzbd-cli/internal/discover"
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// setupCmd represents the setup command
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Interactive setup wizard",
+	Long: `Run an interactive setup wizard to configure Sonarr and Sabnzbd connections.
+
+This command will guide you through:
+- Finding Sonarr and Sabnzbd on this machine or the Docker host
+- Configuring your Sonarr server connection
+- Configuring your Sabnzbd server connection
+- Testing the connections, asking again for settings that fail
+- Saving the configuration
+
+Answers can be given up front with flags or SONCLI_ environment variables,
+e.g. SONCLI_SONARR_HOST. They become the defaults of the questions, or with
+--non-interactive the whole configuration, which is only saved if both
+connections work. API keys can be read from Sonarr's config.xml and from
+sabnzbd.ini. API keys and passwords from the environment keep being read
+from there and are not saved.
+
+Examples:
+  sonarr-sabnzbd-cli setup
+  sonarr-sabnzbd-cli setup --sonarr-config-xml /srv/sonarr/config.xml --sabnzbd-ini /srv/sabnzbd/sabnzbd.ini
+  sonarr-sabnzbd-cli setup --non-interactive --sonarr-url http://nas:8989 --sonarr-api-key KEY \
+    --sabnzbd-url http://nas:8080 --sabnzbd-api-key KEY`,
+	Annotations: map[string]string{SkipConfigAnnotation: "true"},
+	Args:        cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+		answers, err := setupAnswers(cmd)
+		if err != nil {
+			return err
+		}
+		if nonInteractive {
+			return runSetup(cmd.Context(), answers)
+		}
+		return runSetupWizard(cmd.Context(), answers)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+	setupCmd.Flags().Bool("non-interactive", false, "Don't ask questions; take every answer from flags, the environment and discovery")
+	setupCmd.Flags().Bool("no-discover", false, "Don't look for servers on this machine and the Docker host")
+	setupCmd.Flags().String("sonarr-host", "", "Sonarr host")
+	setupCmd.Flags().String("sonarr-port", "", "Sonarr port")
+	setupCmd.Flags().String("sonarr-config-xml", "", "Read the Sonarr API key, port and URL base from this config.xml")
+	setupCmd.Flags().String("sabnzbd-host", "", "Sabnzbd host")
+	setupCmd.Flags().String("sabnzbd-port", "", "Sabnzbd port")
+	setupCmd.Flags().String("sabnzbd-ini", "", "Read the Sabnzbd API key, port, URL base and login from this sabnzbd.ini")
+	setupCmd.Flags().String("sabnzbd-username", "", "Sabnzbd username, if authentication is enabled")
+	setupCmd.Flags().String("sabnzbd-password", "", "Sabnzbd password, if authentication is enabled")
+}
+
+// setupAnswers returns the configuration setup starts from: the defaults,
+// overridden in turn by discovered servers, the environment, the servers'
+// own files and flags
+func setupAnswers(cmd *cobra.Command) (*models.Config, error) {
+	answers := &models.Config{
+		Sonarr: models.SonarrConfig{
+			Host:            "localhost",
+			Port:            8989,
+			Timeout:         30 * time.Second,
+			VersionCacheTTL: 24 * time.Hour,
+		},
+		Sabnzbd: models.SabnzbdConfig{
+			Host:    "localhost",
+			Port:    8080,
+			Timeout: 30 * time.Second,
+		},
+		UI: models.UIConfig{
+			Colors:     true,
+			MaxResults: 10,
+		},
+		HTTP: models.HTTPConfig{
+			Retries:      3,
+			RetryWait:    500 * time.Millisecond,
+			RetryMaxWait: 10 * time.Second,
+			RateBurst:    5,
+		},
+	}
+	sonarrSettings, sabnzbdSettings := &answers.Sonarr, &answers.Sabnzbd
+
+	// Servers are only looked for where no address was given
+	noDiscover, _ := cmd.Flags().GetBool("no-discover")
+	given := func(service string) bool {
+		for _, field := range []string{"url", "host", "port"} {
+			if setupValue(cmd, service+"-"+field, service+"."+field) != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if !noDiscover && !given("sonarr") {
+		if server, ok := discover.Sonarr(cmd.Context()); ok {
+			fmt.Printf("🔎 Found Sonarr at %s:%d\n", server.Host, server.Port)
+			sonarrSettings.Host, sonarrSettings.Port = server.Host, server.Port
+		}
+	}
+	if !noDiscover && !given("sabnzbd") {
+		if server, ok := discover.Sabnzbd(cmd.Context()); ok {
+			fmt.Printf("🔎 Found Sabnzbd at %s:%d%s\n", server.Host, server.Port, server.URLBase)
+			sabnzbdSettings.Host, sabnzbdSettings.Port = server.Host, server.Port
+			sabnzbdSettings.URLBase = server.URLBase
+		}
+	}
+
+	// Secrets from the environment are used but not saved, like at run time
+	fromEnv := func(name string, value *string, external *map[string]bool, field string) {
+		if env := os.Getenv(config.SecretEnv(name)); env != "" {
+			*value = env
+			if *external == nil {
+				*external = make(map[string]bool)
+			}
+			(*external)[field] = true
+		}
+	}
+	fromEnv(config.SonarrAPIKey, &sonarrSettings.APIKey, &sonarrSettings.External, "api_key")
+	fromEnv(config.SabnzbdAPIKey, &sabnzbdSettings.APIKey, &sabnzbdSettings.External, "api_key")
+	fromEnv(config.SabnzbdPassword, &sabnzbdSettings.Password, &sabnzbdSettings.External, "password")
+
+	if path, _ := cmd.Flags().GetString("sonarr-config-xml"); path != "" {
+		settings, err := discover.SonarrConfigXML(path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("🔑 Read the Sonarr API key from %s\n", path)
+		sonarrSettings.APIKey, sonarrSettings.URLBase = settings.APIKey, settings.URLBase
+		delete(sonarrSettings.External, "api_key")
+		if settings.Port != 0 && !given("sonarr") {
+			sonarrSettings.Port = settings.Port
+		}
+	}
+	if path, _ := cmd.Flags().GetString("sabnzbd-ini"); path != "" {
+		settings, err := discover.SabnzbdINI(path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("🔑 Read the Sabnzbd API key from %s\n", path)
+		sabnzbdSettings.APIKey, sabnzbdSettings.URLBase = settings.APIKey, settings.URLBase
+		sabnzbdSettings.Username, sabnzbdSettings.Password = settings.Username, settings.Password
+		sabnzbdSettings.External = nil
+		if settings.Port != 0 && !given("sabnzbd") {
+			sabnzbdSettings.Port = settings.Port
+		}
+	}
+
+	// Flags and the environment
+	var problems []config.Problem
+	set := func(flag, key string, value *string) {
+		if v := setupValue(cmd, flag, key); v != "" {
+			*value = v
+		}
+	}
+	setPort := func(flag, key string, port *int) {
+		if v := setupValue(cmd, flag, key); v != "" {
+			n, err := parsePort(v)
+			if err != nil {
+				problems = append(problems, config.Problem{Path: key, Message: err.Error()})
+			}
+			*port = n
+		}
+	}
+	set("sonarr-url", "sonarr.url", &sonarrSettings.URL)
+	set("sonarr-host", "sonarr.host", &sonarrSettings.Host)
+	setPort("sonarr-port", "sonarr.port", &sonarrSettings.Port)
+	set("sabnzbd-url", "sabnzbd.url", &sabnzbdSettings.URL)
+	set("sabnzbd-host", "sabnzbd.host", &sabnzbdSettings.Host)
+	setPort("sabnzbd-port", "sabnzbd.port", &sabnzbdSettings.Port)
+	set("sabnzbd-username", "sabnzbd.username", &sabnzbdSettings.Username)
+	if key, _ := cmd.Flags().GetString("sonarr-api-key"); key != "" {
+		sonarrSettings.APIKey = key
+		delete(sonarrSettings.External, "api_key")
+	}
+	if key, _ := cmd.Flags().GetString("sabnzbd-api-key"); key != "" {
+		sabnzbdSettings.APIKey = key
+		delete(sabnzbdSettings.External, "api_key")
+	}
+	if password, _ := cmd.Flags().GetString("sabnzbd-password"); password != "" {
+		sabnzbdSettings.Password = password
+		delete(sabnzbdSettings.External, "password")
+	}
+
+	if len(problems) > 0 {
+		return nil, &config.ValidationError{Problems: problems}
+	}
+	return answers, nil
+}
+
+// setupValue returns the value of a setup flag if it was given, or else
+// that of the environment variable of key
+func setupValue(cmd *cobra.Command, flag, key string) string {
+	if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	return os.Getenv(config.EnvVar(key))
+}
+
+// runSetup checks the answers, tests both connections and saves the
+// configuration only if they work
+func runSetup(ctx context.Context, answers *models.Config) error {
+	var problems []config.Problem
+	for _, problem := range []struct {
+		path string
+		err  error
+	}{
+		{"sonarr.url", checkURL(answers.Sonarr.URL)},
+		{"sonarr.api_key", checkRequired(answers.Sonarr.APIKey)},
+		{"sabnzbd.url", checkURL(answers.Sabnzbd.URL)},
+		{"sabnzbd.api_key", checkRequired(answers.Sabnzbd.APIKey)},
+	} {
+		if problem.err != nil {
+			problems = append(problems, config.Problem{Path: problem.path, Message: problem.err.Error()})
+		}
+	}
+	problems = append(problems, config.Validate(answers, nil)...)
+	if len(problems) > 0 {
+		return &config.ValidationError{Problems: problems}
+	}
+
+	fmt.Print("📺 Testing Sonarr connection... ")
+	if err := testSonarr(ctx, answers); err != nil {
+		fmt.Println("❌ Failed")
+		return fmt.Errorf("failed to connect to Sonarr, configuration not saved: %w", err)
+	}
+	fmt.Println("✅ Success!")
+	fmt.Print("📥 Testing Sabnzbd connection... ")
+	if err := testSabnzbd(ctx, answers); err != nil {
+		fmt.Println("❌ Failed")
+		return fmt.Errorf("failed to connect to Sabnzbd, configuration not saved: %w", err)
+	}
+	fmt.Println("✅ Success!")
+
+	return saveSetup(answers)
+}
+
+// runSetupWizard runs an interactive setup wizard, starting from answers
+func runSetupWizard(ctx context.Context, answers *models.Config) error {
+	fmt.Println("🚀 Sonarr-Sabnzbd CLI Setup Wizard")
+	fmt.Println("===================================")
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+	failed := []string{}
+
+	// Sonarr setup
+	fmt.Println("📺 Sonarr Configuration")
+	fmt.Println("----------------------")
+	for {
+		if err := askSonarr(reader, &answers.Sonarr); err != nil {
+			return err
+		}
+		fmt.Print("📺 Testing Sonarr connection... ")
+		err := testSonarr(ctx, answers)
+		if err == nil {
+			fmt.Println("✅ Success!")
+			break
+		}
+		fmt.Printf("❌ Failed: %v\n", err)
+		if !confirm(reader, "Change the Sonarr settings?", true) {
+			failed = append(failed, "Sonarr")
+			break
+		}
+	}
+
+	// Sabnzbd setup
+	fmt.Println()
+	fmt.Println("📥 Sabnzbd Configuration")
+	fmt.Println("-----------------------")
+	for {
+		if err := askSabnzbd(reader, &answers.Sabnzbd); err != nil {
+			return err
+		}
+		fmt.Print("📥 Testing Sabnzbd connection... ")
+		err := testSabnzbd(ctx, answers)
+		if err == nil {
+			fmt.Println("✅ Success!")
+			break
+		}
+		fmt.Printf("❌ Failed: %v\n", err)
+		if !confirm(reader, "Change the Sabnzbd settings?", true) {
+			failed = append(failed, "Sabnzbd")
+			break
+		}
+	}
+
+	fmt.Println()
+	if len(failed) > 0 && !confirm(reader, fmt.Sprintf("⚠️  The %s connection failed. Save the configuration anyway?", strings.Join(failed, " and ")), false) {
+		fmt.Println("Configuration not saved.")
+		return nil
+	}
+	if err := saveSetup(answers); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("🎉 Setup complete! You can now use the CLI:")
+	fmt.Println("   sonarr-sabnzbd-cli status    # Check service status")
+	fmt.Println("   sonarr search \"Breaking Bad\" --add 1    # Search and add shows")
+	fmt.Println("   sabnzbd queue               # View download queue")
+	return nil
+}
+
+// askSonarr asks for the Sonarr settings, offering the current ones
+func askSonarr(reader *bufio.Reader, settings *models.SonarrConfig) error {
+	var err error
+	if settings.URL, err = prompt(reader, "Sonarr URL, e.g. https://media.example.com/sonarr (leave empty to use host and port)", settings.URL, checkURL); err != nil {
+		return err
+	}
+	if settings.URL == "" {
+		if settings.Host, err = prompt(reader, "Sonarr Host", settings.Host, checkRequired); err != nil {
+			return err
+		}
+		if settings.Port, err = promptPort(reader, "Sonarr Port", settings.Port); err != nil {
+			return err
+		}
+	}
+	key, err := promptSecret(reader, "Sonarr API Key (get from Settings > General > API Key)", settings.APIKey, true)
+	if err != nil {
+		return err
+	}
+	if key != settings.APIKey {
+		settings.APIKey = key
+		delete(settings.External, "api_key")
+	}
+	return nil
+}
+
+// askSabnzbd asks for the Sabnzbd settings, offering the current ones
+func askSabnzbd(reader *bufio.Reader, settings *models.SabnzbdConfig) error {
+	var err error
+	if settings.URL, err = prompt(reader, "Sabnzbd URL, e.g. https://media.example.com/sabnzbd (leave empty to use host and port)", settings.URL, checkURL); err != nil {
+		return err
+	}
+	if settings.URL == "" {
+		if settings.Host, err = prompt(reader, "Sabnzbd Host", settings.Host, checkRequired); err != nil {
+			return err
+		}
+		if settings.Port, err = promptPort(reader, "Sabnzbd Port", settings.Port); err != nil {
+			return err
+		}
+	}
+	key, err := promptSecret(reader, "Sabnzbd API Key (get from Config > General > API Key)", settings.APIKey, true)
+	if err != nil {
+		return err
+	}
+	if key != settings.APIKey {
+		settings.APIKey = key
+		delete(settings.External, "api_key")
+	}
+
+	// Optional authentication for Sabnzbd
+	if settings.Username, err = prompt(reader, "Sabnzbd Username (leave empty if no auth)", settings.Username, nil); err != nil {
+		return err
+	}
+	if settings.Username == "" {
+		settings.Password = ""
+		return nil
+	}
+	password, err := promptSecret(reader, "Sabnzbd Password", settings.Password, false)
+	if err != nil {
+		return err
+	}
+	if password != settings.Password {
+		settings.Password = password
+		delete(settings.External, "password")
+	}
+	return nil
+}
+
+// testSonarr checks that Sonarr answers with the configured settings
+func testSonarr(ctx context.Context, answers *models.Config) error {
+	client, err := sonarr.NewClient(answers.Sonarr, answers.HTTP)
+	if err != nil {
+		return err
+	}
+	_, err = client.GetSystemStatus(ctx)
+	return err
+}
+
+// testSabnzbd checks that Sabnzbd accepts the configured API key
+func testSabnzbd(ctx context.Context, answers *models.Config) error {
+	client, err := sabnzbd.NewClient(answers.Sabnzbd, answers.HTTP)
+	if err != nil {
+		return err
+	}
+	// The version is public, so the queue checks the API key too
+	if _, err := client.GetVersion(ctx); err != nil {
+		return err
+	}
+	_, err = client.GetQueue(ctx)
+	return err
+}
+
+// saveSetup saves the configuration written by setup
+func saveSetup(answers *models.Config) error {
+	fmt.Println()
+	fmt.Print("💾 Saving configuration... ")
+	if err := config.SaveConfig(answers); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Println("✅ Saved!")
+	return nil
+}
+
+// prompt asks for a value until check accepts it; an empty answer keeps
+// def. It fails once stdin is exhausted rather than asking forever.
+func prompt(reader *bufio.Reader, label, def string, check func(string) error) (string, error) {
+	return ask(reader, label, def, def, check)
+}
+
+// promptSecret asks for a secret, showing a kept value masked
+func promptSecret(reader *bufio.Reader, label, def string, required bool) (string, error) {
+	check := checkRequired
+	if !required {
+		check = nil
+	}
+	shown := ""
+	if def != "" {
+		shown = config.Redacted
+	}
+	return ask(reader, label, def, shown, check)
+}
+
+// promptPort asks for a port number until a valid one is given
+func promptPort(reader *bufio.Reader, label string, def int) (int, error) {
+	answer, err := prompt(reader, label, strconv.Itoa(def), func(s string) error {
+		_, err := parsePort(s)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return parsePort(answer)
+}
+
+// ask prints label with the default shown, reads an answer and asks
+// again while check rejects it
+func ask(reader *bufio.Reader, label, def, shown string, check func(string) error) (string, error) {
+	for {
+		switch {
+		case shown != "":
+			fmt.Printf("%s [%s]: ", label, shown)
+		case check == nil:
+			fmt.Printf("%s (optional): ", label)
+		default:
+			fmt.Printf("%s: ", label)
+		}
+
+		input, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && input == "" {
+			fmt.Println()
+			return "", fmt.Errorf("setup aborted: no answer for %s", label)
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			input = def
+		}
+		if check == nil {
+			return input, nil
+		}
+		if err := check(input); err != nil {
+			fmt.Printf("❌ %v. Please try again.\n", err)
+			continue
+		}
+		return input, nil
+	}
+}
+
+// confirm asks a yes or no question; an empty answer takes def
+func confirm(reader *bufio.Reader, question string, def bool) bool {
+	options := "[y/N]"
+	if def {
+		options = "[Y/n]"
+	}
+	fmt.Printf("%s %s: ", question, options)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
+
+// checkRequired rejects an empty answer
+func checkRequired(s string) error {
+	if s == "" {
+		return errors.New("this field is required")
+	}
+	return nil
+}
+
+// checkURL rejects a URL the clients could not use; empty is allowed
+func checkURL(s string) error {
+	if s == "" {
+		return nil
+	}
+	_, err := httpclient.BaseURL(s, "", 0, "")
+	return err
+}
+
+// parsePort parses a TCP port number
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port '%s': expected a number from 1 to 65535", s)
+	}
+	return port, nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/discover"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestSetup(t *testing.T) {
+	restoreConfig(t)
+	env.Reset()
+	sonarr, sabnzbd := env.Sonarr.Config(), env.Sabnzbd.Config()
+
+	input := strings.Join([]string{
+		"", sonarr.Host, strconv.Itoa(sonarr.Port), sonarr.APIKey,
+		"", sabnzbd.Host, strconv.Itoa(sabnzbd.Port), sabnzbd.APIKey,
+		"",
+	}, "\n") + "\n"
+
+	out, err := env.RunWithInput(t, input, "setup", "--no-discover")
+	if err != nil {
+		t.Fatalf("setup: %v\n%s", err, out)
+	}
+	testutil.AssertContains(t, out,
+		"Testing Sonarr connection... ✅ Success!",
+		"Testing Sabnzbd connection... ✅ Success!",
+		"Saving configuration... ✅ Saved!")
+
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, string(data), sonarr.APIKey, sabnzbd.APIKey)
+}
+
+func TestSetupRetry(t *testing.T) {
+	restoreConfig(t)
+	env.Reset()
+	sonarr, sabnzbd := env.Sonarr.Config(), env.Sabnzbd.Config()
+
+	// An invalid URL and port are asked again, and a failed connection
+	// offers to change the settings
+	input := strings.Join([]string{
+		"ftp://sonarr", "", sonarr.Host, "port", strconv.Itoa(sonarr.Port), "wrong-key",
+		"y", "", "", "", sonarr.APIKey,
+		"", sabnzbd.Host, strconv.Itoa(sabnzbd.Port), "wrong-key", "",
+		"n", "n",
+	}, "\n") + "\n"
+
+	out, err := env.RunWithInput(t, input, "setup", "--no-discover")
+	if err != nil {
+		t.Fatalf("setup: %v\n%s", err, out)
+	}
+	testutil.AssertContains(t, out,
+		"scheme must be http or https",
+		"invalid port 'port'",
+		"Change the Sonarr settings? [Y/n]",
+		"Sonarr API Key (get from Settings > General > API Key) [********]",
+		"Testing Sonarr connection... ✅ Success!",
+		"Testing Sabnzbd connection... ❌ Failed",
+		"The Sabnzbd connection failed. Save the configuration anyway? [y/N]",
+		"Configuration not saved.")
+
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "wrong-key") {
+		t.Errorf("setup saved a configuration that failed:\n%s", data)
+	}
+
+	// Running out of answers stops the wizard instead of asking forever
+	_, err = env.RunWithInput(t, "\n", "setup", "--no-discover")
+	if err == nil || !strings.Contains(err.Error(), "setup aborted") {
+		t.Errorf("setup with no answers: err = %v, want setup aborted", err)
+	}
+}
+
+func TestSetupNonInteractive(t *testing.T) {
+	restoreConfig(t)
+	env.Reset()
+	sonarr, sabnzbd := env.Sonarr.Config(), env.Sabnzbd.Config()
+	dir := t.TempDir()
+
+	// Sonarr is discovered and its key read from config.xml; Sabnzbd comes
+	// from the environment and sabnzbd.ini
+	hosts, sonarrPorts := discover.Hosts, discover.SonarrPorts
+	t.Cleanup(func() { discover.Hosts, discover.SonarrPorts = hosts, sonarrPorts })
+	discover.Hosts, discover.SonarrPorts = []string{sonarr.Host}, []int{sonarr.Port}
+
+	configXML := filepath.Join(dir, "config.xml")
+	xml := fmt.Sprintf("<Config>\n  <Port>%d</Port>\n  <ApiKey>%s</ApiKey>\n  <UrlBase></UrlBase>\n</Config>\n", sonarr.Port, sonarr.APIKey)
+	if err := os.WriteFile(configXML, []byte(xml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ini := filepath.Join(dir, "sabnzbd.ini")
+	if err := os.WriteFile(ini, []byte("[misc]\nport = 1\napi_key = wrong-key\n[[servers]]\napi_key = other\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SONCLI_SABNZBD_HOST", sabnzbd.Host)
+	t.Setenv("SONCLI_SABNZBD_PORT", strconv.Itoa(sabnzbd.Port))
+	t.Setenv("SONCLI_SABNZBD_API_KEY", sabnzbd.APIKey)
+
+	// The key from sabnzbd.ini is wrong, so nothing is saved
+	before, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"setup", "--non-interactive", "--sonarr-config-xml", configXML}
+	out, err := env.Run(t, append(args, "--sabnzbd-ini", ini)...)
+	if err == nil || !strings.Contains(err.Error(), "failed to connect to Sabnzbd") {
+		t.Fatalf("setup with a wrong key: err = %v\n%s", err, out)
+	}
+	after, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("setup changed the configuration after a failed connection:\n%s", after)
+	}
+
+	out = env.MustRun(t, args...)
+	testutil.AssertContains(t, out,
+		fmt.Sprintf("Found Sonarr at %s:%d", sonarr.Host, sonarr.Port),
+		"Read the Sonarr API key from "+configXML,
+		"Saving configuration... ✅ Saved!")
+
+	data, err := os.ReadFile(env.ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, string(data), sonarr.APIKey, "port: "+strconv.Itoa(sabnzbd.Port))
+	if strings.Contains(string(data), sabnzbd.APIKey) {
+		t.Errorf("setup saved the API key from the environment:\n%s", data)
+	}
+
+	// Missing answers are configuration errors
+	t.Setenv("SONCLI_SABNZBD_API_KEY", "")
+	_, err = env.Run(t, "setup", "--non-interactive", "--no-discover", "--sonarr-api-key", "key", "--sabnzbd-port", "0")
+	if got := ExitCode(err); got != ExitConfig {
+		t.Errorf("setup with missing answers: ExitCode(%v) = %d, want %d", err, got, ExitConfig)
+	}
+}
@@ -9,9 +9,10 @@ import (
 
 // docsCmd represents the docs command
 var docsCmd = &cobra.Command{
-	Use:   "docs",
-	Short: "Show comprehensive help and usage examples",
-	Long:  `Display detailed help documentation with examples for all commands.`,
+	Use:         "docs",
+	Short:       "Show comprehensive help and usage examples",
+	Long:        `Display detailed help documentation with examples for all commands.`,
+	Annotations: map[string]string{cmd.SkipConfigAnnotation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
 		showComprehensiveHelp()
 	},
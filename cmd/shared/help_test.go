@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"os"
+	"testing"
+
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestDocsWithoutConfig(t *testing.T) {
+	env, err := testutil.StartCLI(cmd.RootCmd())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	if err := os.Remove(env.ConfigPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := env.MustRun(t, "docs")
+	testutil.AssertContains(t, out, "# Sonarr-Sabnzbd CLI Tool", "## Quick Start")
+
+	if _, err := os.Stat(env.ConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("docs created a configuration file: %v", err)
+	}
+}
@@ -15,13 +15,22 @@ var addCmd = &cobra.Command{
 	Short: "Add a TV series to your library by TVDB ID",
 	Long: `Add a TV series to your Sonarr library using its TVDB ID.
 
-You can find TVDB IDs using the 'sonarr search' command.
+You can find TVDB IDs using the 'sonarr search' command. The series is
+added with the first quality profile and root folder unless others are
+given.
 
 Examples:
   sonarr add 81189    # Add Breaking Bad
-  sonarr add 78804    # Add The Office (US)`,
+  sonarr add 78804    # Add The Office (US)
+  sonarr add 81189 --quality-profile HD-1080p --root-folder /tv`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		tvdbIDStr := args[0]
 
 		// Parse TVDB ID
@@ -30,33 +39,21 @@ Examples:
 			return fmt.Errorf("invalid TVDB ID '%s': must be a number", tvdbIDStr)
 		}
 
-		fmt.Printf("Adding series with TVDB ID: %d\n", tvdbID)
+		cmd.Progress(command, "Adding series with TVDB ID: %d", tvdbID)
 
-		// Get quality profiles
-		profiles, err := cmd.GetSonarrClient().GetQualityProfiles()
+		profiles, rootFolders, err := addTargets(ctx, client)
 		if err != nil {
-			return fmt.Errorf("failed to get quality profiles: %w", err)
+			return err
 		}
-
-		// Get root folders
-		rootFolders, err := cmd.GetSonarrClient().GetRootFolders()
+		profileIndex, folderIndex, err := selectTargets(command, profiles, rootFolders)
 		if err != nil {
-			return fmt.Errorf("failed to get root folders: %w", err)
+			return err
 		}
+		qualityProfile := profiles[profileIndex]
+		rootFolder := rootFolders[folderIndex]
 
-		// Use first profile and first root folder
-		if len(profiles) == 0 {
-			return fmt.Errorf("no quality profiles configured in Sonarr")
-		}
-		if len(rootFolders) == 0 {
-			return fmt.Errorf("no root folders configured in Sonarr")
-		}
-
-		qualityProfile := profiles[0]
-		rootFolder := rootFolders[0]
-
-		fmt.Printf("Using quality profile: %s\n", qualityProfile.Name)
-		fmt.Printf("Using root folder: %s\n", rootFolder.Path)
+		cmd.Progress(command, "Using quality profile: %s", qualityProfile.Name)
+		cmd.Progress(command, "Using root folder: %s", rootFolder.Path)
 
 		// Create a minimal series object for adding
 		series := models.Series{
@@ -64,16 +61,16 @@ Examples:
 		}
 
 		// Add the series
-		addedSeries, err := cmd.GetSonarrClient().AddSeries(series, rootFolder, qualityProfile)
+		addedSeries, err := client.AddSeries(ctx, series, rootFolder, qualityProfile)
 		if err != nil {
 			return fmt.Errorf("failed to add series: %w", err)
 		}
 
-		fmt.Printf("✅ Successfully added %s (ID: %d)\n", addedSeries.Title, addedSeries.ID)
-		return nil
+		return cmd.Done(command, addedSeries, "✅ Successfully added %s (ID: %d)", addedSeries.Title, addedSeries.ID)
 	},
 }
 
 func init() {
 	sonarrCmd.AddCommand(addCmd)
+	addTargetFlags(addCmd)
 }
@@ -0,0 +1,67 @@
+package sonarr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestAdd(t *testing.T) {
+	out := mustRun(t, "add", "305288")
+	testutil.AssertContains(t, out,
+		"Adding series with TVDB ID: 305288",
+		"Using quality profile: Any",
+		"Using root folder: /tv",
+		"✅ Successfully added",
+		"(ID: 101)")
+
+	series := env.Sonarr.State().Series
+	if added := series[len(series)-1]; added.TVDBID != 305288 || added.QualityProfileID != 1 {
+		t.Errorf("added series = %+v, want TVDB ID 305288 with profile 1", added)
+	}
+}
+
+func TestAddExisting(t *testing.T) {
+	_, err := run(t, "add", "81189")
+	if !errors.Is(err, apierror.ErrConflict) {
+		t.Errorf("error = %v, want ErrConflict", err)
+	}
+}
+
+func TestAddInvalidID(t *testing.T) {
+	if _, err := run(t, "add", "abc"); err == nil {
+		t.Error("expected an error for a non-numeric TVDB ID")
+	}
+}
+
+func TestAddNoRootFolders(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) { s.RootFolders = nil })
+
+	if _, err := env.Run(t, "sonarr", "add", "305288"); err == nil {
+		t.Error("expected an error without root folders")
+	}
+}
+
+func TestAddWithTargets(t *testing.T) {
+	out := mustRun(t, "add", "305288", "--quality-profile", "hd-1080p", "--root-folder", "/tv/")
+	testutil.AssertContains(t, out, "Using quality profile: HD-1080p", "Using root folder: /tv")
+
+	series := env.Sonarr.State().Series
+	if added := series[len(series)-1]; added.QualityProfileID != 4 {
+		t.Errorf("added series = %+v, want profile 4", added)
+	}
+}
+
+func TestAddUnknownTargets(t *testing.T) {
+	_, err := run(t, "add", "305288", "--quality-profile", "Ultra")
+	if err == nil || !strings.Contains(err.Error(), "available: Any, HD-1080p") {
+		t.Errorf("error = %v, want the available profiles", err)
+	}
+	if _, err := run(t, "add", "305288", "--root-folder", "/movies"); err == nil {
+		t.Error("expected an error for an unknown root folder")
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // episodesCmd represents the episodes command
@@ -18,54 +20,58 @@ The series ID can be found using the 'sonarr series' command.
 
 Examples:
   sonarr episodes 123
+  sonarr episodes 123 -o wide
   sonarr episodes 456 | grep -i "pilot"`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		seriesID, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid series ID: %s", args[0])
 		}
 
 		// Get episodes for the series
-		episodes, err := cmd.GetSonarrClient().GetEpisodes(seriesID)
+		episodes, err := client.GetEpisodes(ctx, seriesID)
 		if err != nil {
 			return fmt.Errorf("failed to get episodes: %w", err)
 		}
 
-		if len(episodes) == 0 {
+		format := cmd.OutputFormat(command)
+		if len(episodes) == 0 && format.Text() {
 			fmt.Printf("No episodes found for series ID %d.\n", seriesID)
 			return nil
 		}
 
-		fmt.Printf("Episodes for Series ID %d (%d episodes):\n\n", seriesID, len(episodes))
-
+		table := output.NewTable("EPISODE", "TITLE", "AIR DATE", "STATUS").
+			Wide("ID", "MONITORED", "OVERVIEW")
 		for _, episode := range episodes {
-			status := "Missing"
-			if episode.HasFile {
-				status = "Downloaded"
-			} else if episode.Monitored {
-				status = "Monitored"
-			} else {
-				status = "Unmonitored"
-			}
-
-			fmt.Printf("S%02dE%02d - %s\n", episode.SeasonNumber, episode.EpisodeNumber, episode.Title)
-			fmt.Printf("   Status: %s | Air Date: %s\n", status, episode.AirDate)
-			if episode.Overview != "" {
-				// Truncate overview if too long
-				overview := episode.Overview
-				if len(overview) > 100 {
-					overview = overview[:97] + "..."
-				}
-				fmt.Printf("   Overview: %s\n", overview)
-			}
-			fmt.Println()
+			table.Row(output.SxE(episode.SeasonNumber, episode.EpisodeNumber),
+				episode.Title, episode.AirDate, episodeStatus(episode),
+				episode.ID, monitoredText(episode.Monitored), episode.Overview)
 		}
-
-		return nil
+		return output.Print(format, episodes, table)
 	},
 }
 
 func init() {
 	sonarrCmd.AddCommand(episodesCmd)
+	episodesCmd.ValidArgsFunction = cmd.FirstArg(completeSeries)
+}
+
+// episodeStatus returns whether an episode is downloaded, monitored or
+// unmonitored
+func episodeStatus(episode models.Episode) string {
+	switch {
+	case episode.HasFile:
+		return output.Green("Downloaded")
+	case episode.Monitored:
+		return output.Yellow("Monitored")
+	default:
+		return output.Faint("Unmonitored")
+	}
 }
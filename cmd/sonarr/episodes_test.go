@@ -0,0 +1,26 @@
+package sonarr
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestEpisodes(t *testing.T) {
+	out := mustRun(t, "episodes", "1")
+	testutil.AssertContains(t, out,
+		"EPISODE   TITLE                 AIR DATE     STATUS",
+		"S01E01    Pilot                 2008-01-20   Downloaded",
+		"S01E02    Cat's in the Bag...   2008-01-27   Monitored")
+}
+
+func TestEpisodesEmpty(t *testing.T) {
+	out := mustRun(t, "episodes", "2")
+	testutil.AssertContains(t, out, "No episodes found for series ID 2.")
+}
+
+func TestEpisodesInvalidID(t *testing.T) {
+	if _, err := run(t, "episodes", "abc"); err == nil {
+		t.Error("expected an error for a non-numeric series ID")
+	}
+}
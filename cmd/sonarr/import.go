@@ -21,17 +21,22 @@ Examples:
   sonarr import "/tmp/episodes"`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		path := args[0]
 
 		// Import downloads
-		err := cmd.GetSonarrClient().ImportDownloads(path)
+		err = client.ImportDownloads(ctx, path)
 		if err != nil {
 			return fmt.Errorf("failed to import downloads: %w", err)
 		}
 
-		fmt.Printf("✅ Successfully initiated import scan for: %s\n", path)
-		fmt.Println("Check Sonarr logs for import progress and results.")
-		return nil
+		return cmd.Done(command, map[string]any{"path": path, "started": true},
+			"✅ Successfully initiated import scan for: %s\nCheck Sonarr logs for import progress and results.", path)
 	},
 }
 
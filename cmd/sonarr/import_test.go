@@ -0,0 +1,17 @@
+package sonarr
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestImport(t *testing.T) {
+	out := mustRun(t, "import", "/downloads/complete/tv")
+	testutil.AssertContains(t, out, "✅ Successfully initiated import scan for: /downloads/complete/tv")
+
+	commands := env.Sonarr.State().Commands
+	if len(commands) != 1 || commands[0]["name"] != "DownloadedEpisodesScan" || commands[0]["path"] != "/downloads/complete/tv" {
+		t.Errorf("commands = %+v, want one DownloadedEpisodesScan", commands)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // infoCmd represents the info command
@@ -14,27 +15,32 @@ var infoCmd = &cobra.Command{
 	Long: `Display system information and status for Sonarr.
 
 Examples:
-  sonarr info`,
+  sonarr info
+  sonarr info -o json`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		// Get system status
-		status, err := cmd.GetSonarrClient().GetSystemStatus()
+		status, err := client.GetSystemStatus(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get system status: %w", err)
 		}
 
-		fmt.Println("Sonarr System Information:")
-		fmt.Println("==========================")
-		fmt.Printf("Version: %s\n", status.Version)
-		fmt.Printf("Build Time: %s\n", status.BuildTime)
-		fmt.Printf("Is Production: %t\n", status.IsProduction)
-		fmt.Printf("Is Admin: %t\n", status.IsAdmin)
-		fmt.Printf("Is User Interactive: %t\n", status.IsUserInteractive)
-		fmt.Printf("Startup Path: %s\n", status.StartupPath)
-		fmt.Printf("App Data: %s\n", status.AppData)
-		fmt.Printf("OS Name: %s\n", status.OsName)
-		fmt.Printf("OS Version: %s\n", status.OsVersion)
-
-		return nil
+		table := output.NewTable("FIELD", "VALUE")
+		table.Row("Version", status.Version)
+		table.Row("Build Time", status.BuildTime)
+		table.Row("Is Production", status.IsProduction)
+		table.Row("Is Admin", status.IsAdmin)
+		table.Row("Is User Interactive", status.IsUserInteractive)
+		table.Row("Startup Path", status.StartupPath)
+		table.Row("App Data", status.AppData)
+		table.Row("OS Name", status.OsName)
+		table.Row("OS Version", status.OsVersion)
+		return output.Print(cmd.OutputFormat(command), status, table)
 	},
 }
 
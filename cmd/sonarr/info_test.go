@@ -0,0 +1,29 @@
+package sonarr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestInfo(t *testing.T) {
+	out := mustRun(t, "info")
+	testutil.AssertContains(t, out,
+		"Version               3.0.10.1567",
+		"OS Name               ubuntu")
+}
+
+func TestInfoServerError(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) {
+		s.Failures = map[string]int{"/api/v3/system/status": http.StatusInternalServerError}
+	})
+
+	_, err := env.Run(t, "sonarr", "info")
+	if !errors.Is(err, apierror.ErrServer) {
+		t.Errorf("error = %v, want ErrServer", err)
+	}
+}
@@ -0,0 +1,43 @@
+package sonarr
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// env is shared by the tests in this package; see testutil.CLIEnv
+var env *testutil.CLIEnv
+
+func TestMain(m *testing.M) {
+	var err error
+	env, err = testutil.StartCLI(cmd.RootCmd())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	env.Close()
+	os.Exit(code)
+}
+
+// run resets the fake servers and executes a sonarr subcommand
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	env.Reset()
+	return env.Run(t, append([]string{"sonarr"}, args...)...)
+}
+
+// mustRun is run that fails the test on error
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := run(t, args...)
+	if err != nil {
+		t.Fatalf("sonarr %v: %v\n%s", args, err, out)
+	}
+	return out
+}
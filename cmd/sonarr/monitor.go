@@ -9,7 +9,8 @@ import (
 )
 
 var (
-	monitorState bool
+	monitorEnable  bool
+	monitorDisable bool
 )
 
 // monitorCmd represents the monitor command
@@ -26,39 +27,46 @@ Examples:
   sonarr monitor 123 --disable   # Stop monitoring series 123`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		seriesID, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid series ID: %s", args[0])
 		}
 
 		// Get the current series
-		series, err := cmd.GetSonarrClient().GetSeriesByID(seriesID)
+		series, err := client.GetSeriesByID(ctx, seriesID)
 		if err != nil {
 			return fmt.Errorf("failed to get series: %w", err)
 		}
 
-		// Update monitoring state
-		series.Monitored = monitorState
+		// Update monitoring state; monitoring is enabled unless --disable is given
+		monitored := !monitorDisable
+		series.Monitored = monitored
 
 		// Update the series
-		updatedSeries, err := cmd.GetSonarrClient().UpdateSeries(*series)
+		updatedSeries, err := client.UpdateSeries(ctx, *series)
 		if err != nil {
 			return fmt.Errorf("failed to update series: %w", err)
 		}
 
 		action := "disabled"
-		if monitorState {
+		if monitored {
 			action = "enabled"
 		}
 
-		fmt.Printf("✅ Successfully %s monitoring for '%s'\n", action, updatedSeries.Title)
-		return nil
+		return cmd.Done(command, updatedSeries, "✅ Successfully %s monitoring for '%s'", action, updatedSeries.Title)
 	},
 }
 
 func init() {
 	sonarrCmd.AddCommand(monitorCmd)
-	monitorCmd.Flags().BoolVar(&monitorState, "enable", false, "Enable monitoring for the series")
-	monitorCmd.Flags().BoolVar(&monitorState, "disable", true, "Disable monitoring for the series")
+	monitorCmd.ValidArgsFunction = cmd.FirstArg(completeSeries)
+	monitorCmd.Flags().BoolVar(&monitorEnable, "enable", false, "Enable monitoring for the series")
+	monitorCmd.Flags().BoolVar(&monitorDisable, "disable", false, "Disable monitoring for the series")
 	monitorCmd.MarkFlagsMutuallyExclusive("enable", "disable")
 }
@@ -0,0 +1,37 @@
+package sonarr
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestMonitorEnable(t *testing.T) {
+	out := mustRun(t, "monitor", "2", "--enable")
+	testutil.AssertContains(t, out, "✅ Successfully enabled monitoring for 'The Office (US)'")
+
+	if !env.Sonarr.State().Series[1].Monitored {
+		t.Error("series 2 is not monitored")
+	}
+}
+
+func TestMonitorDisable(t *testing.T) {
+	out := mustRun(t, "monitor", "1", "--disable")
+	testutil.AssertContains(t, out, "✅ Successfully disabled monitoring for 'Breaking Bad'")
+
+	if env.Sonarr.State().Series[0].Monitored {
+		t.Error("series 1 is still monitored")
+	}
+}
+
+func TestMonitorFlagsExclusive(t *testing.T) {
+	if _, err := run(t, "monitor", "1", "--enable", "--disable"); err == nil {
+		t.Error("expected an error for --enable with --disable")
+	}
+}
+
+func TestMonitorNotFound(t *testing.T) {
+	if _, err := run(t, "monitor", "999", "--enable"); err == nil {
+		t.Error("expected an error for an unknown series")
+	}
+}
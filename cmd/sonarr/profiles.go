@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // profilesCmd represents the profiles command
@@ -14,28 +15,36 @@ var profilesCmd = &cobra.Command{
 	Long: `Display all available quality profiles configured in Sonarr.
 
 Quality profiles determine the quality and format preferences for downloads.
+On Sonarr v4 the custom format scores of each profile are shown as well.
 
 Examples:
   sonarr profiles`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		// Get quality profiles
-		profiles, err := cmd.GetSonarrClient().GetQualityProfiles()
+		profiles, err := client.GetQualityProfiles(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get quality profiles: %w", err)
 		}
 
-		if len(profiles) == 0 {
+		format := cmd.OutputFormat(command)
+		if len(profiles) == 0 && format.Text() {
 			fmt.Println("No quality profiles found.")
 			return nil
 		}
 
-		fmt.Printf("Quality Profiles (%d):\n\n", len(profiles))
-
-		for i, profile := range profiles {
-			fmt.Printf("%d. %s (ID: %d)\n", i+1, profile.Name, profile.ID)
+		table := output.NewTable("ID", "NAME", "CUSTOM FORMATS", "MIN SCORE", "CUTOFF SCORE").
+			Wide("UPGRADE ALLOWED")
+		for _, profile := range profiles {
+			table.Row(profile.ID, profile.Name, len(profile.FormatItems),
+				profile.MinFormatScore, profile.CutoffFormatScore, profile.UpgradeAllowed)
 		}
-
-		return nil
+		return output.Print(format, profiles, table)
 	},
 }
 
@@ -0,0 +1,43 @@
+package sonarr
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestProfiles(t *testing.T) {
+	out := mustRun(t, "profiles")
+	testutil.AssertContains(t, out,
+		"ID   NAME       CUSTOM FORMATS   MIN SCORE   CUTOFF SCORE",
+		"1    Any        0",
+		"4    HD-1080p   0")
+}
+
+func TestProfilesEmpty(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) { s.Profiles = nil })
+
+	out, err := env.Run(t, "sonarr", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "No quality profiles found.")
+}
+
+func TestProfilesCustomFormats(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) {
+		s.Status.Version = "4.0.5.1710"
+		s.Profiles[1].MinFormatScore = 10
+		s.Profiles[1].CutoffFormatScore = 100
+		s.Profiles[1].FormatItems = []models.ProfileFormatItem{{Format: 1, Name: "x265", Score: -100}}
+	})
+
+	out, err := env.Run(t, "sonarr", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "4    HD-1080p   1                10          100")
+}
@@ -5,22 +5,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
-// formatBytes formats bytes into human readable format
-func formatBytes(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
 // rootfoldersCmd represents the root-folders command
 var rootfoldersCmd = &cobra.Command{
 	Use:   "root-folders",
@@ -32,26 +19,29 @@ Root folders are the base directories where Sonarr stores TV series.
 Examples:
   sonarr root-folders`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		// Get root folders
-		folders, err := cmd.GetSonarrClient().GetRootFolders()
+		folders, err := client.GetRootFolders(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get root folders: %w", err)
 		}
 
-		if len(folders) == 0 {
+		format := cmd.OutputFormat(command)
+		if len(folders) == 0 && format.Text() {
 			fmt.Println("No root folders configured.")
 			return nil
 		}
 
-		fmt.Printf("Root Folders (%d):\n\n", len(folders))
-
-		for i, folder := range folders {
-			fmt.Printf("%d. %s\n", i+1, folder.Path)
-			fmt.Printf("   Free Space: %s\n", formatBytes(folder.FreeSpace))
-			fmt.Println()
+		table := output.NewTable("ID", "PATH", "FREE SPACE").Wide("UNMAPPED FOLDERS")
+		for _, folder := range folders {
+			table.Row(folder.ID, folder.Path, output.FormatBytes(folder.FreeSpace), len(folder.UnmappedFolders))
 		}
-
-		return nil
+		return output.Print(format, folders, table)
 	},
 }
 
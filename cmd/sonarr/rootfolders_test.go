@@ -0,0 +1,25 @@
+package sonarr
+
+import (
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestRootFolders(t *testing.T) {
+	out := mustRun(t, "root-folders")
+	testutil.AssertContains(t, out,
+		"ID   PATH   FREE SPACE",
+		"1    /tv    500.0 GB")
+}
+
+func TestRootFoldersEmpty(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) { s.RootFolders = nil })
+
+	out, err := env.Run(t, "sonarr", "root-folders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "No root folders configured.")
+}
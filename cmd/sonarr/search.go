@@ -1,14 +1,19 @@
 package sonarr
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/api/sonarr"
 	"sonarr-sabnzbd-cli/internal/ascii"
 	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+	"sonarr-sabnzbd-cli/internal/tui"
 )
 
 // searchCmd represents the search command
@@ -17,63 +22,57 @@ var searchCmd = &cobra.Command{
 	Short: "Search for TV series to add to your library",
 	Long: `Search TheTVDB for TV series and display results. Use --add <number> to add a specific result.
 
+On a terminal the results are shown in a list to pick from, with the
+overview and poster of the selected series beside it, followed by the
+quality profile, root folder and episodes to monitor. Enter chooses, esc
+goes back a step. Piped output and --non-interactive list the results
+instead.
+
 Examples:
-  sonarr search "Breaking Bad"              # Display search results
+  sonarr search "Breaking Bad"              # Pick a result to add, or display results when piped
   sonarr search "Breaking Bad" --add 1      # Add first result
   sonarr search "Breaking Bad" --add 3      # Add third result
-  sonarr search "The Office" --json         # Output in JSON format
+  sonarr search "Breaking Bad" --add 1 --quality-profile HD-1080p
+  sonarr search "The Office" -o json        # Output in JSON format
   sonarr search "Stranger Things" --ascii   # Display with ASCII art posters`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
+		client, err := cmd.GetSonarrClient()
+		if err != nil {
+			return err
+		}
+
 		query := args[0]
 		addIndex, _ := command.Flags().GetInt("add")
-		jsonOutput, _ := command.Flags().GetBool("json")
 		asciiOutput, _ := command.Flags().GetBool("ascii")
 
 		// Search for series
-		results, err := cmd.GetSonarrClient().LookupSeries(query)
+		results, err := client.LookupSeries(ctx, query)
 		if err != nil {
 			return fmt.Errorf("failed to search series: %w", err)
 		}
 
-		if len(results) == 0 {
-			if jsonOutput {
-				fmt.Println("[]")
-			} else {
-				fmt.Println("No series found matching your query.")
-			}
-			return nil
+		// Scripts get either the results or the series added
+		format := cmd.OutputFormat(command)
+		if !format.Text() && addIndex == 0 {
+			return output.Print(format, results, searchTable(results))
 		}
 
-		// JSON output mode
-		if jsonOutput {
-			return outputJSON(results)
+		nonInteractive, _ := command.Flags().GetBool("non-interactive")
+		if format == output.FormatTable && addIndex == 0 && !nonInteractive && len(results) > 0 && cmd.Interactive() {
+			return addInteractively(command, client, results)
 		}
 
-		// Display results
-		fmt.Printf("Found %d series:\n\n", len(results))
-
-		asciiConfig := ascii.DefaultConfig()
-		// Keep default 8x8 size for compact display
-
-		for i, series := range results {
-			status := "✓"
-			if series.Status != "Continuing" {
-				status = "○"
+		if format.Text() {
+			if len(results) == 0 {
+				fmt.Println("No series found matching your query.")
+				return nil
 			}
-
-			fmt.Printf("%d. %s %s (%d) - %s\n",
-				i+1, status, series.Title, series.Year, series.Status)
-
-			// Add ASCII art if requested
-			if asciiOutput {
-				asciiArt, err := ascii.GetSeriesPosterASCII(series, asciiConfig)
-				if err != nil {
-					fmt.Printf("   [Could not load poster: %v]\n", err)
-				} else {
-					fmt.Printf("   %s\n", asciiArt)
-				}
-				fmt.Println()
+			if asciiOutput && format == output.FormatTable {
+				printSearchPosters(results)
+			} else if err := output.Print(format, results, searchTable(results)); err != nil {
+				return err
 			}
 		}
 
@@ -82,7 +81,10 @@ Examples:
 			if addIndex > len(results) {
 				return fmt.Errorf("invalid series number %d (only %d results found)", addIndex, len(results))
 			}
-			return addSeries(results[addIndex-1]) // Convert to 0-based index
+			if format.Text() {
+				fmt.Println()
+			}
+			return addSeries(command, results[addIndex-1]) // Convert to 0-based index
 		}
 
 		fmt.Printf("\nUse --add <number> to add a specific series, or run:\n")
@@ -95,48 +97,274 @@ Examples:
 func init() {
 	sonarrCmd.AddCommand(searchCmd)
 	searchCmd.Flags().Int("add", 0, "Add the series at the specified number (1-based)")
-	searchCmd.Flags().Bool("json", false, "Output results in JSON format")
 	searchCmd.Flags().Bool("ascii", false, "Display ASCII art posters for search results")
+	searchCmd.Flags().Bool("non-interactive", false, "List the results on a terminal too, instead of picking one to add")
+	addTargetFlags(searchCmd)
 }
 
-// outputJSON outputs results in JSON format
-func outputJSON(results []models.Series) error {
-	return json.NewEncoder(os.Stdout).Encode(results)
+// searchTable lists search results with the numbers used by --add
+func searchTable(results []models.Series) *output.Table {
+	table := output.NewTable("#", "TITLE", "YEAR", "STATUS", "TVDB ID").
+		Wide("NETWORK", "SEASONS", "ADDED")
+	for i, series := range results {
+		added := output.Faint("no")
+		if series.ID > 0 {
+			added = output.Green("yes")
+		}
+		table.Row(i+1, series.Title, series.Year, series.Status, series.TVDBID,
+			series.Network, len(series.Seasons), added)
+	}
+	return table
 }
 
-// addSeries adds a series to Sonarr
-func addSeries(series models.Series) error {
-	fmt.Printf("Adding series: %s (%d)\n", series.Title, series.Year)
+// printSearchPosters lists search results with an ASCII art poster under
+// each
+func printSearchPosters(results []models.Series) {
+	fmt.Printf("Found %d series:\n\n", len(results))
+
+	asciiConfig := ascii.DefaultConfig()
+	asciiConfig.Colored = output.Colors()
+	// Keep default 8x8 size for compact display
+
+	for i, series := range results {
+		status := "✓"
+		if series.Status != "Continuing" {
+			status = "○"
+		}
+		fmt.Printf("%d. %s %s (%d) - %s\n",
+			i+1, status, series.Title, series.Year, series.Status)
+
+		asciiArt, err := ascii.GetSeriesPosterASCII(series, asciiConfig)
+		if err != nil {
+			fmt.Printf("   [Could not load poster: %v]\n", err)
+		} else {
+			fmt.Printf("   %s\n", asciiArt)
+		}
+		fmt.Println()
+	}
+}
 
-	// Get quality profiles
-	profiles, err := cmd.GetSonarrClient().GetQualityProfiles()
+// addSeries adds a series to Sonarr with the quality profile and root
+// folder given by flags, or else the first ones
+func addSeries(command *cobra.Command, series models.Series) error {
+	ctx := command.Context()
+	client, err := cmd.GetSonarrClient()
 	if err != nil {
-		return fmt.Errorf("failed to get quality profiles: %w", err)
+		return err
 	}
 
-	// Get root folders
-	rootFolders, err := cmd.GetSonarrClient().GetRootFolders()
+	cmd.Progress(command, "Adding series: %s (%d)", series.Title, series.Year)
+
+	profiles, rootFolders, err := addTargets(ctx, client)
+	if err != nil {
+		return err
+	}
+	profileIndex, folderIndex, err := selectTargets(command, profiles, rootFolders)
 	if err != nil {
-		return fmt.Errorf("failed to get root folders: %w", err)
+		return err
 	}
+	qualityProfile := profiles[profileIndex]
+	rootFolder := rootFolders[folderIndex]
+
+	cmd.Progress(command, "Using quality profile: %s", qualityProfile.Name)
+	cmd.Progress(command, "Using root folder: %s", rootFolder.Path)
+
+	return addSeriesWith(command, client, series, rootFolder, qualityProfile, sonarr.MonitorModes[0].Value)
+}
 
-	// Use first profile and first root folder
+// addTargets returns the quality profiles and root folders a series can
+// be added with, failing if either is missing
+func addTargets(ctx context.Context, client *sonarr.Client) ([]models.QualityProfile, []models.RootFolder, error) {
+	profiles, err := client.GetQualityProfiles(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get quality profiles: %w", err)
+	}
+	rootFolders, err := client.GetRootFolders(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get root folders: %w", err)
+	}
 	if len(profiles) == 0 || len(rootFolders) == 0 {
-		return fmt.Errorf("no quality profiles or root folders configured in Sonarr")
+		return nil, nil, fmt.Errorf("no quality profiles or root folders configured in Sonarr")
 	}
+	return profiles, rootFolders, nil
+}
 
-	qualityProfile := profiles[0]
-	rootFolder := rootFolders[0]
-
-	fmt.Printf("Using quality profile: %s\n", qualityProfile.Name)
-	fmt.Printf("Using root folder: %s\n", rootFolder.Path)
+// selectTargets returns the quality profile and root folder chosen with
+// --quality-profile and --root-folder, defaulting to the first ones. A
+// profile is matched by name, ignoring case, or by ID.
+func selectTargets(command *cobra.Command, profiles []models.QualityProfile, rootFolders []models.RootFolder) (profileIndex, folderIndex int, err error) {
+	if name, _ := command.Flags().GetString("quality-profile"); name != "" {
+		profileIndex = -1
+		names := make([]string, len(profiles))
+		for i, profile := range profiles {
+			names[i] = profile.Name
+			if strings.EqualFold(profile.Name, name) || strconv.Itoa(profile.ID) == name {
+				profileIndex = i
+			}
+		}
+		if profileIndex < 0 {
+			return 0, 0, fmt.Errorf("quality profile '%s' not found; available: %s", name, strings.Join(names, ", "))
+		}
+	}
+	if path, _ := command.Flags().GetString("root-folder"); path != "" {
+		folderIndex = -1
+		paths := make([]string, len(rootFolders))
+		for i, folder := range rootFolders {
+			paths[i] = folder.Path
+			if strings.TrimRight(folder.Path, "/") == strings.TrimRight(path, "/") {
+				folderIndex = i
+			}
+		}
+		if folderIndex < 0 {
+			return 0, 0, fmt.Errorf("root folder '%s' not found; available: %s", path, strings.Join(paths, ", "))
+		}
+	}
+	return profileIndex, folderIndex, nil
+}
 
-	// Add the series
-	addedSeries, err := cmd.GetSonarrClient().AddSeries(series, rootFolder, qualityProfile)
+// addSeriesWith adds a series with the given settings
+func addSeriesWith(command *cobra.Command, client *sonarr.Client, series models.Series, rootFolder models.RootFolder, qualityProfile models.QualityProfile, monitor string) error {
+	addedSeries, err := client.AddSeriesMonitoring(command.Context(), series, rootFolder, qualityProfile, monitor)
 	if err != nil {
 		return fmt.Errorf("failed to add series: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully added %s (ID: %d)\n", addedSeries.Title, addedSeries.ID)
-	return nil
+	return cmd.Done(command, addedSeries, "✅ Successfully added %s (ID: %d)", addedSeries.Title, addedSeries.ID)
+}
+
+// addInteractively lets the user pick a search result and how to add it
+// in full-screen lists, then adds it
+func addInteractively(command *cobra.Command, client *sonarr.Client, results []models.Series) error {
+	ctx := command.Context()
+	profiles, rootFolders, err := addTargets(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	profileIndex, folderIndex, err := selectTargets(command, profiles, rootFolders)
+	if err != nil {
+		return err
+	}
+
+	var seriesIndex, monitorIndex int
+	steps := []addStep{{seriesPicker(results), &seriesIndex}}
+	// A single profile or folder, or one given by flag, leaves nothing to
+	// choose
+	if len(profiles) > 1 && !command.Flags().Changed("quality-profile") {
+		steps = append(steps, addStep{profilePicker(profiles), &profileIndex})
+	}
+	if len(rootFolders) > 1 && !command.Flags().Changed("root-folder") {
+		steps = append(steps, addStep{folderPicker(rootFolders), &folderIndex})
+	}
+	steps = append(steps, addStep{monitorPicker(), &monitorIndex})
+
+	screen, err := tui.OpenScreen(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	// Esc goes back a step, and out of the first one
+	step := 0
+	for step >= 0 && step < len(steps) {
+		chosen, err := screen.Pick(ctx, steps[step].picker)
+		if err != nil {
+			screen.Close()
+			return err
+		}
+		if chosen < 0 {
+			step--
+			continue
+		}
+		*steps[step].chosen = chosen
+		step++
+	}
+	screen.Close()
+	if step < 0 {
+		fmt.Println("No series added.")
+		return nil
+	}
+
+	return addSeriesWith(command, client, results[seriesIndex], rootFolders[folderIndex],
+		profiles[profileIndex], sonarr.MonitorModes[monitorIndex].Value)
+}
+
+// addStep is a choice made when adding a series interactively
+type addStep struct {
+	picker *tui.Picker
+	chosen *int
+}
+
+// seriesPicker lists search results with their details and poster
+func seriesPicker(results []models.Series) *tui.Picker {
+	items := make([]string, len(results))
+	for i, series := range results {
+		items[i] = fmt.Sprintf("%s (%d)", series.Title, series.Year)
+		if series.ID > 0 {
+			items[i] += " ✓"
+		}
+	}
+	return &tui.Picker{
+		Title: fmt.Sprintf("Add a series: %d results", len(results)),
+		Items: items,
+		Preview: func(i int) []string {
+			return seriesPreview(results[i])
+		},
+		Poster: func(i, width, height int) (string, error) {
+			return cmd.Poster(results[i], width, height)
+		},
+	}
+}
+
+// seriesPreview describes a search result
+func seriesPreview(series models.Series) []string {
+	lines := []string{output.Bold(fmt.Sprintf("%s (%d)", series.Title, series.Year))}
+	var facts []string
+	for _, fact := range []string{series.Network, series.Status} {
+		if fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	if len(series.Seasons) > 0 {
+		facts = append(facts, fmt.Sprintf("%d seasons", len(series.Seasons)))
+	}
+	if series.Runtime > 0 {
+		facts = append(facts, fmt.Sprintf("%d min", series.Runtime))
+	}
+	lines = append(lines, strings.Join(facts, " · "))
+	if len(series.Genres) > 0 {
+		lines = append(lines, strings.Join(series.Genres, ", "))
+	}
+	if series.ID > 0 {
+		lines = append(lines, output.Green("Already in your library"))
+	}
+	if series.Overview != "" {
+		lines = append(lines, "", series.Overview)
+	}
+	return lines
+}
+
+// profilePicker lists the quality profiles
+func profilePicker(profiles []models.QualityProfile) *tui.Picker {
+	items := make([]string, len(profiles))
+	for i, profile := range profiles {
+		items[i] = profile.Name
+	}
+	return &tui.Picker{Title: "Quality profile", Items: items}
+}
+
+// folderPicker lists the root folders with their free space
+func folderPicker(rootFolders []models.RootFolder) *tui.Picker {
+	items := make([]string, len(rootFolders))
+	for i, folder := range rootFolders {
+		items[i] = fmt.Sprintf("%s (%s free)", folder.Path, output.FormatBytes(folder.FreeSpace))
+	}
+	return &tui.Picker{Title: "Root folder", Items: items}
+}
+
+// monitorPicker lists the episodes a new series can monitor
+func monitorPicker() *tui.Picker {
+	items := make([]string, len(sonarr.MonitorModes))
+	for i, mode := range sonarr.MonitorModes {
+		items[i] = mode.Description
+	}
+	return &tui.Picker{Title: "Episodes to monitor", Items: items}
 }
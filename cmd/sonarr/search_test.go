@@ -0,0 +1,65 @@
+package sonarr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestSearch(t *testing.T) {
+	out := mustRun(t, "search", "stranger")
+	testutil.AssertContains(t, out,
+		"#   TITLE                    YEAR   STATUS       TVDB ID",
+		"1   Stranger Things          2016   continuing   305288",
+		"2   Beyond Stranger Things   2017   ended        332302",
+		"sonarr add 305288")
+
+	// Tests don't run on a terminal, so this only checks the flag exists
+	out = mustRun(t, "search", "stranger", "--non-interactive")
+	testutil.AssertContains(t, out, "1   Stranger Things          2016   continuing   305288")
+}
+
+func TestSearchJSON(t *testing.T) {
+	out := mustRun(t, "search", "stranger", "-o", "json")
+
+	var results []models.Series
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+}
+
+func TestSearchNoResults(t *testing.T) {
+	out := mustRun(t, "search", "nothing")
+	testutil.AssertContains(t, out, "No series found matching your query.")
+
+	out = mustRun(t, "search", "nothing", "-o", "json")
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("JSON output = %q, want []", out)
+	}
+}
+
+func TestSearchAdd(t *testing.T) {
+	out := mustRun(t, "search", "stranger", "--add", "1")
+	testutil.AssertContains(t, out,
+		"Adding series: Stranger Things (2016)",
+		"✅ Successfully added Stranger Things (ID: 101)")
+
+	if _, err := run(t, "search", "stranger", "--add", "5"); err == nil {
+		t.Error("expected an error for an out of range result number")
+	}
+}
+
+func TestSearchAddWithProfile(t *testing.T) {
+	mustRun(t, "search", "stranger", "--add", "1", "--quality-profile", "4")
+
+	series := env.Sonarr.State().Series
+	if added := series[len(series)-1]; added.QualityProfileID != 4 {
+		t.Errorf("added series = %+v, want profile 4", added)
+	}
+}
@@ -1,13 +1,13 @@
 package sonarr
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
 	"sonarr-sabnzbd-cli/internal/ascii"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // seriesCmd represents the series command
@@ -18,63 +18,123 @@ var seriesCmd = &cobra.Command{
 
 Examples:
    sonarr series                    # List all series
+   sonarr series -o wide            # Add network, seasons, size and path
+   sonarr series -o yaml            # Output in YAML format
+   sonarr series -o template='{{range .}}{{.Title}}{{"\n"}}{{end}}'  # One title per line
    sonarr series --ascii            # List with ASCII art posters
+   sonarr series --all-instances    # List series from every instance
    sonarr series | grep "Breaking"  # Filter for specific series`,
 	RunE: func(command *cobra.Command, args []string) error {
+		ctx := command.Context()
 		asciiOutput, _ := command.Flags().GetBool("ascii")
-		jsonOutput, _ := command.Flags().GetBool("json")
+		allInstances := cmd.AllInstances(command)
 
-		// Get all series from Sonarr
-		series, err := cmd.GetSonarrClient().GetSeries()
+		// Get all series from every instance queried
+		results, err := cmd.FanOut(cmd.SonarrInstances(command), func(instance string) ([]models.Series, error) {
+			client, err := cmd.GetSonarrClientFor(instance)
+			if err != nil {
+				return nil, err
+			}
+			series, err := client.GetSeries(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get series: %w", err)
+			}
+			return series, nil
+		})
 		if err != nil {
-			return fmt.Errorf("failed to get series: %w", err)
+			return err
 		}
 
-		if len(series) == 0 {
-			if jsonOutput {
-				fmt.Println("[]")
-			} else {
-				fmt.Println("No series found in your library.")
+		var series []instanceSeries
+		for _, result := range results {
+			for _, s := range result.Value {
+				series = append(series, instanceSeries{Instance: result.Instance, Series: s})
 			}
-			return nil
 		}
 
-		// JSON output mode
-		if jsonOutput {
-			return json.NewEncoder(os.Stdout).Encode(series)
+		format := cmd.OutputFormat(command)
+		if len(series) == 0 && format.Text() {
+			fmt.Println("No series found in your library.")
+			return nil
 		}
 
-		fmt.Printf("Your Library (%d series):\n\n", len(series))
-
-		asciiConfig := ascii.DefaultConfig()
-		asciiConfig.Width = 30 // Smaller width for series list
-
-		for i, s := range series {
-			status := "✓"
-			if !s.Monitored {
-				status = "○"
-			}
-			fmt.Printf("%d. %s %s (%d) - %s\n",
-				i+1, status, s.Title, s.Year, s.Status)
+		if asciiOutput && format == output.FormatTable {
+			printSeriesPosters(series, allInstances)
+			return nil
+		}
 
-			// Add ASCII art if requested
-			if asciiOutput {
-				asciiArt, err := ascii.GetSeriesPosterASCII(s, asciiConfig)
-				if err != nil {
-					fmt.Printf("   [Could not load poster: %v]\n", err)
-				} else {
-					fmt.Printf("   %s\n", asciiArt)
-				}
-				fmt.Println()
+		table := output.NewTable("ID", "TITLE", "YEAR", "STATUS", "MONITORED").
+			Wide("NETWORK", "SEASONS", "EPISODES", "SIZE", "PATH")
+		if allInstances {
+			table = output.NewTable("INSTANCE", "ID", "TITLE", "YEAR", "STATUS", "MONITORED").
+				Wide("NETWORK", "SEASONS", "EPISODES", "SIZE", "PATH")
+		}
+		for _, s := range series {
+			row := []any{s.ID, s.Title, s.Year, s.Status, monitoredText(s.Monitored),
+				s.Network, s.Statistics.SeasonCount,
+				fmt.Sprintf("%d/%d", s.Statistics.EpisodeFileCount, s.Statistics.EpisodeCount),
+				output.FormatBytes(s.Statistics.SizeOnDisk), s.Path}
+			if allInstances {
+				row = append([]any{s.Instance}, row...)
 			}
+			table.Row(row...)
 		}
 
-		return nil
+		// Series are tagged with their instance when several are queried
+		if allInstances {
+			return output.Print(format, series, table)
+		}
+		return output.Print(format, results[0].Value, table)
 	},
 }
 
 func init() {
 	sonarrCmd.AddCommand(seriesCmd)
 	seriesCmd.Flags().Bool("ascii", false, "Display ASCII art posters for series")
-	seriesCmd.Flags().Bool("json", false, "Output results in JSON format")
+	seriesCmd.Flags().Bool("all-instances", false, "List series from every instance")
+}
+
+// instanceSeries is a series tagged with the instance it belongs to
+type instanceSeries struct {
+	Instance string `json:"instance"`
+	models.Series
+}
+
+// monitoredText shows whether a series or episode is monitored
+func monitoredText(monitored bool) string {
+	if monitored {
+		return output.Green("yes")
+	}
+	return output.Faint("no")
+}
+
+// printSeriesPosters lists series with an ASCII art poster under each
+func printSeriesPosters(series []instanceSeries, allInstances bool) {
+	fmt.Printf("Your Library (%d series):\n\n", len(series))
+
+	asciiConfig := ascii.DefaultConfig()
+	asciiConfig.Width = 30 // Smaller width for series list
+	asciiConfig.Colored = output.Colors()
+
+	for i, s := range series {
+		status := "✓"
+		if !s.Monitored {
+			status = "○"
+		}
+		if allInstances {
+			fmt.Printf("%d. [%s] %s %s (%d) - %s\n",
+				i+1, s.Instance, status, s.Title, s.Year, s.Status)
+		} else {
+			fmt.Printf("%d. %s %s (%d) - %s\n",
+				i+1, status, s.Title, s.Year, s.Status)
+		}
+
+		asciiArt, err := ascii.GetSeriesPosterASCII(s.Series, asciiConfig)
+		if err != nil {
+			fmt.Printf("   [Could not load poster: %v]\n", err)
+		} else {
+			fmt.Printf("   %s\n", asciiArt)
+		}
+		fmt.Println()
+	}
 }
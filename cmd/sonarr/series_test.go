@@ -0,0 +1,85 @@
+package sonarr
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestSeries(t *testing.T) {
+	out := mustRun(t, "series")
+	testutil.AssertContains(t, out,
+		"ID   TITLE             YEAR   STATUS   MONITORED",
+		"1    Breaking Bad      2008   ended    yes",
+		"2    The Office (US)   2005   ended    no")
+}
+
+func TestSeriesJSON(t *testing.T) {
+	out := mustRun(t, "series", "-o", "json")
+
+	var series []models.Series
+	if err := json.Unmarshal([]byte(out), &series); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(series) != 2 || series[0].TVDBID != 81189 {
+		t.Errorf("series = %+v, want Breaking Bad first", series)
+	}
+}
+
+func TestSeriesTemplate(t *testing.T) {
+	out := mustRun(t, "series", "-o", `template={{range .}}{{.Title}}: {{humanBytes .Statistics.SizeOnDisk}}{{"\n"}}{{end}}`)
+	testutil.AssertContains(t, out, "Breaking Bad: 150.0 GB\nThe Office (US): 80.0 GB\n")
+
+	if _, err := env.Run(t, "sonarr", "series", "-o", "template={{.Nope"); err == nil {
+		t.Error("expected an error for a broken template")
+	}
+}
+
+func TestSeriesEmpty(t *testing.T) {
+	env.Reset()
+	env.Sonarr.Update(func(s *testutil.SonarrState) { s.Series = nil })
+
+	out, err := env.Run(t, "sonarr", "series")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.AssertContains(t, out, "No series found in your library.")
+}
+
+func TestSeriesAllInstances(t *testing.T) {
+	t.Cleanup(func() { env.WriteConfig("") })
+	fourK := testutil.NewFakeSonarr(t)
+	fourK.Update(func(s *testutil.SonarrState) {
+		s.Series = s.Series[:1]
+		s.Series[0].Title = "Planet Earth"
+		s.Series[0].Year = 2006
+	})
+	config := fourK.Config()
+	if err := env.WriteConfig(fmt.Sprintf("instances:\n  4k:\n    sonarr:\n      port: %d\n      api_key: %s\n", config.Port, config.APIKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, "series", "--all-instances")
+	testutil.AssertContains(t, out,
+		"INSTANCE   ID   TITLE             YEAR   STATUS   MONITORED",
+		"default    1    Breaking Bad      2008   ended    yes",
+		"4k         1    Planet Earth      2006   ended    yes")
+
+	out = mustRun(t, "series", "--instance", "4k")
+	testutil.AssertContains(t, out, "1    Planet Earth   2006   ended    yes")
+
+	out = mustRun(t, "series", "--all-instances", "-o", "json")
+	var series []struct {
+		Instance string `json:"instance"`
+		Title    string `json:"title"`
+	}
+	if err := json.Unmarshal([]byte(out), &series); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(series) != 3 || series[2].Instance != "4k" || series[2].Title != "Planet Earth" {
+		t.Errorf("series = %+v, want Planet Earth from 4k last", series)
+	}
+}
@@ -1,8 +1,14 @@
 package sonarr
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+
 	"github.com/spf13/cobra"
 	"sonarr-sabnzbd-cli/cmd"
+	"sonarr-sabnzbd-cli/internal/api/sonarr"
+	"sonarr-sabnzbd-cli/internal/output"
 )
 
 // sonarrCmd represents the sonarr command
@@ -15,3 +21,51 @@ var sonarrCmd = &cobra.Command{
 func init() {
 	cmd.RootCmd().AddCommand(sonarrCmd)
 }
+
+// completeSeries completes series IDs, described by title and year
+var completeSeries = cmd.CompleteSonarr("series", cmd.CompletionCacheTTL, func(ctx context.Context, client *sonarr.Client) ([]cobra.Completion, error) {
+	series, err := client.GetSeries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, len(series))
+	for i, s := range series {
+		completions[i] = cobra.CompletionWithDesc(strconv.Itoa(s.ID), fmt.Sprintf("%s (%d)", s.Title, s.Year))
+	}
+	return completions, nil
+})
+
+// completeQualityProfiles completes the names of the quality profiles
+var completeQualityProfiles = cmd.CompleteSonarr("qualityprofiles", cmd.CompletionCacheTTL, func(ctx context.Context, client *sonarr.Client) ([]cobra.Completion, error) {
+	profiles, err := client.GetQualityProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, len(profiles))
+	for i, profile := range profiles {
+		completions[i] = profile.Name
+	}
+	return completions, nil
+})
+
+// completeRootFolders completes the root folders, described by their free
+// space
+var completeRootFolders = cmd.CompleteSonarr("rootfolders", cmd.CompletionCacheTTL, func(ctx context.Context, client *sonarr.Client) ([]cobra.Completion, error) {
+	folders, err := client.GetRootFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, len(folders))
+	for i, folder := range folders {
+		completions[i] = cobra.CompletionWithDesc(folder.Path, output.FormatBytes(folder.FreeSpace)+" free")
+	}
+	return completions, nil
+})
+
+// addTargetFlags adds the flags choosing where a new series goes
+func addTargetFlags(command *cobra.Command) {
+	command.Flags().String("quality-profile", "", "Quality profile name or ID (default: the first profile)")
+	command.Flags().String("root-folder", "", "Root folder path (default: the first root folder)")
+	command.RegisterFlagCompletionFunc("quality-profile", completeQualityProfiles)
+	command.RegisterFlagCompletionFunc("root-folder", completeRootFolders)
+}
@@ -0,0 +1,45 @@
+package sonarr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// complete resets the fake servers and the completion cache and asks for
+// the completions of a sonarr command line
+func complete(t *testing.T, args ...string) string {
+	t.Helper()
+	env.Reset()
+	os.RemoveAll(filepath.Join(env.Home, ".cache"))
+	return env.MustRun(t, append([]string{"__complete", "sonarr"}, args...)...)
+}
+
+func TestCompleteSeries(t *testing.T) {
+	out := complete(t, "episodes", "")
+	testutil.AssertContains(t, out, "1\tBreaking Bad (2008)", ":4")
+
+	// Only the series ID is completed
+	if out := env.MustRun(t, "__complete", "sonarr", "monitor", "1", ""); strings.Contains(out, "Breaking Bad") {
+		t.Errorf("second argument should not complete series:\n%s", out)
+	}
+}
+
+func TestCompleteCached(t *testing.T) {
+	complete(t, "episodes", "")
+	env.Sonarr.Update(func(s *testutil.SonarrState) { s.Series = nil })
+
+	out := env.MustRun(t, "__complete", "sonarr", "monitor", "")
+	testutil.AssertContains(t, out, "1\tBreaking Bad (2008)")
+}
+
+func TestCompleteTargets(t *testing.T) {
+	out := complete(t, "add", "305288", "--quality-profile", "")
+	testutil.AssertContains(t, out, "Any\n", "HD-1080p\n")
+
+	out = complete(t, "search", "stranger", "--root-folder", "")
+	testutil.AssertContains(t, out, "/tv\t")
+}
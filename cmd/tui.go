@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/ascii"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+	"sonarr-sabnzbd-cli/internal/tui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen dashboard of downloads and the library",
+	Long: `Show the Sabnzbd queue and history next to the Sonarr library and the
+episodes of a series, refreshed every few seconds.
+
+Keys:
+  tab, 1-4, ←/→   switch panes
+  ↑/↓, j/k        move; PgUp/PgDn, g/G jump
+  u               refresh everything
+  q, Ctrl-C       quit
+
+Queue:    p/r pause or resume the job, d delete it, P/R pause or resume all
+Series:   enter show episodes, v poster, m toggle monitoring, s search
+Episodes: s search for the episode, v poster, esc close the poster
+
+A service that isn't configured or reachable leaves its panes showing the
+error while the other keeps working.`,
+	Args: cobra.NoArgs,
+	RunE: func(command *cobra.Command, args []string) error {
+		if !Interactive() {
+			return &usageError{err: errors.New("tui needs a terminal; use status --watch or sabnzbd queue --watch instead")}
+		}
+
+		// A nil client must stay a nil interface for the dashboard to
+		// notice it is missing
+		var sonarrClient tui.Sonarr
+		client, sonarrErr := GetSonarrClient()
+		if sonarrErr == nil {
+			sonarrClient = client
+		}
+		var sabnzbdClient tui.Sabnzbd
+		sabClient, sabnzbdErr := GetSabnzbdClient()
+		if sabnzbdErr == nil {
+			sabnzbdClient = sabClient
+		}
+
+		app := tui.New(command.Context(), sonarrClient, sonarrErr, sabnzbdClient, sabnzbdErr, Poster)
+		return tui.Run(command.Context(), app, os.Stdin, os.Stdout)
+	},
+}
+
+// Interactive reports whether stdin and stdout are both a terminal, as
+// full-screen views need
+func Interactive() bool {
+	return output.IsTerminal() && isatty.IsTerminal(os.Stdin.Fd())
+}
+
+// Poster draws a series poster as ASCII art fitting width by height, for
+// full-screen views
+func Poster(series models.Series, width, height int) (string, error) {
+	asciiConfig := ascii.DefaultConfig()
+	asciiConfig.Width, asciiConfig.Height = width, height
+	asciiConfig.Colored = output.Colors()
+	return ascii.GetSeriesPosterASCII(series, asciiConfig)
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
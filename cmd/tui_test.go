@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTUINeedsTerminal(t *testing.T) {
+	env.Reset()
+	_, err := env.Run(t, "tui")
+	if err == nil || !strings.Contains(err.Error(), "tui needs a terminal") {
+		t.Fatalf("tui without a terminal: err = %v, want terminal error", err)
+	}
+	if got := ExitCode(err); got != ExitUsage {
+		t.Errorf("ExitCode(%v) = %d, want %d", err, got, ExitUsage)
+	}
+}
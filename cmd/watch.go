@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// defaultWatchInterval is the refresh interval of a bare --watch
+const defaultWatchInterval = 2 * time.Second
+
+// minWatchInterval keeps --watch from flooding the services with requests
+const minWatchInterval = 100 * time.Millisecond
+
+// AddWatchFlag adds --watch to a command that shows something worth
+// following, and lets the interval follow a bare --watch as an argument.
+// A bad interval exits with ExitUsage like other flag errors.
+func AddWatchFlag(command *cobra.Command) {
+	command.Flags().Duration("watch", 0, "Refresh at this interval until interrupted")
+	command.Flags().Lookup("watch").NoOptDefVal = defaultWatchInterval.String()
+	command.Args = func(command *cobra.Command, args []string) error {
+		if _, err := WatchInterval(command, args); err != nil {
+			return &usageError{err: err}
+		}
+		return nil
+	}
+}
+
+// WatchInterval returns the --watch interval, or 0 without --watch. Since
+// the flag's value is optional, "--watch 5s" leaves 5s as an argument.
+func WatchInterval(command *cobra.Command, args []string) (time.Duration, error) {
+	interval, _ := command.Flags().GetDuration("watch")
+	if !command.Flags().Changed("watch") {
+		if len(args) > 0 {
+			return 0, fmt.Errorf("unknown argument %q for %q", args[0], command.CommandPath())
+		}
+		return 0, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("unknown argument %q for %q", args[1], command.CommandPath())
+	}
+	if len(args) == 1 {
+		parsed, err := time.ParseDuration(args[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid --watch interval %q: %w", args[0], err)
+		}
+		interval = parsed
+	}
+	if interval < minWatchInterval {
+		return 0, fmt.Errorf("--watch interval must be at least %s", minWatchInterval)
+	}
+	return interval, nil
+}
+
+// Watch calls refresh every interval until interrupted, drawing what it
+// writes in place of the previous frame on a terminal. An error on the
+// first refresh is returned; later ones are shown in the frame, so that a
+// service restarting doesn't end the watch. Ctrl-C ends it cleanly.
+func Watch(command *cobra.Command, interval time.Duration, refresh func(w io.Writer) error) error {
+	ctx := command.Context()
+	format := OutputFormat(command)
+	live := output.NewLive(os.Stdout, format.Text() && output.IsTerminal())
+	defer live.Close()
+
+	name := strings.TrimPrefix(command.CommandPath(), command.Root().Name()+" ")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for frames := 0; ; frames++ {
+		var frame bytes.Buffer
+		if format.Text() {
+			if frames > 0 && !output.IsTerminal() {
+				frame.WriteString("\n")
+			}
+			fmt.Fprintf(&frame, "%s   %s\n\n",
+				output.Bold(fmt.Sprintf("Every %s: %s", interval, name)), time.Now().Format("15:04:05"))
+		}
+		err := refresh(&frame)
+		// A refresh cut short by Ctrl-C is not worth showing
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			if frames == 0 {
+				return err
+			}
+			if format.Text() {
+				fmt.Fprintf(&frame, "\n%s\n", output.Red("❌ "+err.Error()))
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+		if err := live.Draw(frame.String()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
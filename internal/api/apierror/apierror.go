@@ -0,0 +1,241 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Kind classifies an API failure
+type Kind int
+
+const (
+	// KindUnknown is an unclassified failure
+	KindUnknown Kind = iota
+	// KindAuth is a rejected or missing API key or password
+	KindAuth
+	// KindNotFound is a missing resource
+	KindNotFound
+	// KindValidation is a request rejected because of invalid fields
+	KindValidation
+	// KindConflict is a request rejected because the resource already exists
+	KindConflict
+	// KindConnection is a failure to reach the server
+	KindConnection
+	// KindTimeout is a request that did not complete in time
+	KindTimeout
+	// KindServer is a 5xx response from the server or a proxy in front of it
+	KindServer
+	// KindAPI is an error reported in the response body, such as Sabnzbd's
+	// status:false
+	KindAPI
+)
+
+// String returns a short description of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindAuth:
+		return "authentication failed"
+	case KindNotFound:
+		return "not found"
+	case KindValidation:
+		return "validation failed"
+	case KindConflict:
+		return "already exists"
+	case KindConnection:
+		return "connection failed"
+	case KindTimeout:
+		return "timed out"
+	case KindServer:
+		return "server error"
+	case KindAPI:
+		return "API error"
+	default:
+		return "request failed"
+	}
+}
+
+// Sentinel errors for use with errors.Is
+var (
+	ErrAuth       = &Error{Kind: KindAuth}
+	ErrNotFound   = &Error{Kind: KindNotFound}
+	ErrValidation = &Error{Kind: KindValidation}
+	ErrConflict   = &Error{Kind: KindConflict}
+	ErrConnection = &Error{Kind: KindConnection}
+	ErrTimeout    = &Error{Kind: KindTimeout}
+	ErrServer     = &Error{Kind: KindServer}
+	ErrAPI        = &Error{Kind: KindAPI}
+)
+
+// FieldError is a validation message for a single field, as returned by
+// Sonarr in the body of a 400 response
+type FieldError struct {
+	Field     string `json:"propertyName"`
+	Message   string `json:"errorMessage"`
+	ErrorCode string `json:"errorCode"`
+}
+
+// Error is returned by the API clients for failed requests
+type Error struct {
+	Kind       Kind
+	Service    string
+	StatusCode int
+	Message    string
+	Fields     []FieldError
+	Err        error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Service != "" {
+		b.WriteString(e.Service + " ")
+	}
+	b.WriteString(e.Kind.String())
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, " (HTTP %d)", e.StatusCode)
+	}
+
+	var details []string
+	for _, field := range e.Fields {
+		if field.Field != "" {
+			details = append(details, fmt.Sprintf("%s: %s", field.Field, field.Message))
+		} else {
+			details = append(details, field.Message)
+		}
+	}
+	if len(details) == 0 && e.Message != "" {
+		details = append(details, e.Message)
+	}
+	if len(details) == 0 && e.Err != nil {
+		details = append(details, e.Err.Error())
+	}
+	if len(details) > 0 {
+		b.WriteString(": " + strings.Join(details, "; "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same kind, so the sentinel
+// errors can be matched with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Service == "" && t.StatusCode == 0
+}
+
+// FromResponse builds an error from a non-2xx HTTP response
+func FromResponse(service string, statusCode int, body []byte) *Error {
+	e := &Error{
+		Service:    service,
+		StatusCode: statusCode,
+		Kind:       kindForStatus(statusCode),
+	}
+
+	// Sonarr returns a list of field failures for validation errors and an
+	// object with a message for other errors
+	var fields []FieldError
+	var message struct {
+		Message     string `json:"message"`
+		Description string `json:"description"`
+		Error       string `json:"error"`
+	}
+	switch {
+	case json.Unmarshal(body, &fields) == nil && len(fields) > 0:
+		e.Fields = fields
+		if e.Kind == KindUnknown {
+			e.Kind = KindValidation
+		}
+		for _, field := range fields {
+			if strings.HasSuffix(field.ErrorCode, "ExistsValidator") {
+				e.Kind = KindConflict
+			}
+		}
+	case json.Unmarshal(body, &message) == nil:
+		e.Message = message.Message
+		if e.Message == "" {
+			e.Message = message.Error
+		}
+		if message.Description != "" && message.Description != e.Message {
+			e.Message = strings.TrimSpace(e.Message + " " + message.Description)
+		}
+	default:
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	return e
+}
+
+// FromStatus builds an error from a failure reported in a response body,
+// such as Sabnzbd's status:false
+func FromStatus(service, message string) *Error {
+	kind := KindAPI
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "api key") || strings.Contains(lower, "authentication") {
+		kind = KindAuth
+	}
+	return &Error{
+		Kind:    kind,
+		Service: service,
+		Message: message,
+	}
+}
+
+// FromTransport classifies an error returned by http.Client.Do. Context
+// cancellation is returned unchanged so callers can detect interrupts.
+func FromTransport(service string, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	kind := KindConnection
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		kind = KindTimeout
+	}
+
+	message := ""
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		message = "connection refused"
+	case errors.As(err, &dnsErr):
+		message = fmt.Sprintf("cannot resolve host %s", dnsErr.Name)
+	}
+
+	return &Error{
+		Kind:    kind,
+		Service: service,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// kindForStatus maps an HTTP status code to a kind
+func kindForStatus(statusCode int) Kind {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return KindAuth
+	case statusCode == http.StatusNotFound:
+		return KindNotFound
+	case statusCode == http.StatusConflict:
+		return KindConflict
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return KindValidation
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return KindTimeout
+	case statusCode >= 500:
+		return KindServer
+	default:
+		return KindUnknown
+	}
+}
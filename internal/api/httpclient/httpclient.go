@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Options holds the connection settings shared by the API clients
+type Options struct {
+	Timeout            time.Duration
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+	Headers            map[string]string
+
+	// Retries is the number of times an idempotent request is retried after
+	// a network error, a 5xx status or 429 Too Many Requests
+	Retries      int
+	RetryWait    time.Duration
+	RetryMaxWait time.Duration
+
+	// RateLimit caps requests per second; zero disables rate limiting
+	RateLimit float64
+	RateBurst int
+}
+
+// New creates an HTTP client configured with TLS settings and extra headers
+func New(opts Options) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, fmt.Errorf("both cert_file and key_file are required for client certificates")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tlsConfig
+
+	retry := &retryTransport{
+		retries: opts.Retries,
+		minWait: opts.RetryWait,
+		maxWait: opts.RetryMaxWait,
+		next:    &logTransport{headers: opts.Headers, next: base},
+	}
+	if retry.minWait <= 0 {
+		retry.minWait = 500 * time.Millisecond
+	}
+	if retry.maxWait < retry.minWait {
+		retry.maxWait = retry.minWait
+	}
+	if opts.RateLimit > 0 {
+		retry.limiter = newRateLimiter(opts.RateLimit, opts.RateBurst)
+	}
+
+	var transport http.RoundTripper = retry
+	if len(opts.Headers) > 0 {
+		transport = &headerTransport{headers: opts.Headers, next: retry}
+	}
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// BaseURL builds the base URL of a service. A full URL takes precedence over
+// host and port; urlBase is appended to the path in either case.
+func BaseURL(rawURL, host string, port int, urlBase string) (*url.URL, error) {
+	if rawURL == "" {
+		rawURL = fmt.Sprintf("http://%s:%d", host, port)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: missing host", rawURL)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if base := strings.Trim(urlBase, "/"); base != "" {
+		u.Path += "/" + base
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u, nil
+}
+
+// headerTransport adds fixed headers to every request, e.g. for
+// forward-auth proxies
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.next.RoundTrip(req)
+}
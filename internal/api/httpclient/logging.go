@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LevelTrace is the slog level at which request and response bodies are
+// logged. It is below slog.LevelDebug so --debug does not include bodies.
+const LevelTrace = slog.LevelDebug - 4
+
+// maxTraceBody caps the number of body bytes written to the log
+const maxTraceBody = 64 * 1024
+
+// logTransport logs every request attempt with its status and latency.
+// Headers are logged at debug level and bodies at trace level, with
+// credentials redacted.
+type logTransport struct {
+	// headers are the extra headers from the configuration, which are
+	// redacted like credentials
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	logger := slog.Default()
+	if !logger.Enabled(ctx, slog.LevelInfo) {
+		return t.next.RoundTrip(req)
+	}
+
+	secrets := requestSecrets(req, t.headers)
+	target := redactURL(req.URL)
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug("http request headers",
+			"method", req.Method,
+			"url", target,
+			"headers", redactHeaders(req.Header, t.headers))
+	}
+	if logger.Enabled(ctx, LevelTrace) && req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxTraceBody))
+			body.Close()
+			logger.Log(ctx, LevelTrace, "http request body",
+				"method", req.Method,
+				"url", target,
+				"body", redactBody(string(data), secrets))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		logger.Info("http request failed",
+			"method", req.Method,
+			"url", target,
+			"latency", latency,
+			"error", err)
+		return resp, err
+	}
+
+	logger.Info("http request",
+		"method", req.Method,
+		"url", target,
+		"status", resp.StatusCode,
+		"latency", latency)
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug("http response headers",
+			"url", target,
+			"headers", redactHeaders(resp.Header, t.headers))
+	}
+	if logger.Enabled(ctx, LevelTrace) {
+		// Buffer the body so it can be logged and still read by the caller
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if readErr != nil {
+			return resp, readErr
+		}
+
+		logged := data
+		if len(logged) > maxTraceBody {
+			logged = logged[:maxTraceBody]
+		}
+		logger.Log(ctx, LevelTrace, "http response body",
+			"url", target,
+			"status", resp.StatusCode,
+			"bytes", len(data),
+			"body", redactBody(string(logged), secrets))
+	}
+
+	return resp, nil
+}
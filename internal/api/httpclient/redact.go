@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secret values in logs
+const redacted = "REDACTED"
+
+// secretParams are query parameters and JSON fields that hold credentials
+var secretParams = map[string]bool{
+	"apikey":      true,
+	"api_key":     true,
+	"nzbkey":      true,
+	"nzb_key":     true,
+	"password":    true,
+	"pass":        true,
+	"ma_username": true,
+	"ma_password": true,
+	"username":    true,
+	"email_pwd":   true,
+}
+
+// secretHeaders are request headers that hold credentials
+var secretHeaders = map[string]bool{
+	"X-Api-Key":           true,
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// secretFieldPattern matches JSON string fields whose name holds a credential
+var secretFieldPattern = regexp.MustCompile(`(?i)("(?:api_?key|nzb_?key|password|pass|ma_username|ma_password|username|email_pwd)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// redactURL returns the URL as a string with user info and secret query
+// parameters replaced
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	if c.User != nil {
+		c.User = url.UserPassword(redacted, redacted)
+	}
+	if c.RawQuery != "" {
+		query := c.Query()
+		for key := range query {
+			if isSecretParam(query, key) {
+				query.Set(key, redacted)
+			}
+		}
+		c.RawQuery = query.Encode()
+	}
+	return c.String()
+}
+
+// isSecretParam reports whether the query parameter key holds a credential.
+// Sabnzbd's set_config passes the setting as keyword and its new value as
+// value, so value is secret when keyword names a secret setting.
+func isSecretParam(query url.Values, key string) bool {
+	key = strings.ToLower(key)
+	if key == "value" {
+		return secretParams[strings.ToLower(query.Get("keyword"))]
+	}
+	return secretParams[key]
+}
+
+// redactHeaders returns a copy of the headers with credentials replaced.
+// The extra headers from the configuration are replaced too, as they are
+// usually proxy credentials.
+func redactHeaders(header http.Header, extra map[string]string) map[string]string {
+	hidden := make(map[string]bool, len(extra))
+	for key := range extra {
+		hidden[http.CanonicalHeaderKey(key)] = true
+	}
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		if canonical := http.CanonicalHeaderKey(key); secretHeaders[canonical] || hidden[canonical] {
+			result[key] = redacted
+		} else {
+			result[key] = strings.Join(values, ", ")
+		}
+	}
+	return result
+}
+
+// redactBody replaces secret JSON fields and any of the given literal
+// secrets in a request or response body
+func redactBody(body string, secrets []string) string {
+	body = secretFieldPattern.ReplaceAllString(body, `$1"`+redacted+`"`)
+	for _, secret := range secrets {
+		if secret != "" {
+			body = strings.ReplaceAll(body, secret, redacted)
+		}
+	}
+	return body
+}
+
+// requestSecrets returns the credentials carried by a request, including
+// the values of the extra headers, so they can be removed wherever they are
+// echoed back
+func requestSecrets(req *http.Request, extra map[string]string) []string {
+	var secrets []string
+	for _, value := range extra {
+		secrets = append(secrets, value)
+	}
+	query := req.URL.Query()
+	for key, values := range query {
+		if isSecretParam(query, key) {
+			secrets = append(secrets, values...)
+		}
+	}
+	if key := req.Header.Get("X-Api-Key"); key != "" {
+		secrets = append(secrets, key)
+	}
+	if req.URL.User != nil {
+		if password, ok := req.URL.User.Password(); ok {
+			secrets = append(secrets, password)
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryTransport retries idempotent requests that fail with a network
+// error, a 5xx status or 429 Too Many Requests
+type retryTransport struct {
+	retries int
+	minWait time.Duration
+	maxWait time.Duration
+	limiter *rateLimiter
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retries := t.retries
+	if !isIdempotent(req.Method) || ctx.Value(noRetryKey{}) != nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= retries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			// The server may ask for a longer wait, but not beyond maxWait
+			if after, ok := retryAfter(resp); ok {
+				wait = min(after, t.maxWait)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		slog.Info("retrying request",
+			"method", req.Method,
+			"url", redactURL(req.URL),
+			"attempt", attempt+1,
+			"max_retries", retries,
+			"reason", reason,
+			"wait", wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the exponential backoff with jitter for an attempt
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	wait := t.minWait << attempt
+	if wait <= 0 || wait > t.maxWait {
+		wait = t.maxWait
+	}
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
+
+// noRetryKey marks a context whose requests must not be repeated
+type noRetryKey struct{}
+
+// NoRetry returns a context whose requests are never retried. It is for
+// APIs that change state with GET, such as Sabnzbd, where the method says
+// nothing about whether a request is safe to repeat.
+func NoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// isIdempotent reports whether a request method is safe to repeat
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether a response or error is worth retrying
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses the Retry-After header in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
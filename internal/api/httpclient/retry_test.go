@@ -0,0 +1,195 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer answers every request with status and counts them
+func failingServer(t *testing.T, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &count
+}
+
+// newRetryClient returns a client retrying twice with short waits
+func newRetryClient(t *testing.T, maxWait time.Duration) *http.Client {
+	t.Helper()
+	client, err := New(Options{Retries: 2, RetryWait: time.Millisecond, RetryMaxWait: maxWait})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		ctx    context.Context
+		status int
+		want   int32
+	}{
+		{"server error", http.MethodGet, context.Background(), http.StatusServiceUnavailable, 3},
+		{"too many requests", http.MethodGet, context.Background(), http.StatusTooManyRequests, 3},
+		{"client error", http.MethodGet, context.Background(), http.StatusNotFound, 1},
+		{"not idempotent", http.MethodPost, context.Background(), http.StatusServiceUnavailable, 1},
+		{"no retry", http.MethodGet, NoRetry(context.Background()), http.StatusServiceUnavailable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, count := failingServer(t, tt.status, nil)
+			req, err := http.NewRequestWithContext(tt.ctx, tt.method, server.URL, strings.NewReader(""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newRetryClient(t, 10*time.Millisecond).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := count.Load(); got != tt.want {
+				t.Errorf("%d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	server, count := failingServer(t, http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}})
+
+	start := time.Now()
+	resp, err := newRetryClient(t, 20*time.Millisecond).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s; Retry-After should be capped at the maximum wait", elapsed)
+	}
+	if got := count.Load(); got != 3 {
+		t.Errorf("%d requests, want 3", got)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	server, count := failingServer(t, http.StatusServiceUnavailable, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &http.Client{Transport: &retryTransport{
+		retries: 5,
+		minWait: time.Hour,
+		maxWait: time.Hour,
+		next:    http.DefaultTransport,
+	}}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Error("expected an error when cancelled while waiting")
+	}
+	if got := count.Load(); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	transport := &retryTransport{minWait: 100 * time.Millisecond, maxWait: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{5, 500 * time.Millisecond, time.Second},
+		// Shifting overflows to a negative wait, which is capped too
+		{70, 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if wait := transport.backoff(tt.attempt); wait < tt.min || wait > tt.max {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, wait, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(resp)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}}
+	if got, ok := retryAfter(resp); !ok || got <= 50*time.Second || got > time.Minute {
+		t.Errorf("retryAfter(date in a minute) = %s, %t", got, ok)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(10, 2)
+
+	// The burst is available at once, then requests are spaced out
+	for i := range 2 {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("request %d delayed %s within the burst", i, delay)
+		}
+	}
+	if delay := limiter.reserve(); delay <= 0 || delay > 100*time.Millisecond {
+		t.Errorf("delay after the burst = %s, want up to 100ms", delay)
+	}
+
+	start := time.Now()
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("wait returned after %s, want about 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newRateLimiter(0.001, 1).wait(ctx); err != nil {
+		t.Errorf("the first request should not wait, got %v", err)
+	}
+	slow := newRateLimiter(0.001, 1)
+	slow.reserve()
+	if err := slow.wait(ctx); err == nil {
+		t.Error("wait should fail once the context is cancelled")
+	}
+}
@@ -1,16 +1,23 @@
 package sabnzbd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
+	"strconv"
+	"strings"
 
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/api/httpclient"
 	"sonarr-sabnzbd-cli/internal/models"
 )
 
+// serviceName identifies Sabnzbd in API errors
+const serviceName = "Sabnzbd"
+
 // Client represents a Sabnzbd API client
 type Client struct {
 	baseURL  string
@@ -21,27 +28,45 @@ type Client struct {
 }
 
 // NewClient creates a new Sabnzbd API client
-func NewClient(host string, port int, apiKey, username, password string, timeout time.Duration) *Client {
-	baseURL := fmt.Sprintf("http://%s:%d", host, port)
-	if username != "" && password != "" {
-		baseURL = fmt.Sprintf("http://%s:%s@%s:%d", username, password, host, port)
+func NewClient(config models.SabnzbdConfig, httpConfig models.HTTPConfig) (*Client, error) {
+	baseURL, err := httpclient.BaseURL(config.URL, config.Host, config.Port, config.URLBase)
+	if err != nil {
+		return nil, err
+	}
+	if config.Username != "" && config.Password != "" {
+		baseURL.User = url.UserPassword(config.Username, config.Password)
 	}
 
-	return &Client{
-		baseURL:  baseURL,
-		apiKey:   apiKey,
-		username: username,
-		password: password,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+	httpClient, err := httpclient.New(httpclient.Options{
+		Timeout:            config.Timeout,
+		CAFile:             config.CAFile,
+		CertFile:           config.CertFile,
+		KeyFile:            config.KeyFile,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		Headers:            config.Headers,
+		Retries:            httpConfig.Retries,
+		RetryWait:          httpConfig.RetryWait,
+		RetryMaxWait:       httpConfig.RetryMaxWait,
+		RateLimit:          httpConfig.RateLimit,
+		RateBurst:          httpConfig.RateBurst,
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return &Client{
+		baseURL:  baseURL.String(),
+		apiKey:   config.APIKey,
+		username: config.Username,
+		password: config.Password,
+		client:   httpClient,
+	}, nil
 }
 
 // GetVersion retrieves the Sabnzbd version
-func (c *Client) GetVersion() (string, error) {
+func (c *Client) GetVersion(ctx context.Context) (string, error) {
 	var resp models.VersionResponse
-	err := c.get("mode=version", &resp)
+	err := c.get(ctx, "mode=version", &resp)
 	if err != nil {
 		return "", err
 	}
@@ -51,15 +76,204 @@ func (c *Client) GetVersion() (string, error) {
 		return resp.Version, nil
 	}
 	if !resp.Status {
-		return "", fmt.Errorf("API error: %s", resp.Error)
+		return "", apierror.FromStatus(serviceName, resp.Error)
 	}
 	return resp.Version, nil
 }
 
+// GetFullStatus retrieves detailed status including servers and disk space
+func (c *Client) GetFullStatus(ctx context.Context) (*models.FullStatus, error) {
+	var resp models.FullStatusResponse
+	err := c.get(ctx, "mode=fullstatus&skip_dashboard=0", &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Status, nil
+}
+
+// GetWarnings retrieves the active warnings
+func (c *Client) GetWarnings(ctx context.Context) ([]models.Warning, error) {
+	var resp models.WarningsResponse
+	err := c.get(ctx, "mode=warnings", &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Warnings, nil
+}
+
+// ClearWarnings dismisses all active warnings
+func (c *Client) ClearWarnings(ctx context.Context) error {
+	params := url.Values{}
+	params.Add("name", "clear")
+	return c.simpleCommandWithParams(ctx, "warnings", params)
+}
+
+// GetServerStats retrieves the download totals per news server
+func (c *Client) GetServerStats(ctx context.Context) (*models.ServerStats, error) {
+	var resp models.ServerStats
+	err := c.get(ctx, "mode=server_stats", &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetConfig retrieves configuration values. An empty section returns the
+// whole configuration and an empty keyword returns the whole section.
+func (c *Client) GetConfig(ctx context.Context, section, keyword string) (map[string]any, error) {
+	params := url.Values{}
+	params.Add("mode", "get_config")
+	if section != "" {
+		params.Add("section", section)
+	}
+	if keyword != "" {
+		params.Add("keyword", keyword)
+	}
+
+	var resp models.ConfigResponse
+	err := c.getWithParams(ctx, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, apierror.FromStatus(serviceName, resp.Error)
+	}
+	return resp.Config, nil
+}
+
+// SetConfigValue sets a single value in a plain section such as misc
+func (c *Client) SetConfigValue(ctx context.Context, section, keyword, value string) error {
+	params := url.Values{}
+	params.Add("section", section)
+	params.Add("keyword", keyword)
+	params.Add("value", value)
+	return c.setConfig(ctx, params)
+}
+
+// SetConfigItem creates or updates a named item in a section such as
+// servers, categories or rss
+func (c *Client) SetConfigItem(ctx context.Context, section, name string, fields map[string]string) error {
+	params := url.Values{}
+	params.Add("section", section)
+	params.Add("keyword", name)
+	for key, value := range fields {
+		params.Add(key, value)
+	}
+	return c.setConfig(ctx, params)
+}
+
+// setConfig performs a set_config request
+func (c *Client) setConfig(ctx context.Context, params url.Values) error {
+	params.Set("mode", "set_config")
+	var resp models.ConfigResponse
+	err := c.getWithParams(ctx, params, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return apierror.FromStatus(serviceName, resp.Error)
+	}
+	return nil
+}
+
+// DeleteConfigItem removes a named item from a section such as rss
+func (c *Client) DeleteConfigItem(ctx context.Context, section, name string) error {
+	params := url.Values{}
+	params.Add("section", section)
+	params.Add("keyword", name)
+	return c.simpleCommandWithParams(ctx, "del_config", params)
+}
+
+// GetRSSFeeds retrieves the configured RSS feeds
+func (c *Client) GetRSSFeeds(ctx context.Context) ([]models.RSSFeed, error) {
+	params := url.Values{}
+	params.Add("mode", "get_config")
+	params.Add("section", "rss")
+
+	var resp models.RSSConfigResponse
+	err := c.getWithParams(ctx, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, apierror.FromStatus(serviceName, resp.Error)
+	}
+	return resp.Config.RSS, nil
+}
+
+// AddRSSFeed adds an enabled RSS feed
+func (c *Client) AddRSSFeed(ctx context.Context, name, uri, category string) error {
+	fields := map[string]string{
+		"uri":    uri,
+		"enable": "1",
+	}
+	if category != "" {
+		fields["cat"] = category
+	}
+	return c.SetConfigItem(ctx, "rss", name, fields)
+}
+
+// RemoveRSSFeed removes an RSS feed
+func (c *Client) RemoveRSSFeed(ctx context.Context, name string) error {
+	return c.DeleteConfigItem(ctx, "rss", name)
+}
+
+// RunRSS triggers an immediate scan of all RSS feeds
+func (c *Client) RunRSS(ctx context.Context) error {
+	return c.simpleCommand(ctx, "rss_now")
+}
+
+// GetOrphans retrieves the orphaned job folders in the incomplete folder
+func (c *Client) GetOrphans(ctx context.Context) ([]string, error) {
+	status, err := c.GetFullStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return status.Folders, nil
+}
+
+// AddOrphan re-adds an orphaned job folder to the queue. An empty folder
+// re-adds all orphans.
+func (c *Client) AddOrphan(ctx context.Context, folder string) error {
+	if folder == "" {
+		return c.orphanCommand(ctx, "add_all_orphan", "")
+	}
+	return c.orphanCommand(ctx, "add_orphan", folder)
+}
+
+// DeleteOrphan deletes an orphaned job folder from disk. An empty folder
+// deletes all orphans.
+func (c *Client) DeleteOrphan(ctx context.Context, folder string) error {
+	if folder == "" {
+		return c.orphanCommand(ctx, "delete_all_orphan", "")
+	}
+	return c.orphanCommand(ctx, "delete_orphan", folder)
+}
+
+// orphanCommand performs an orphan action through the status endpoint
+func (c *Client) orphanCommand(ctx context.Context, name, folder string) error {
+	params := url.Values{}
+	params.Add("name", name)
+	if folder != "" {
+		params.Add("value", folder)
+	}
+	return c.simpleCommandWithParams(ctx, "status", params)
+}
+
+// GetScripts retrieves the available post-processing scripts
+func (c *Client) GetScripts(ctx context.Context) ([]string, error) {
+	var resp models.ScriptsResponse
+	err := c.get(ctx, "mode=get_scripts", &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Scripts, nil
+}
+
 // GetQueue retrieves the current download queue
-func (c *Client) GetQueue() (*models.Queue, error) {
+func (c *Client) GetQueue(ctx context.Context) (*models.Queue, error) {
 	var resp models.QueueResponse
-	err := c.get("mode=queue", &resp)
+	err := c.get(ctx, "mode=queue", &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -67,9 +281,9 @@ func (c *Client) GetQueue() (*models.Queue, error) {
 }
 
 // GetHistory retrieves the download history
-func (c *Client) GetHistory() (*models.History, error) {
+func (c *Client) GetHistory(ctx context.Context) (*models.History, error) {
 	var resp models.HistoryResponse
-	err := c.get("mode=history", &resp)
+	err := c.get(ctx, "mode=history", &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +291,7 @@ func (c *Client) GetHistory() (*models.History, error) {
 }
 
 // AddNZB adds an NZB file or URL to the queue
-func (c *Client) AddNZB(nzbURL string, category string) ([]string, error) {
+func (c *Client) AddNZB(ctx context.Context, nzbURL string, category string) ([]string, error) {
 	params := url.Values{}
 	params.Add("mode", "addurl")
 	params.Add("name", nzbURL)
@@ -86,37 +300,37 @@ func (c *Client) AddNZB(nzbURL string, category string) ([]string, error) {
 	}
 
 	var resp models.AddResponse
-	err := c.getWithParams(params, &resp)
+	err := c.getWithParams(ctx, params, &resp)
 	if err != nil {
 		return nil, err
 	}
 	if !resp.Status {
-		return nil, fmt.Errorf("API error: %s", resp.Error)
+		return nil, apierror.FromStatus(serviceName, resp.Error)
 	}
 	return resp.NZOIDS, nil
 }
 
 // PauseQueue pauses the download queue
-func (c *Client) PauseQueue() error {
-	return c.simpleCommand("pause")
+func (c *Client) PauseQueue(ctx context.Context) error {
+	return c.simpleCommand(ctx, "pause")
 }
 
 // ResumeQueue resumes the download queue
-func (c *Client) ResumeQueue() error {
-	return c.simpleCommand("resume")
+func (c *Client) ResumeQueue(ctx context.Context) error {
+	return c.simpleCommand(ctx, "resume")
 }
 
 // SetSpeedLimit sets the download speed limit
-func (c *Client) SetSpeedLimit(limit string) error {
+func (c *Client) SetSpeedLimit(ctx context.Context, limit string) error {
 	params := url.Values{}
 	params.Add("value", limit)
-	return c.simpleCommandWithParams("speedlimit", params)
+	return c.simpleCommandWithParams(ctx, "speedlimit", params)
 }
 
 // GetCategories retrieves available categories
-func (c *Client) GetCategories() ([]string, error) {
+func (c *Client) GetCategories(ctx context.Context) ([]string, error) {
 	var resp models.CategoriesResponse
-	err := c.get("mode=get_cats", &resp)
+	err := c.get(ctx, "mode=get_cats", &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -124,59 +338,113 @@ func (c *Client) GetCategories() ([]string, error) {
 }
 
 // DeleteFromQueue deletes an item from the queue
-func (c *Client) DeleteFromQueue(nzoID string) error {
+func (c *Client) DeleteFromQueue(ctx context.Context, nzoID string) error {
+	params := url.Values{}
+	params.Add("name", "delete")
+	params.Add("value", nzoID)
+	return c.simpleCommandWithParams(ctx, "queue", params)
+}
+
+// PauseJob pauses a single job in the queue
+func (c *Client) PauseJob(ctx context.Context, nzoID string) error {
 	params := url.Values{}
+	params.Add("name", "pause")
 	params.Add("value", nzoID)
-	return c.simpleCommandWithParams("queue", params)
+	return c.simpleCommandWithParams(ctx, "queue", params)
+}
+
+// ResumeJob resumes a single paused job in the queue
+func (c *Client) ResumeJob(ctx context.Context, nzoID string) error {
+	params := url.Values{}
+	params.Add("name", "resume")
+	params.Add("value", nzoID)
+	return c.simpleCommandWithParams(ctx, "queue", params)
+}
+
+// GetFiles retrieves the individual files of a queued job
+func (c *Client) GetFiles(ctx context.Context, nzoID string) ([]models.JobFile, error) {
+	params := url.Values{}
+	params.Add("mode", "get_files")
+	params.Add("value", nzoID)
+
+	var resp models.FilesResponse
+	err := c.getWithParams(ctx, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Files, nil
+}
+
+// DeleteFiles removes individual files from a queued job
+func (c *Client) DeleteFiles(ctx context.Context, nzoID string, nzfIDs []string) error {
+	params := url.Values{}
+	params.Add("name", "delete_nzf")
+	params.Add("value", nzoID)
+	params.Add("value2", strings.Join(nzfIDs, ","))
+	return c.simpleCommandWithParams(ctx, "queue", params)
+}
+
+// MoveFiles moves files within a queued job. Direction is one of
+// "top", "up", "down" or "bottom"; size is the number of positions
+// to move for "up" and "down".
+func (c *Client) MoveFiles(ctx context.Context, nzoID string, nzfIDs []string, direction string, size int) error {
+	params := url.Values{}
+	params.Add("name", direction)
+	params.Add("value", nzoID)
+	params.Add("nzf_ids", strings.Join(nzfIDs, ","))
+	if size > 0 {
+		params.Add("size", strconv.Itoa(size))
+	}
+	return c.simpleCommandWithParams(ctx, "move_nzf_bulk", params)
 }
 
 // simpleCommand performs a simple command without parameters
-func (c *Client) simpleCommand(command string) error {
+func (c *Client) simpleCommand(ctx context.Context, command string) error {
 	params := url.Values{}
 	params.Set("mode", command)
 	var resp models.SabnzbdResponse
-	err := c.getWithParams(params, &resp)
+	err := c.getWithParams(ctx, params, &resp)
 	if err != nil {
 		return err
 	}
 	if !resp.Status {
-		return fmt.Errorf("API error: %s", resp.Error)
+		return apierror.FromStatus(serviceName, resp.Error)
 	}
 	return nil
 }
 
 // simpleCommandWithParams performs a command with parameters
-func (c *Client) simpleCommandWithParams(command string, params url.Values) error {
+func (c *Client) simpleCommandWithParams(ctx context.Context, command string, params url.Values) error {
 	params.Set("mode", command)
 	var resp models.SabnzbdResponse
-	err := c.getWithParams(params, &resp)
+	err := c.getWithParams(ctx, params, &resp)
 	if err != nil {
 		return err
 	}
 	if !resp.Status {
-		return fmt.Errorf("API error: %s", resp.Error)
+		return apierror.FromStatus(serviceName, resp.Error)
 	}
 	return nil
 }
 
 // get performs a GET request to the Sabnzbd API
-func (c *Client) get(endpoint string, result any) error {
+func (c *Client) get(ctx context.Context, endpoint string, result any) error {
 	fullURL := fmt.Sprintf("%s/api?apikey=%s&output=json&%s", c.baseURL, c.apiKey, endpoint)
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return err
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return apierror.FromTransport(serviceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return apierror.FromResponse(serviceName, resp.StatusCode, body)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -184,24 +452,43 @@ func (c *Client) get(endpoint string, result any) error {
 		return err
 	}
 
-	return json.Unmarshal(body, result)
+	return decode(body, result)
 }
 
-// getWithParams performs a GET request with URL parameters
-func (c *Client) getWithParams(params url.Values, result any) error {
+// readOnlyModes are the API modes that only read, which are safe to retry
+var readOnlyModes = map[string]bool{
+	"version":      true,
+	"fullstatus":   true,
+	"warnings":     true,
+	"server_stats": true,
+	"get_config":   true,
+	"get_scripts":  true,
+	"queue":        true,
+	"history":      true,
+	"get_cats":     true,
+	"get_files":    true,
+}
+
+// getWithParams performs a GET request with URL parameters. Sabnzbd changes
+// state with GET too, so only requests that read are retried; a mode given a
+// name, such as queue with name=delete, is an action.
+func (c *Client) getWithParams(ctx context.Context, params url.Values, result any) error {
+	if !readOnlyModes[params.Get("mode")] || params.Has("name") {
+		ctx = httpclient.NoRetry(ctx)
+	}
 	params.Set("apikey", c.apiKey)
 	params.Set("output", "json")
 
 	fullURL := fmt.Sprintf("%s/api?%s", c.baseURL, params.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return err
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return apierror.FromTransport(serviceName, err)
 	}
 	defer resp.Body.Close()
 
@@ -211,8 +498,21 @@ func (c *Client) getWithParams(params url.Values, result any) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return apierror.FromResponse(serviceName, resp.StatusCode, body)
 	}
 
+	return decode(body, result)
+}
+
+// decode unmarshals a response body, returning an error for responses that
+// report status:false, such as an incorrect API key
+func decode(body []byte, result any) error {
+	var status struct {
+		Status *bool  `json:"status"`
+		Error  string `json:"error"`
+	}
+	if json.Unmarshal(body, &status) == nil && status.Status != nil && !*status.Status && status.Error != "" {
+		return apierror.FromStatus(serviceName, status.Error)
+	}
 	return json.Unmarshal(body, result)
 }
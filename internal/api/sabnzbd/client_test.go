@@ -0,0 +1,484 @@
+package sabnzbd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// newTestClient returns a client connected to a fresh fake server
+func newTestClient(t *testing.T) (*Client, *testutil.FakeSabnzbd) {
+	t.Helper()
+	fake := testutil.NewFakeSabnzbd(t)
+	client, err := NewClient(fake.Config(), models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, fake
+}
+
+// assertCall checks the parameters of the last request sent to the fake
+func assertCall(t *testing.T, fake *testutil.FakeSabnzbd, want map[string]string) {
+	t.Helper()
+	call := fake.LastCall()
+	for key, value := range want {
+		if got := call.Get(key); got != value {
+			t.Errorf("%s = %q, want %q (call %v)", key, got, value, call)
+		}
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	version, err := client.GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion: %v", err)
+	}
+	if version != "4.3.2" {
+		t.Errorf("GetVersion = %q, want 4.3.2", version)
+	}
+}
+
+func TestGetFullStatus(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	status, err := client.GetFullStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetFullStatus: %v", err)
+	}
+	if len(status.Servers) != 1 || status.Servers[0].Name != "news.example.com" {
+		t.Errorf("Servers = %+v, want news.example.com", status.Servers)
+	}
+	assertCall(t, fake, map[string]string{"mode": "fullstatus", "skip_dashboard": "0"})
+}
+
+func TestFullStatusModules(t *testing.T) {
+	var resp models.FullStatusResponse
+	data := `{"status": {"cpumodel": "x", "sabctools": "8.2.0", "unrar": "7.00", "7zip": "", "nice": true, "ionice": false}}`
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"sabctools": "8.2.0", "unrar": "7.00", "nice": "loaded"}
+	if got := resp.Status.Modules; !maps.Equal(got, want) || resp.Status.CPUModel != "x" {
+		t.Errorf("FullStatus = %+v, want modules %v", resp.Status, want)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+	fake.Update(func(s *testutil.SabnzbdState) {
+		s.Warnings = []models.Warning{{Text: "Disk almost full", Type: "WARNING", Time: 1700000000}}
+	})
+
+	warnings, err := client.GetWarnings(ctx)
+	if err != nil {
+		t.Fatalf("GetWarnings: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Text != "Disk almost full" {
+		t.Errorf("GetWarnings = %+v, want one warning", warnings)
+	}
+
+	if err := client.ClearWarnings(ctx); err != nil {
+		t.Fatalf("ClearWarnings: %v", err)
+	}
+	if len(fake.State().Warnings) != 0 {
+		t.Error("warnings were not cleared")
+	}
+}
+
+func TestGetServerStats(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	stats, err := client.GetServerStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetServerStats: %v", err)
+	}
+	if stats.Total == 0 || len(stats.Servers["news.example.com"].Daily) != 1 {
+		t.Errorf("GetServerStats = %+v, want totals and a daily entry", stats)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	config, err := client.GetConfig(ctx, "", "")
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if _, ok := config["servers"]; !ok {
+		t.Errorf("GetConfig = %v, want a servers section", config)
+	}
+
+	config, err = client.GetConfig(ctx, "misc", "cache_limit")
+	if err != nil {
+		t.Fatalf("GetConfig(misc, cache_limit): %v", err)
+	}
+	if misc := config["misc"].(map[string]any); misc["cache_limit"] != "1G" {
+		t.Errorf("cache_limit = %v, want 1G", misc["cache_limit"])
+	}
+	assertCall(t, fake, map[string]string{"mode": "get_config", "section": "misc", "keyword": "cache_limit"})
+
+	fake.Update(func(s *testutil.SabnzbdState) { s.Failures = map[string]string{"get_config": "Not allowed"} })
+	if _, err := client.GetConfig(ctx, "", ""); !errors.Is(err, apierror.ErrAPI) {
+		t.Errorf("GetConfig error = %v, want ErrAPI", err)
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	if err := client.SetConfigValue(context.Background(), "misc", "cache_limit", "2G"); err != nil {
+		t.Fatalf("SetConfigValue: %v", err)
+	}
+	misc := fake.State().Config["misc"].(map[string]any)
+	if misc["cache_limit"] != "2G" {
+		t.Errorf("cache_limit = %v, want 2G", misc["cache_limit"])
+	}
+}
+
+func TestSetConfigItem(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	err := client.SetConfigItem(context.Background(), "servers", "news.example.com", map[string]string{"connections": "30"})
+	if err != nil {
+		t.Fatalf("SetConfigItem: %v", err)
+	}
+	server := fake.State().Config["servers"].([]any)[0].(map[string]any)
+	if server["connections"] != 30 {
+		t.Errorf("connections = %v, want 30", server["connections"])
+	}
+	assertCall(t, fake, map[string]string{"mode": "set_config", "section": "servers", "keyword": "news.example.com"})
+}
+
+func TestDeleteConfigItem(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.DeleteConfigItem(ctx, "categories", "tv"); err != nil {
+		t.Fatalf("DeleteConfigItem: %v", err)
+	}
+	if n := len(fake.State().Config["categories"].([]any)); n != 1 {
+		t.Errorf("%d categories left, want 1", n)
+	}
+
+	if err := client.DeleteConfigItem(ctx, "categories", "missing"); !errors.Is(err, apierror.ErrAPI) {
+		t.Errorf("DeleteConfigItem(missing) error = %v, want ErrAPI", err)
+	}
+}
+
+func TestRSSFeeds(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	feeds, err := client.GetRSSFeeds(ctx)
+	if err != nil {
+		t.Fatalf("GetRSSFeeds: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].Name != "shows" || feeds[0].Category != "tv" {
+		t.Errorf("GetRSSFeeds = %+v, want the shows feed", feeds)
+	}
+
+	if err := client.AddRSSFeed(ctx, "movies", "https://indexer.example.com/movies", "movies"); err != nil {
+		t.Fatalf("AddRSSFeed: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "set_config", "section": "rss", "keyword": "movies", "enable": "1", "cat": "movies"})
+
+	if err := client.RemoveRSSFeed(ctx, "shows"); err != nil {
+		t.Fatalf("RemoveRSSFeed: %v", err)
+	}
+	feeds, err = client.GetRSSFeeds(ctx)
+	if err != nil {
+		t.Fatalf("GetRSSFeeds: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].Name != "movies" {
+		t.Errorf("feeds after add and remove = %+v, want only movies", feeds)
+	}
+
+	if err := client.RunRSS(ctx); err != nil {
+		t.Fatalf("RunRSS: %v", err)
+	}
+	if fake.State().RSSRuns != 1 {
+		t.Error("rss_now was not requested")
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	orphans, err := client.GetOrphans(ctx)
+	if err != nil {
+		t.Fatalf("GetOrphans: %v", err)
+	}
+	if !slices.Equal(orphans, []string{"Orphaned.Job.1", "Orphaned.Job.2"}) {
+		t.Errorf("GetOrphans = %v", orphans)
+	}
+
+	if err := client.AddOrphan(ctx, "Orphaned.Job.1"); err != nil {
+		t.Fatalf("AddOrphan: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "status", "name": "add_orphan", "value": "Orphaned.Job.1"})
+
+	if err := client.DeleteOrphan(ctx, "Orphaned.Job.2"); err != nil {
+		t.Fatalf("DeleteOrphan: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "status", "name": "delete_orphan", "value": "Orphaned.Job.2"})
+
+	if err := client.AddOrphan(ctx, ""); err != nil {
+		t.Fatalf("AddOrphan(all): %v", err)
+	}
+	assertCall(t, fake, map[string]string{"name": "add_all_orphan"})
+
+	if err := client.DeleteOrphan(ctx, ""); err != nil {
+		t.Fatalf("DeleteOrphan(all): %v", err)
+	}
+	assertCall(t, fake, map[string]string{"name": "delete_all_orphan"})
+}
+
+func TestGetScripts(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	scripts, err := client.GetScripts(context.Background())
+	if err != nil {
+		t.Fatalf("GetScripts: %v", err)
+	}
+	if !slices.Equal(scripts, []string{"None", "notify.py"}) {
+		t.Errorf("GetScripts = %v", scripts)
+	}
+}
+
+func TestGetQueue(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	queue, err := client.GetQueue(context.Background())
+	if err != nil {
+		t.Fatalf("GetQueue: %v", err)
+	}
+	if len(queue.Slots) != 2 || queue.Slots[0].ID != "SABnzbd_nzo_1" {
+		t.Errorf("GetQueue slots = %+v", queue.Slots)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	history, err := client.GetHistory(context.Background())
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history.Slots) != 2 || history.Slots[1].Status != "Failed" {
+		t.Errorf("GetHistory slots = %+v", history.Slots)
+	}
+}
+
+func TestAddNZB(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	ids, err := client.AddNZB(ctx, "https://indexer.example.com/get/1.nzb", "tv")
+	if err != nil {
+		t.Fatalf("AddNZB: %v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("AddNZB returned %v, want one ID", ids)
+	}
+	assertCall(t, fake, map[string]string{"mode": "addurl", "name": "https://indexer.example.com/get/1.nzb", "cat": "tv"})
+
+	if _, err := client.AddNZB(ctx, "", ""); !errors.Is(err, apierror.ErrAPI) {
+		t.Errorf("AddNZB(empty) error = %v, want ErrAPI", err)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.PauseQueue(ctx); err != nil {
+		t.Fatalf("PauseQueue: %v", err)
+	}
+	if !fake.State().Queue.Paused {
+		t.Error("queue was not paused")
+	}
+
+	if err := client.ResumeQueue(ctx); err != nil {
+		t.Fatalf("ResumeQueue: %v", err)
+	}
+	if fake.State().Queue.Paused {
+		t.Error("queue was not resumed")
+	}
+}
+
+func TestSetSpeedLimit(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	if err := client.SetSpeedLimit(context.Background(), "50"); err != nil {
+		t.Fatalf("SetSpeedLimit: %v", err)
+	}
+	if limit := fake.State().Queue.SpeedLimit; limit != "50" {
+		t.Errorf("SpeedLimit = %q, want 50", limit)
+	}
+}
+
+func TestGetCategories(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	categories, err := client.GetCategories(context.Background())
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if !slices.Equal(categories, []string{"*", "movies", "tv"}) {
+		t.Errorf("GetCategories = %v", categories)
+	}
+}
+
+func TestDeleteFromQueue(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.DeleteFromQueue(ctx, "SABnzbd_nzo_2"); err != nil {
+		t.Fatalf("DeleteFromQueue: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "queue", "name": "delete", "value": "SABnzbd_nzo_2"})
+	if n := len(fake.State().Queue.Slots); n != 1 {
+		t.Errorf("%d jobs left, want 1", n)
+	}
+
+	if err := client.DeleteFromQueue(ctx, "SABnzbd_nzo_missing"); !errors.Is(err, apierror.ErrAPI) {
+		t.Errorf("DeleteFromQueue(missing) error = %v, want ErrAPI", err)
+	}
+}
+
+func TestPauseResumeJob(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.PauseJob(ctx, "SABnzbd_nzo_1"); err != nil {
+		t.Fatalf("PauseJob: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "queue", "name": "pause", "value": "SABnzbd_nzo_1"})
+	if status := fake.State().Queue.Slots[0].Status; status != "Paused" {
+		t.Errorf("status = %q, want Paused", status)
+	}
+
+	if err := client.ResumeJob(ctx, "SABnzbd_nzo_1"); err != nil {
+		t.Fatalf("ResumeJob: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "queue", "name": "resume", "value": "SABnzbd_nzo_1"})
+
+	if err := client.PauseJob(ctx, "SABnzbd_nzo_missing"); !errors.Is(err, apierror.ErrAPI) {
+		t.Errorf("PauseJob(missing) error = %v, want ErrAPI", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	files, err := client.GetFiles(ctx, "SABnzbd_nzo_1")
+	if err != nil {
+		t.Fatalf("GetFiles: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("GetFiles returned %d files, want 3", len(files))
+	}
+
+	if err := client.MoveFiles(ctx, "SABnzbd_nzo_1", []string{"SABnzbd_nzf_3"}, "up", 2); err != nil {
+		t.Fatalf("MoveFiles: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "move_nzf_bulk", "name": "up", "nzf_ids": "SABnzbd_nzf_3", "size": "2"})
+	if first := fake.State().Files["SABnzbd_nzo_1"][0].ID; first != "SABnzbd_nzf_3" {
+		t.Errorf("first file = %s, want SABnzbd_nzf_3", first)
+	}
+
+	if err := client.DeleteFiles(ctx, "SABnzbd_nzo_1", []string{"SABnzbd_nzf_1", "SABnzbd_nzf_2"}); err != nil {
+		t.Fatalf("DeleteFiles: %v", err)
+	}
+	assertCall(t, fake, map[string]string{"mode": "queue", "name": "delete_nzf", "value2": "SABnzbd_nzf_1,SABnzbd_nzf_2"})
+	if n := len(fake.State().Files["SABnzbd_nzo_1"]); n != 1 {
+		t.Errorf("%d files left, want 1", n)
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	fake := testutil.NewFakeSabnzbd(t)
+	config := fake.Config()
+	config.APIKey = "wrong"
+	client, err := NewClient(config, models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if _, err := client.GetQueue(context.Background()); !errors.Is(err, apierror.ErrAuth) {
+		t.Errorf("GetQueue error = %v, want ErrAuth", err)
+	}
+}
+
+func TestBasicAuthCredentials(t *testing.T) {
+	fake := testutil.NewFakeSabnzbd(t)
+	config := fake.Config()
+	config.Username = "admin"
+	config.Password = "hunter2"
+	client, err := NewClient(config, models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	u, _ := url.Parse(client.baseURL)
+	if password, _ := u.User.Password(); u.User.Username() != "admin" || password != "hunter2" {
+		t.Errorf("baseURL user = %v, want admin:hunter2", u.User)
+	}
+	if _, err := client.GetVersion(context.Background()); err != nil {
+		t.Errorf("GetVersion: %v", err)
+	}
+}
+
+func TestRetriesOnlyReads(t *testing.T) {
+	var modes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode := r.URL.Query().Get("mode")
+		if name := r.URL.Query().Get("name"); mode == "queue" && name != "" {
+			mode += "/" + name
+		}
+		modes = append(modes, mode)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(models.SabnzbdConfig{URL: server.URL, APIKey: "key"},
+		models.HTTPConfig{Retries: 2, RetryWait: time.Millisecond, RetryMaxWait: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx := context.Background()
+	client.GetQueue(ctx)
+	client.GetFiles(ctx, "SABnzbd_nzo_1")
+	client.AddNZB(ctx, "https://example.com/a.nzb", "tv")
+	client.DeleteFromQueue(ctx, "SABnzbd_nzo_1")
+	client.SetConfigValue(ctx, "misc", "cache_limit", "1G")
+
+	// Reads are retried twice; actions are sent once so they are not
+	// repeated when only the response was lost
+	want := []string{
+		"queue", "queue", "queue",
+		"get_files", "get_files", "get_files",
+		"addurl", "queue/delete", "set_config",
+	}
+	if !slices.Equal(modes, want) {
+		t.Errorf("requests = %q, want %q", modes, want)
+	}
+}
@@ -0,0 +1,81 @@
+package sonarr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Capabilities describes the API differences between Sonarr versions
+type Capabilities struct {
+	// Major is the Sonarr major version
+	Major int
+
+	// APIVersion is the versioned API path segment, empty for the
+	// unversioned v2 API
+	APIVersion string
+
+	// LanguageProfiles is set when series require a language profile.
+	// Sonarr v3 has them; v4 replaced them with per-release languages.
+	LanguageProfiles bool
+
+	// CustomFormats is set when quality profiles carry custom format scores
+	CustomFormats bool
+
+	// MonitorNewItems is set when series accept the monitorNewItems field
+	MonitorNewItems bool
+
+	// AddMonitorOption is set when addOptions accepts a monitor mode
+	AddMonitorOption bool
+}
+
+// capabilitiesFor returns the capabilities of the detected server. When the
+// version is unknown the major version is inferred from the API version.
+func capabilitiesFor(info VersionInfo) Capabilities {
+	major := majorVersion(info.Version)
+	if major == 0 {
+		major = 3
+		if info.APIVersion == "" {
+			major = 2
+		}
+	}
+
+	switch {
+	case major <= 2:
+		return Capabilities{Major: major}
+	case major == 3:
+		return Capabilities{
+			Major:            major,
+			APIVersion:       "v3",
+			LanguageProfiles: true,
+			AddMonitorOption: true,
+		}
+	default:
+		// Sonarr v4 still serves the v3 API
+		return Capabilities{
+			Major:            major,
+			APIVersion:       "v3",
+			CustomFormats:    true,
+			MonitorNewItems:  true,
+			AddMonitorOption: true,
+		}
+	}
+}
+
+// QualityProfilePath returns the path of the quality profile endpoint
+func (c Capabilities) QualityProfilePath() string {
+	if c.Major <= 2 {
+		return "/profile"
+	}
+	return "/qualityprofile"
+}
+
+// majorVersion returns the major component of a version such as
+// "4.0.5.1710", or 0 if it cannot be parsed
+func majorVersion(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,254 @@
+package sonarr
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+func TestCapabilitiesFor(t *testing.T) {
+	tests := []struct {
+		info VersionInfo
+		want Capabilities
+	}{
+		{
+			VersionInfo{APIVersion: "", Version: "2.0.0.5344"},
+			Capabilities{Major: 2},
+		},
+		{
+			VersionInfo{APIVersion: "v3", Version: "3.0.10.1567"},
+			Capabilities{Major: 3, APIVersion: "v3", LanguageProfiles: true, AddMonitorOption: true},
+		},
+		{
+			VersionInfo{APIVersion: "v3", Version: "4.0.5.1710"},
+			Capabilities{Major: 4, APIVersion: "v3", CustomFormats: true, MonitorNewItems: true, AddMonitorOption: true},
+		},
+		{
+			// Unknown versions fall back on the API version
+			VersionInfo{APIVersion: "v3"},
+			Capabilities{Major: 3, APIVersion: "v3", LanguageProfiles: true, AddMonitorOption: true},
+		},
+		{
+			VersionInfo{APIVersion: ""},
+			Capabilities{Major: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := capabilitiesFor(tt.info); got != tt.want {
+			t.Errorf("capabilitiesFor(%+v) = %+v, want %+v", tt.info, got, tt.want)
+		}
+	}
+}
+
+// newVersionClient returns a client connected to a fake server reporting
+// version
+func newVersionClient(t *testing.T, version string) (*Client, *testutil.FakeSonarr) {
+	t.Helper()
+	client, fake := newTestClient(t)
+	fake.Update(func(s *testutil.SonarrState) { s.Status.Version = version })
+	return client, fake
+}
+
+// addStrangerThings adds the first lookup result and returns the request
+// body received by the fake server
+func addStrangerThings(t *testing.T, client *Client, fake *testutil.FakeSonarr) map[string]any {
+	t.Helper()
+	state := fake.State()
+	if _, err := client.AddSeries(context.Background(), state.Lookup[0], state.RootFolders[0], state.Profiles[1]); err != nil {
+		t.Fatalf("AddSeries: %v", err)
+	}
+	requests := fake.State().AddRequests
+	if len(requests) != 1 {
+		t.Fatalf("got %d add requests, want 1", len(requests))
+	}
+	return requests[0]
+}
+
+func TestSonarrV2(t *testing.T) {
+	client, fake := newVersionClient(t, "2.0.0.5344")
+	ctx := context.Background()
+
+	caps := client.Capabilities(ctx)
+	if caps.Major != 2 || caps.APIVersion != "" {
+		t.Errorf("Capabilities = %+v, want the unversioned v2 API", caps)
+	}
+	if _, err := client.GetSeries(ctx); err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	if _, err := client.GetQualityProfiles(ctx); err != nil {
+		t.Fatalf("GetQualityProfiles: %v", err)
+	}
+	if _, err := client.GetEpisodes(ctx, 1); err != nil {
+		t.Fatalf("GetEpisodes: %v", err)
+	}
+
+	body := addStrangerThings(t, client, fake)
+	if body["profileId"] != float64(4) {
+		t.Errorf("profileId = %v, want 4", body["profileId"])
+	}
+	if _, ok := body["seasons"].([]any); !ok {
+		t.Errorf("seasons = %v, want a list", body["seasons"])
+	}
+	if _, ok := body["languageProfileId"]; ok {
+		t.Error("v2 request has a languageProfileId")
+	}
+
+	want := []string{
+		"GET /api/v3/system/status",
+		"GET /api/system/status",
+		"GET /api/series",
+		"GET /api/profile",
+		"GET /api/episode",
+		"POST /api/series",
+	}
+	if got := fake.Requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestSonarrV3(t *testing.T) {
+	client, fake := newVersionClient(t, "3.0.10.1567")
+
+	body := addStrangerThings(t, client, fake)
+	if body["languageProfileId"] != float64(2) {
+		t.Errorf("languageProfileId = %v, want the first language profile", body["languageProfileId"])
+	}
+	if _, ok := body["monitorNewItems"]; ok {
+		t.Error("v3 request has monitorNewItems")
+	}
+	if options := body["addOptions"].(map[string]any); options["monitor"] != "all" {
+		t.Errorf("addOptions = %v, want monitor all", options)
+	}
+}
+
+func TestSonarrV3LanguageProfileFromSeries(t *testing.T) {
+	client, fake := newVersionClient(t, "3.0.10.1567")
+	state := fake.State()
+	series := state.Lookup[0]
+	series.LanguageProfileID = 7
+
+	if _, err := client.AddSeries(context.Background(), series, state.RootFolders[0], state.Profiles[1]); err != nil {
+		t.Fatalf("AddSeries: %v", err)
+	}
+	if id := fake.State().AddRequests[0]["languageProfileId"]; id != float64(7) {
+		t.Errorf("languageProfileId = %v, want 7", id)
+	}
+	if slices.Contains(fake.Requests(), "GET /api/v3/languageprofile") {
+		t.Error("language profiles were requested although the series has one")
+	}
+}
+
+func TestSonarrV3NoLanguageProfiles(t *testing.T) {
+	client, fake := newVersionClient(t, "3.0.10.1567")
+	fake.Update(func(s *testutil.SonarrState) { s.LanguageProfiles = nil })
+	state := fake.State()
+
+	if _, err := client.AddSeries(context.Background(), state.Lookup[0], state.RootFolders[0], state.Profiles[1]); err == nil {
+		t.Error("expected an error without language profiles")
+	}
+}
+
+func TestSonarrV4(t *testing.T) {
+	client, fake := newVersionClient(t, "4.0.5.1710")
+	fake.Update(func(s *testutil.SonarrState) {
+		s.Profiles[1].MinFormatScore = 10
+		s.Profiles[1].FormatItems = []models.ProfileFormatItem{{Format: 1, Name: "x265", Score: -100}}
+	})
+	ctx := context.Background()
+
+	profiles, err := client.GetQualityProfiles(ctx)
+	if err != nil {
+		t.Fatalf("GetQualityProfiles: %v", err)
+	}
+	if len(profiles[1].FormatItems) != 1 || profiles[1].MinFormatScore != 10 {
+		t.Errorf("profile = %+v, want custom format scores", profiles[1])
+	}
+
+	languages, err := client.GetLanguageProfiles(ctx)
+	if err != nil || languages != nil {
+		t.Errorf("GetLanguageProfiles = %v, %v, want none without a request", languages, err)
+	}
+
+	body := addStrangerThings(t, client, fake)
+	if _, ok := body["languageProfileId"]; ok {
+		t.Error("v4 request has a languageProfileId")
+	}
+	if body["monitorNewItems"] != "all" {
+		t.Errorf("monitorNewItems = %v, want all", body["monitorNewItems"])
+	}
+	if slices.Contains(fake.Requests(), "GET /api/v3/languageprofile") {
+		t.Error("language profiles were requested from v4")
+	}
+}
+
+func TestUpdateSeriesOmitsEmptyLanguageProfile(t *testing.T) {
+	client, fake := newVersionClient(t, "4.0.5.1710")
+	series := fake.State().Series[0]
+
+	if _, err := client.UpdateSeries(context.Background(), series); err != nil {
+		t.Fatalf("UpdateSeries: %v", err)
+	}
+	data, _ := json.Marshal(series)
+	if strings.Contains(string(data), "languageProfileId") {
+		t.Errorf("series JSON has languageProfileId: %s", data)
+	}
+}
+
+func TestAddSeriesMonitoring(t *testing.T) {
+	tests := []struct {
+		version   string
+		monitor   string
+		monitored bool
+		options   map[string]any
+	}{
+		{"4.0.5.1710", "future", true, map[string]any{"monitor": "future"}},
+		{"3.0.10.1567", "none", false, map[string]any{"monitor": "none"}},
+		{"2.0.0.5344", "missing", true, map[string]any{"ignoreEpisodesWithFiles": true, "ignoreEpisodesWithoutFiles": false}},
+		{"2.0.0.5344", "none", false, map[string]any{"ignoreEpisodesWithFiles": false, "ignoreEpisodesWithoutFiles": false}},
+	}
+	for _, tt := range tests {
+		client, fake := newVersionClient(t, tt.version)
+		state := fake.State()
+		if _, err := client.AddSeriesMonitoring(context.Background(), state.Lookup[0], state.RootFolders[0], state.Profiles[1], tt.monitor); err != nil {
+			t.Fatalf("%s AddSeriesMonitoring(%s): %v", tt.version, tt.monitor, err)
+		}
+
+		body := fake.State().AddRequests[0]
+		if body["monitored"] != tt.monitored {
+			t.Errorf("%s %s: monitored = %v, want %v", tt.version, tt.monitor, body["monitored"], tt.monitored)
+		}
+		options := body["addOptions"].(map[string]any)
+		for key, want := range tt.options {
+			if options[key] != want {
+				t.Errorf("%s %s: addOptions[%s] = %v, want %v", tt.version, tt.monitor, key, options[key], want)
+			}
+		}
+	}
+}
+
+func TestVersionDetectedAfterCancel(t *testing.T) {
+	client, fake := newVersionClient(t, "2.0.0.5344")
+
+	// A cancelled first call must not leave the client assuming v3
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.GetSeries(ctx)
+
+	if _, err := client.GetSeries(context.Background()); err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	if caps := client.Capabilities(context.Background()); caps.Major != 2 || caps.APIVersion != "" {
+		t.Errorf("Capabilities = %+v, want the unversioned v2 API", caps)
+	}
+
+	want := []string{"GET /api/v3/system/status", "GET /api/system/status", "GET /api/series"}
+	if got := fake.Requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
@@ -2,169 +2,399 @@ package sonarr
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/api/httpclient"
+	"sonarr-sabnzbd-cli/internal/cache"
 	"sonarr-sabnzbd-cli/internal/models"
 )
 
+// serviceName identifies Sonarr in API errors
+const serviceName = "Sonarr"
+
+// versionCacheKey prefixes the cache key of the detected version
+const versionCacheKey = "sonarr-version:"
+
+// detectCooldown is how long a client assumes v3 after the server failed
+// to answer the version probe, rather than probing before every request
+const detectCooldown = time.Minute
+
+// VersionInfo describes the detected Sonarr server
+type VersionInfo struct {
+	APIVersion string `json:"apiVersion"`
+	Version    string `json:"version"`
+	Branch     string `json:"branch"`
+}
+
 // Client represents a Sonarr API client
 type Client struct {
-	baseURL    string
-	apiKey     string
-	client     *http.Client
-	apiVersion string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+
+	detectMu   sync.Mutex
+	detected   bool
+	detectFail time.Time
+	probed     *models.SystemStatus
+	info       VersionInfo
+	caps       Capabilities
+	cache      *cache.Store
+	cacheTTL   time.Duration
+}
+
+// NewClient creates a new Sonarr API client. No request is made until the
+// first API call, which also detects the server version.
+func NewClient(config models.SonarrConfig, httpConfig models.HTTPConfig) (*Client, error) {
+	baseURL, err := httpclient.BaseURL(config.URL, config.Host, config.Port, config.URLBase)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := httpclient.New(httpclient.Options{
+		Timeout:            config.Timeout,
+		CAFile:             config.CAFile,
+		CertFile:           config.CertFile,
+		KeyFile:            config.KeyFile,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		Headers:            config.Headers,
+		Retries:            httpConfig.Retries,
+		RetryWait:          httpConfig.RetryWait,
+		RetryMaxWait:       httpConfig.RetryMaxWait,
+		RateLimit:          httpConfig.RateLimit,
+		RateBurst:          httpConfig.RateBurst,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		baseURL: baseURL.String(),
+		apiKey:  config.APIKey,
+		client:  httpClient,
+	}, nil
+}
+
+// UseVersionCache stores the detected server version on disk for ttl so
+// that later runs skip the detection request
+func (c *Client) UseVersionCache(store *cache.Store, ttl time.Duration) {
+	c.cache = store
+	c.cacheTTL = ttl
+}
+
+// ServerVersion returns the detected API version, Sonarr version and branch
+func (c *Client) ServerVersion(ctx context.Context) VersionInfo {
+	info, _ := c.detect(ctx)
+	return info
+}
+
+// Capabilities returns the API capabilities of the server, detecting its
+// version on first use
+func (c *Client) Capabilities(ctx context.Context) Capabilities {
+	return c.capabilities(ctx)
+}
+
+// capabilities detects the server version and returns its capabilities
+func (c *Client) capabilities(ctx context.Context) Capabilities {
+	_, caps := c.detect(ctx)
+	return caps
+}
+
+// detect detects the server version once and returns it with its
+// capabilities. After a failed detection v3 is assumed for
+// detectCooldown, so that a server that is down is not probed before every
+// request; a detection whose context was cancelled is tried again on the
+// next call.
+func (c *Client) detect(ctx context.Context) (VersionInfo, Capabilities) {
+	c.detectMu.Lock()
+	defer c.detectMu.Unlock()
+	if !c.detected && time.Since(c.detectFail) >= detectCooldown {
+		c.detected = c.detectVersion(ctx)
+		if !c.detected && ctx.Err() == nil {
+			c.detectFail = time.Now()
+		}
+	}
+	return c.info, c.caps
 }
 
-// NewClient creates a new Sonarr API client
-func NewClient(host string, port int, apiKey string, timeout time.Duration) *Client {
-	c := &Client{
-		baseURL: fmt.Sprintf("http://%s:%d", host, port),
-		apiKey:  apiKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		apiVersion: "v3",
+// detectVersion asks the server for its version, trying the v3 API first
+// and falling back to the unversioned v2 API. It reports whether the
+// server answered; otherwise v3 is assumed.
+func (c *Client) detectVersion(ctx context.Context) bool {
+	key := versionCacheKey + c.baseURL
+	if c.cache != nil && c.cache.Get(key, c.cacheTTL, &c.info) && c.info.Version != "" {
+		c.caps = capabilitiesFor(c.info)
+		return true
 	}
 
-	c.detectAPIVersion()
-	return c
+	// Assume v3 until the server says otherwise. The probe is not retried,
+	// as the request that follows is.
+	c.info = VersionInfo{APIVersion: "v3"}
+	ctx = httpclient.NoRetry(ctx)
+	status, code, err := c.probeStatus(ctx, "/api/v3/system/status")
+	if err == nil && code == http.StatusNotFound {
+		c.info.APIVersion = ""
+		status, code, err = c.probeStatus(ctx, "/api/system/status")
+	}
+	if err != nil || code != http.StatusOK {
+		// Don't cache a failed detection
+		c.info.APIVersion = "v3"
+		c.caps = capabilitiesFor(c.info)
+		return false
+	}
+
+	c.info.Version = status.Version
+	c.info.Branch = status.Branch
+	c.caps = capabilitiesFor(c.info)
+	c.probed = status
+	if c.cache != nil {
+		c.cache.Set(key, c.info)
+	}
+	return true
 }
 
-func (c *Client) detectAPIVersion() {
-	req, err := http.NewRequest("GET", c.baseURL+"/api/v3/system/status", nil)
+// probeStatus requests the system status at path without raising API
+// errors, returning the HTTP status code
+func (c *Client) probeStatus(ctx context.Context, path string) (*models.SystemStatus, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
 	if err != nil {
-		c.apiVersion = ""
-		return
+		return nil, 0, err
 	}
 
 	req.Header.Set("X-Api-Key", c.apiKey)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		c.apiVersion = ""
-		return
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
+	var status models.SystemStatus
 	if resp.StatusCode == http.StatusOK {
-		c.apiVersion = "v3"
-	} else {
-		c.apiVersion = ""
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			return nil, 0, err
+		}
 	}
+	return &status, resp.StatusCode, nil
 }
 
-func (c *Client) endpoint(path string) string {
-	if c.apiVersion != "" {
-		return fmt.Sprintf("/api/%s%s", c.apiVersion, path)
+func (c *Client) endpoint(ctx context.Context, path string) string {
+	if apiVersion := c.capabilities(ctx).APIVersion; apiVersion != "" {
+		return fmt.Sprintf("/api/%s%s", apiVersion, path)
 	}
 	return "/api" + path
 }
 
 // GetSystemStatus retrieves the system status
-func (c *Client) GetSystemStatus() (*models.SystemStatus, error) {
+func (c *Client) GetSystemStatus(ctx context.Context) (*models.SystemStatus, error) {
+	// The status fetched to detect the version is used once
+	c.capabilities(ctx)
+	c.detectMu.Lock()
+	probed := c.probed
+	c.probed = nil
+	c.detectMu.Unlock()
+	if probed != nil {
+		return probed, nil
+	}
+
 	var status models.SystemStatus
-	err := c.get(c.endpoint("/system/status"), &status)
+	err := c.get(ctx, c.endpoint(ctx, "/system/status"), &status)
 	return &status, err
 }
 
 // GetSeries retrieves all series
-func (c *Client) GetSeries() ([]models.Series, error) {
+func (c *Client) GetSeries(ctx context.Context) ([]models.Series, error) {
 	var series []models.Series
-	err := c.get(c.endpoint("/series"), &series)
+	err := c.get(ctx, c.endpoint(ctx, "/series"), &series)
 	return series, err
 }
 
 // LookupSeries searches for series by term
-func (c *Client) LookupSeries(term string) ([]models.Series, error) {
+func (c *Client) LookupSeries(ctx context.Context, term string) ([]models.Series, error) {
 	var series []models.Series
 	params := url.Values{}
 	params.Add("term", term)
-	err := c.get(c.endpoint("/series/lookup")+"?"+params.Encode(), &series)
+	err := c.get(ctx, c.endpoint(ctx, "/series/lookup")+"?"+params.Encode(), &series)
 	return series, err
 }
 
 // GetSeriesByID retrieves a specific series by ID
-func (c *Client) GetSeriesByID(id int) (*models.Series, error) {
+func (c *Client) GetSeriesByID(ctx context.Context, id int) (*models.Series, error) {
 	var series models.Series
-	err := c.get(c.endpoint(fmt.Sprintf("/series/%d", id)), &series)
+	err := c.get(ctx, c.endpoint(ctx, fmt.Sprintf("/series/%d", id)), &series)
 	return &series, err
 }
 
 // GetEpisodes retrieves episodes for a series
-func (c *Client) GetEpisodes(seriesID int) ([]models.Episode, error) {
+func (c *Client) GetEpisodes(ctx context.Context, seriesID int) ([]models.Episode, error) {
 	var episodes []models.Episode
 	params := url.Values{}
 	params.Add("seriesId", fmt.Sprintf("%d", seriesID))
-	err := c.get(c.endpoint("/episode")+"?"+params.Encode(), &episodes)
+	err := c.get(ctx, c.endpoint(ctx, "/episode")+"?"+params.Encode(), &episodes)
 	return episodes, err
 }
 
-// GetQualityProfiles retrieves all quality profiles
-func (c *Client) GetQualityProfiles() ([]models.QualityProfile, error) {
+// GetQualityProfiles retrieves all quality profiles. Profiles from Sonarr
+// v4 include custom format scores.
+func (c *Client) GetQualityProfiles(ctx context.Context) ([]models.QualityProfile, error) {
 	var profiles []models.QualityProfile
-	profileEndpoint := c.endpoint("/qualityprofile")
-	err := c.get(profileEndpoint, &profiles)
-	if err != nil && c.apiVersion == "v3" {
-		err = c.get(c.endpoint("/profile"), &profiles)
+	caps := c.capabilities(ctx)
+	err := c.get(ctx, c.endpoint(ctx, caps.QualityProfilePath()), &profiles)
+	if errors.Is(err, apierror.ErrNotFound) && caps.Major == 3 {
+		// Early v3 builds still served profiles at /profile
+		err = c.get(ctx, c.endpoint(ctx, "/profile"), &profiles)
+	}
+	return profiles, err
+}
+
+// GetLanguageProfiles retrieves all language profiles. Servers without
+// language profiles return none.
+func (c *Client) GetLanguageProfiles(ctx context.Context) ([]models.LanguageProfile, error) {
+	if !c.capabilities(ctx).LanguageProfiles {
+		return nil, nil
 	}
+	var profiles []models.LanguageProfile
+	err := c.get(ctx, c.endpoint(ctx, "/languageprofile"), &profiles)
 	return profiles, err
 }
 
 // GetRootFolders retrieves all root folders
-func (c *Client) GetRootFolders() ([]models.RootFolder, error) {
+func (c *Client) GetRootFolders(ctx context.Context) ([]models.RootFolder, error) {
 	var folders []models.RootFolder
-	err := c.get(c.endpoint("/rootfolder"), &folders)
+	err := c.get(ctx, c.endpoint(ctx, "/rootfolder"), &folders)
 	return folders, err
 }
 
-// AddSeries adds a new series
-func (c *Client) AddSeries(series models.Series, rootFolder models.RootFolder, qualityProfile models.QualityProfile) (*models.Series, error) {
+// MonitorMode is a choice of the episodes to monitor in a new series
+type MonitorMode struct {
+	Value       string
+	Description string
+}
+
+// MonitorModes are the episodes a new series can monitor, the default first
+var MonitorModes = []MonitorMode{
+	{"all", "All episodes except specials"},
+	{"future", "Episodes that haven't aired yet"},
+	{"missing", "Episodes without files"},
+	{"existing", "Episodes with files"},
+	{"pilot", "Only the first episode"},
+	{"firstSeason", "All episodes of the first season"},
+	{"none", "No episodes"},
+}
+
+// AddSeries adds a new series monitoring all episodes
+func (c *Client) AddSeries(ctx context.Context, series models.Series, rootFolder models.RootFolder, qualityProfile models.QualityProfile) (*models.Series, error) {
+	return c.AddSeriesMonitoring(ctx, series, rootFolder, qualityProfile, MonitorModes[0].Value)
+}
+
+// AddSeriesMonitoring adds a new series monitoring the episodes of one of
+// MonitorModes, shaping the request for the server version. Sonarr v2 can
+// only monitor all, missing, existing or no episodes; other modes monitor
+// all of them there.
+func (c *Client) AddSeriesMonitoring(ctx context.Context, series models.Series, rootFolder models.RootFolder, qualityProfile models.QualityProfile, monitor string) (*models.Series, error) {
+	caps := c.capabilities(ctx)
+	addOptions := map[string]interface{}{
+		"searchForMissingEpisodes": false,
+	}
 	addSeries := map[string]interface{}{
 		"tvdbId":           series.TVDBID,
 		"title":            series.Title,
 		"qualityProfileId": qualityProfile.ID,
 		"titleSlug":        series.TitleSlug,
 		"rootFolderPath":   rootFolder.Path,
-		"monitored":        true,
+		"monitored":        monitor != "none",
 		"seasonFolder":     true,
-		"addOptions": map[string]interface{}{
-			"searchForMissingEpisodes": false,
-		},
+		"addOptions":       addOptions,
 	}
 
-	if c.apiVersion == "v3" {
-		addSeries["languageProfileId"] = 1
+	switch {
+	case caps.Major <= 2:
+		// v2 names the quality profile profileId and requires the seasons
+		// and images from the lookup
+		addSeries["profileId"] = qualityProfile.ID
+		addSeries["seasons"] = nonNil(series.Seasons)
+		addSeries["images"] = nonNil(series.Images)
+		addOptions["ignoreEpisodesWithFiles"] = monitor == "missing"
+		addOptions["ignoreEpisodesWithoutFiles"] = monitor == "existing"
+	case caps.LanguageProfiles:
+		languageProfileID, err := c.languageProfileID(ctx, series)
+		if err != nil {
+			return nil, err
+		}
+		addSeries["languageProfileId"] = languageProfileID
+	}
+	if caps.MonitorNewItems {
 		addSeries["monitorNewItems"] = "all"
 	}
+	if caps.AddMonitorOption {
+		addOptions["monitor"] = monitor
+	}
 
 	var result models.Series
-	err := c.post(c.endpoint("/series"), addSeries, &result)
+	err := c.post(ctx, c.endpoint(ctx, "/series"), addSeries, &result)
 	return &result, err
 }
 
+// languageProfileID returns the language profile of series, or the first
+// profile configured on the server
+func (c *Client) languageProfileID(ctx context.Context, series models.Series) (int, error) {
+	if series.LanguageProfileID > 0 {
+		return series.LanguageProfileID, nil
+	}
+	profiles, err := c.GetLanguageProfiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get language profiles: %w", err)
+	}
+	if len(profiles) == 0 {
+		return 0, fmt.Errorf("no language profiles configured in Sonarr")
+	}
+	return profiles[0].ID, nil
+}
+
 // UpdateSeries updates an existing series
-func (c *Client) UpdateSeries(series models.Series) (*models.Series, error) {
+func (c *Client) UpdateSeries(ctx context.Context, series models.Series) (*models.Series, error) {
 	var result models.Series
-	err := c.put(c.endpoint(fmt.Sprintf("/series/%d", series.ID)), series, &result)
+	err := c.put(ctx, c.endpoint(ctx, fmt.Sprintf("/series/%d", series.ID)), series, &result)
 	return &result, err
 }
 
 // ImportDownloads scans for downloaded episodes
-func (c *Client) ImportDownloads(path string) error {
+func (c *Client) ImportDownloads(ctx context.Context, path string) error {
 	command := map[string]interface{}{
 		"name": "DownloadedEpisodesScan",
 		"path": path,
 	}
-	return c.post(c.endpoint("/command"), command, nil)
+	return c.post(ctx, c.endpoint(ctx, "/command"), command, nil)
+}
+
+// SearchSeries starts a search for the missing episodes of a series
+func (c *Client) SearchSeries(ctx context.Context, seriesID int) error {
+	command := map[string]interface{}{
+		"name":     "SeriesSearch",
+		"seriesId": seriesID,
+	}
+	return c.post(ctx, c.endpoint(ctx, "/command"), command, nil)
+}
+
+// SearchEpisodes starts a search for the given episodes
+func (c *Client) SearchEpisodes(ctx context.Context, episodeIDs []int) error {
+	command := map[string]interface{}{
+		"name":       "EpisodeSearch",
+		"episodeIds": episodeIDs,
+	}
+	return c.post(ctx, c.endpoint(ctx, "/command"), command, nil)
 }
 
 // get performs a GET request
-func (c *Client) get(endpoint string, result any) error {
-	req, err := http.NewRequest("GET", c.baseURL+endpoint, nil)
+func (c *Client) get(ctx context.Context, endpoint string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+endpoint, nil)
 	if err != nil {
 		return err
 	}
@@ -174,26 +404,26 @@ func (c *Client) get(endpoint string, result any) error {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return apierror.FromTransport(serviceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return apierror.FromResponse(serviceName, resp.StatusCode, body)
 	}
 
 	return json.NewDecoder(resp.Body).Decode(result)
 }
 
 // post performs a POST request
-func (c *Client) post(endpoint string, data any, result any) error {
+func (c *Client) post(ctx context.Context, endpoint string, data any, result any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -204,13 +434,13 @@ func (c *Client) post(endpoint string, data any, result any) error {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return apierror.FromTransport(serviceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return apierror.FromResponse(serviceName, resp.StatusCode, body)
 	}
 
 	if result != nil {
@@ -221,13 +451,13 @@ func (c *Client) post(endpoint string, data any, result any) error {
 }
 
 // put performs a PUT request
-func (c *Client) put(endpoint string, data any, result any) error {
+func (c *Client) put(ctx context.Context, endpoint string, data any, result any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("PUT", c.baseURL+endpoint, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.baseURL+endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -238,13 +468,13 @@ func (c *Client) put(endpoint string, data any, result any) error {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
+		return apierror.FromTransport(serviceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return apierror.FromResponse(serviceName, resp.StatusCode, body)
 	}
 
 	if result != nil {
@@ -253,3 +483,11 @@ func (c *Client) put(endpoint string, data any, result any) error {
 
 	return nil
 }
+
+// nonNil returns an empty slice instead of nil so lists encode as []
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,342 @@
+package sonarr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/api/apierror"
+	"sonarr-sabnzbd-cli/internal/cache"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// newTestClient returns a client connected to a fresh fake server
+func newTestClient(t *testing.T) (*Client, *testutil.FakeSonarr) {
+	t.Helper()
+	fake := testutil.NewFakeSonarr(t)
+	client, err := NewClient(fake.Config(), models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, fake
+}
+
+func TestNewClientMakesNoRequests(t *testing.T) {
+	_, fake := newTestClient(t)
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("NewClient made requests: %v", requests)
+	}
+}
+
+func TestNewClientInvalidURL(t *testing.T) {
+	_, err := NewClient(models.SonarrConfig{URL: "ftp://example.com"}, models.HTTPConfig{})
+	if err == nil {
+		t.Fatal("expected an error for a non-HTTP URL")
+	}
+}
+
+func TestGetSystemStatus(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	status, err := client.GetSystemStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetSystemStatus: %v", err)
+	}
+	if status.Version != "3.0.10.1567" {
+		t.Errorf("Version = %q, want 3.0.10.1567", status.Version)
+	}
+
+	// The status fetched to detect the version is reused once
+	want := []string{"GET /api/v3/system/status"}
+	if got := fake.Requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+	client.GetSystemStatus(context.Background())
+	if got := fake.Requests(); len(got) != 2 {
+		t.Errorf("requests = %v, want a second status request", got)
+	}
+}
+
+func TestGetSeries(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	series, err := client.GetSeries(context.Background())
+	if err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	if len(series) != 2 || series[0].Title != "Breaking Bad" {
+		t.Errorf("GetSeries = %+v, want Breaking Bad and The Office", series)
+	}
+}
+
+func TestLookupSeries(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	results, err := client.LookupSeries(context.Background(), "stranger")
+	if err != nil {
+		t.Fatalf("LookupSeries: %v", err)
+	}
+	if len(results) != 2 || results[0].TVDBID != 305288 {
+		t.Errorf("LookupSeries = %+v, want two Stranger Things results", results)
+	}
+}
+
+func TestGetSeriesByID(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	series, err := client.GetSeriesByID(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetSeriesByID: %v", err)
+	}
+	if series.Title != "The Office (US)" {
+		t.Errorf("Title = %q, want The Office (US)", series.Title)
+	}
+
+	_, err = client.GetSeriesByID(context.Background(), 999)
+	if !errors.Is(err, apierror.ErrNotFound) {
+		t.Errorf("GetSeriesByID(999) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetEpisodes(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	episodes, err := client.GetEpisodes(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetEpisodes: %v", err)
+	}
+	if len(episodes) != 2 || episodes[0].Title != "Pilot" {
+		t.Errorf("GetEpisodes = %+v, want Pilot first", episodes)
+	}
+}
+
+func TestGetQualityProfiles(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	profiles, err := client.GetQualityProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("GetQualityProfiles: %v", err)
+	}
+	if len(profiles) != 2 || profiles[1].Name != "HD-1080p" {
+		t.Errorf("GetQualityProfiles = %+v, want Any and HD-1080p", profiles)
+	}
+}
+
+func TestGetQualityProfilesLegacyFallback(t *testing.T) {
+	client, fake := newTestClient(t)
+	fake.Update(func(s *testutil.SonarrState) { s.LegacyProfiles = true })
+
+	profiles, err := client.GetQualityProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("GetQualityProfiles: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Errorf("got %d profiles, want 2", len(profiles))
+	}
+	if !slices.Contains(fake.Requests(), "GET /api/v3/profile") {
+		t.Errorf("requests = %v, want a fallback to /profile", fake.Requests())
+	}
+}
+
+func TestGetRootFolders(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	folders, err := client.GetRootFolders(context.Background())
+	if err != nil {
+		t.Fatalf("GetRootFolders: %v", err)
+	}
+	if len(folders) != 1 || folders[0].Path != "/tv" {
+		t.Errorf("GetRootFolders = %+v, want /tv", folders)
+	}
+}
+
+func TestAddSeries(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+	state := fake.State()
+
+	added, err := client.AddSeries(ctx, state.Lookup[0], state.RootFolders[0], state.Profiles[1])
+	if err != nil {
+		t.Fatalf("AddSeries: %v", err)
+	}
+	if added.ID == 0 || added.Title != "Stranger Things" {
+		t.Errorf("AddSeries = %+v, want Stranger Things with an ID", added)
+	}
+	if added.QualityProfileID != 4 {
+		t.Errorf("QualityProfileID = %d, want 4", added.QualityProfileID)
+	}
+
+	_, err = client.AddSeries(ctx, state.Lookup[0], state.RootFolders[0], state.Profiles[1])
+	if !errors.Is(err, apierror.ErrConflict) {
+		t.Errorf("second AddSeries error = %v, want ErrConflict", err)
+	}
+}
+
+func TestAddSeriesValidationError(t *testing.T) {
+	client, fake := newTestClient(t)
+	state := fake.State()
+
+	_, err := client.AddSeries(context.Background(), state.Lookup[0], state.RootFolders[0], models.QualityProfile{})
+	if !errors.Is(err, apierror.ErrValidation) {
+		t.Fatalf("AddSeries error = %v, want ErrValidation", err)
+	}
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) || len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "QualityProfileId" {
+		t.Errorf("field errors = %+v, want QualityProfileId", apiErr)
+	}
+}
+
+func TestUpdateSeries(t *testing.T) {
+	client, fake := newTestClient(t)
+	series := fake.State().Series[1]
+	series.Monitored = true
+
+	updated, err := client.UpdateSeries(context.Background(), series)
+	if err != nil {
+		t.Fatalf("UpdateSeries: %v", err)
+	}
+	if !updated.Monitored || !fake.State().Series[1].Monitored {
+		t.Error("series was not marked as monitored")
+	}
+}
+
+func TestImportDownloads(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	if err := client.ImportDownloads(context.Background(), "/downloads/complete"); err != nil {
+		t.Fatalf("ImportDownloads: %v", err)
+	}
+	commands := fake.State().Commands
+	if len(commands) != 1 || commands[0]["name"] != "DownloadedEpisodesScan" || commands[0]["path"] != "/downloads/complete" {
+		t.Errorf("commands = %+v, want one DownloadedEpisodesScan", commands)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.SearchSeries(ctx, 1); err != nil {
+		t.Fatalf("SearchSeries: %v", err)
+	}
+	if err := client.SearchEpisodes(ctx, []int{11, 12}); err != nil {
+		t.Fatalf("SearchEpisodes: %v", err)
+	}
+	commands := fake.State().Commands
+	if len(commands) != 2 || commands[0]["name"] != "SeriesSearch" || commands[0]["seriesId"] != 1.0 {
+		t.Fatalf("commands = %+v, want SeriesSearch then EpisodeSearch", commands)
+	}
+	if ids, _ := commands[1]["episodeIds"].([]any); commands[1]["name"] != "EpisodeSearch" || len(ids) != 2 {
+		t.Errorf("command = %+v, want an EpisodeSearch for 2 episodes", commands[1])
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	fake := testutil.NewFakeSonarr(t)
+	config := fake.Config()
+	config.APIKey = "wrong"
+	client, err := NewClient(config, models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = client.GetSeries(context.Background())
+	if !errors.Is(err, apierror.ErrAuth) {
+		t.Errorf("GetSeries error = %v, want ErrAuth", err)
+	}
+}
+
+func TestServerError(t *testing.T) {
+	client, fake := newTestClient(t)
+	fake.Update(func(s *testutil.SonarrState) {
+		s.Failures = map[string]int{"/api/v3/series": http.StatusInternalServerError}
+	})
+
+	_, err := client.GetSeries(context.Background())
+	if !errors.Is(err, apierror.ErrServer) {
+		t.Errorf("GetSeries error = %v, want ErrServer", err)
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	info := client.ServerVersion(context.Background())
+	want := VersionInfo{APIVersion: "v3", Version: "3.0.10.1567", Branch: "main"}
+	if info != want {
+		t.Errorf("ServerVersion = %+v, want %+v", info, want)
+	}
+}
+
+func TestVersionDetectedOnce(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	client.GetSeries(ctx)
+	client.GetSeries(ctx)
+
+	count := 0
+	for _, request := range fake.Requests() {
+		if request == "GET /api/v3/system/status" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("system/status requested %d times, want 1", count)
+	}
+}
+
+func TestVersionDetectionCooldown(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(models.SonarrConfig{URL: server.URL, Timeout: 5 * time.Second},
+		models.HTTPConfig{Retries: 1, RetryWait: time.Millisecond, RetryMaxWait: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.GetSeries(context.Background())
+	client.GetSeries(context.Background())
+
+	// The probe is neither retried nor repeated while the server is down
+	want := []string{"/api/v3/system/status", "/api/v3/series", "/api/v3/series", "/api/v3/series", "/api/v3/series"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestVersionCache(t *testing.T) {
+	fake := testutil.NewFakeSonarr(t)
+	store := cache.New(t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		client, err := NewClient(fake.Config(), models.HTTPConfig{})
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		client.UseVersionCache(store, time.Hour)
+		if _, err := client.GetSeries(context.Background()); err != nil {
+			t.Fatalf("GetSeries: %v", err)
+		}
+	}
+
+	want := []string{"GET /api/v3/system/status", "GET /api/v3/series", "GET /api/v3/series"}
+	if got := fake.Requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package sonarr
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/testutil"
+)
+
+// TestReplay runs the client against golden responses in testdata/replay.
+// Set SONCLI_RECORD_SONARR and SONCLI_SONARR_API_KEY to re-record them from
+// a real server.
+func TestReplay(t *testing.T) {
+	upstream := os.Getenv("SONCLI_RECORD_SONARR")
+	apiKey := testutil.SonarrAPIKey
+	if upstream != "" {
+		apiKey = os.Getenv("SONCLI_SONARR_API_KEY")
+	}
+
+	server := testutil.NewReplayServer(t, "testdata/replay", upstream, apiKey)
+	client, err := NewClient(models.SonarrConfig{URL: server.URL, APIKey: apiKey}, models.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := context.Background()
+
+	status, err := client.GetSystemStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetSystemStatus: %v", err)
+	}
+	if status.Version == "" {
+		t.Error("GetSystemStatus returned no version")
+	}
+
+	if _, err := client.GetSeries(ctx); err != nil {
+		t.Errorf("GetSeries: %v", err)
+	}
+
+	profiles, err := client.GetQualityProfiles(ctx)
+	if err != nil {
+		t.Errorf("GetQualityProfiles: %v", err)
+	} else if len(profiles) == 0 {
+		t.Error("GetQualityProfiles returned no profiles")
+	}
+
+	if _, err := client.GetRootFolders(ctx); err != nil {
+		t.Errorf("GetRootFolders: %v", err)
+	}
+}
@@ -46,8 +46,10 @@ func ConvertImageToASCII(url string, config *Config) (string, error) {
 		config = DefaultConfig()
 	}
 
-	// Try to get from cache first
+	// Try to get from cache first. Posters are drawn at several sizes, so
+	// the size is part of the key.
 	cacheKey := strings.ReplaceAll(strings.ReplaceAll(url, "/", "_"), ":", "_")
+	cacheKey += fmt.Sprintf("_%dx%d_%t", config.Width, config.Height, config.Colored)
 	cachePath := filepath.Join(config.CacheDir, cacheKey+".ascii")
 
 	if ascii, err := readFromCache(cachePath); err == nil {
@@ -96,15 +98,10 @@ func ConvertImageToASCII(url string, config *Config) (string, error) {
 		ascii = stripANSI(ascii)
 	}
 
-	// Format the ASCII art into proper lines for 8x8 display
-	lines := make([]string, 0, config.Height)
-	for i := 0; i < config.Height && i*config.Width < len(ascii); i++ {
-		start := i * config.Width
-		end := start + config.Width
-		if end > len(ascii) {
-			end = len(ascii)
-		}
-		lines = append(lines, ascii[start:end])
+	// The converter ends each row with a newline
+	lines := strings.Split(strings.TrimSuffix(ascii, "\n"), "\n")
+	if config.Height > 0 && len(lines) > config.Height {
+		lines = lines[:config.Height]
 	}
 	formattedASCII := strings.Join(lines, "\n")
 
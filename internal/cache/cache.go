@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store is a directory of JSON values that expire after a TTL
+type Store struct {
+	dir string
+}
+
+// entry is the on-disk format of a cached value
+type entry struct {
+	Key     string          `json:"key"`
+	Created time.Time       `json:"created"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// New creates a store in the given directory
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Default returns the store in the user's cache directory
+func Default() (*Store, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(filepath.Join(dir, "sonarr-sabnzbd-cli")), nil
+}
+
+// Get loads the value stored under key into v. It reports false when the
+// value is missing, unreadable or older than ttl.
+func (s *Store) Get(key string, ttl time.Duration, v any) bool {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return false
+	}
+	if ttl > 0 && time.Since(e.Created) > ttl {
+		return false
+	}
+
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Set stores v under key
+func (s *Store) Set(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry{Key: key, Created: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial value
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete removes the value stored under key
+func (s *Store) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// path returns the file used for a key
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".json")
+}
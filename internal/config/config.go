@@ -1,84 +1,305 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/secrets"
 )
 
-// LoadConfig loads the configuration from file and environment variables
+// LoadConfig loads the configuration from file, environment variables and
+// flags, migrating an older file to the current version first. The file is
+// never rewritten otherwise. A configuration that fails validation is
+// returned together with a *ValidationError, so that commands which
+// inspect or repair the file can still run.
 func LoadConfig() (*models.Config, error) {
-	// Set default values
-	viper.SetDefault("sonarr.host", "localhost")
-	viper.SetDefault("sonarr.port", 8989)
-	viper.SetDefault("sonarr.api_key", "")
-	viper.SetDefault("sonarr.timeout", 30*time.Second)
-	viper.SetDefault("sabnzbd.host", "localhost")
-	viper.SetDefault("sabnzbd.port", 8080)
-	viper.SetDefault("sabnzbd.api_key", "")
-	viper.SetDefault("sabnzbd.username", "")
-	viper.SetDefault("sabnzbd.password", "")
-	viper.SetDefault("sabnzbd.timeout", 30*time.Second)
-	viper.SetDefault("ui.colors", true)
-	viper.SetDefault("ui.max_results", 10)
+	// Start afresh, since --config and the bound flags change between runs
+	viper.Reset()
+	loaded.raw, loaded.file, loaded.doc = nil, nil, nil
+	setDefaults(viper.GetViper())
 
 	// Set config file name and paths
-	viper.SetConfigName("config")
+	if path := overrideFile(); path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		configDir, err := getConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get config directory: %w", err)
+		}
+		viper.SetConfigName("config")
+		viper.AddConfigPath(configDir)
+		viper.AddConfigPath(".")
+	}
 	viper.SetConfigType("yaml")
 
-	// Add config paths
-	configDir, err := getConfigDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	// Environment variables and flags override the file; see EnvVar
+	if err := bindOverrides(viper.GetViper()); err != nil {
+		return nil, fmt.Errorf("failed to bind overrides: %w", err)
 	}
 
-	viper.AddConfigPath(configDir)
-	viper.AddConfigPath(".")
-
-	// Enable environment variable override
-	viper.SetEnvPrefix("SONARR_CLI")
-	viper.AutomaticEnv()
-
-	// Read config file
+	// Read config file. A missing file is not an error: the defaults are
+	// used and the file is only written by setup or an explicit change.
+	// A file given by --config must exist.
+	path := ""
+	file := models.Config{}
 	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found, create default
-			return createDefaultConfig(configDir)
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := defaults().Unmarshal(&file); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+	} else {
+		path = viper.ConfigFileUsed()
+		data, err := migrateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &loaded.raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		loaded.doc = &doc
+
+		// The file alone, for SaveConfig to restore overridden values
+		v := defaults()
+		if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := v.Unmarshal(&file); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 		}
-		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
+	loaded.file = &file
 
 	var config models.Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Check if we have the correct values, if not, recreate config
-	if config.Sonarr.Host == "localhost" || config.Sonarr.APIKey == "" {
-		return createDefaultConfig(configDir)
+	if problems := Validate(&config, loaded.raw); len(problems) > 0 {
+		return &config, &ValidationError{File: path, Problems: problems}
 	}
-
 	return &config, nil
 }
 
-// SaveConfig saves the configuration to file
+// defaults returns a new viper holding only the default values
+func defaults() *viper.Viper {
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType("yaml")
+	return v
+}
+
+// setDefaults sets the default value of every key
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("version", CurrentVersion)
+	v.SetDefault("sonarr.host", "localhost")
+	v.SetDefault("sonarr.port", 8989)
+	v.SetDefault("sonarr.api_key", "")
+	v.SetDefault("sonarr.timeout", 30*time.Second)
+	v.SetDefault("sonarr.version_cache_ttl", 24*time.Hour)
+	v.SetDefault("sabnzbd.host", "localhost")
+	v.SetDefault("sabnzbd.port", 8080)
+	v.SetDefault("sabnzbd.api_key", "")
+	v.SetDefault("sabnzbd.username", "")
+	v.SetDefault("sabnzbd.password", "")
+	v.SetDefault("sabnzbd.timeout", 30*time.Second)
+	v.SetDefault("ui.colors", true)
+	v.SetDefault("ui.max_results", 10)
+	v.SetDefault("http.retries", 3)
+	v.SetDefault("http.retry_wait", 500*time.Millisecond)
+	v.SetDefault("http.retry_max_wait", 10*time.Second)
+	v.SetDefault("http.rate_limit", 0)
+	v.SetDefault("http.rate_burst", 5)
+}
+
+// ValidateData checks the contents of a configuration file without loading
+// it, as config edit does before replacing the file
+func ValidateData(data []byte) error {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	v := defaults()
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	var config models.Config
+	if err := v.Unmarshal(&config); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	if problems := Validate(&config, raw); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// SaveConfig saves the configuration to the file it was loaded from,
+// readable only by the owner. Only the values that changed since loading
+// are written, so the rest of the file and its comments stay as they are;
+// a new file gets the whole configuration. Secrets resolved from commands,
+// files, the environment or the secret store are not written, and neither
+// are values given by flags or environment variables.
 func SaveConfig(config *models.Config) error {
-	configDir, err := getConfigDir()
+	configPath, err := Path()
 	if err != nil {
 		return fmt.Errorf("failed to get config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.yaml")
+	saved := restoreOverrides(stripExternal(*config))
+	saved.Version = CurrentVersion
+	var data []byte
+	if loaded.doc != nil && loaded.file != nil {
+		data, err = updateDocument(loaded.doc, loaded.file, &saved)
+	} else {
+		data, err = encodeYAML(&saved)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return secrets.WriteFile(configPath, data)
+}
+
+// updateDocument changes the values in doc, the parsed configuration file,
+// that differ between the configurations before and after, and returns the
+// file
+func updateDocument(doc *yaml.Node, before, after *models.Config) ([]byte, error) {
+	var old, changed yaml.Node
+	if err := old.Encode(before); err != nil {
+		return nil, err
+	}
+	if err := changed.Encode(after); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		// Not a configuration file at all; write the configuration instead
+		return encodeYAML(after)
+	}
+	mergeMapping(doc.Content[0], &old, &changed)
+	return encodeYAML(doc)
+}
+
+// mergeMapping applies the differences between the mappings old and
+// changed to file. Values are replaced in place, keeping their comments;
+// keys that are new are appended and keys that are gone are removed. Keys
+// are matched in the file ignoring case, as the configuration is loaded.
+func mergeMapping(file, old, changed *yaml.Node) {
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		if key := old.Content[i].Value; mappingValue(changed, key) == nil {
+			removeKey(file, key)
+		}
+	}
+
+	for i := 0; i+1 < len(changed.Content); i += 2 {
+		key, value := changed.Content[i], changed.Content[i+1]
+		before := mappingValue(old, key.Value)
+		if before != nil && sameNode(before, value) {
+			continue
+		}
+
+		existing := fileValue(file, key.Value)
+		if value.Kind == yaml.MappingNode && (existing == nil || existing.Kind == yaml.MappingNode) {
+			// Sections are merged, so a new section only holds what changed
+			if before == nil || before.Kind != yaml.MappingNode {
+				before = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			if existing == nil {
+				existing = &yaml.Node{Kind: yaml.MappingNode}
+				file.Content = append(file.Content, key, existing)
+			}
+			mergeMapping(existing, before, value)
+			if len(existing.Content) == 0 {
+				removeKey(file, key.Value)
+			}
+			continue
+		}
+
+		if existing == nil {
+			file.Content = append(file.Content, key, value)
+			continue
+		}
+		head, line, foot := existing.HeadComment, existing.LineComment, existing.FootComment
+		*existing = *value
+		existing.HeadComment, existing.LineComment, existing.FootComment = head, line, foot
+	}
+}
+
+// fileValue returns the value node for key in a mapping node of the file,
+// ignoring case
+func fileValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeKey removes key and its value from a mapping node of the file,
+// ignoring case
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// sameNode reports whether two nodes hold the same value
+func sameNode(a, b *yaml.Node) bool {
+	x, errA := yaml.Marshal(a)
+	y, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(x, y)
+}
 
-	viper.Set("sonarr", config.Sonarr)
-	viper.Set("sabnzbd", config.Sabnzbd)
-	viper.Set("ui", config.UI)
+// encodeYAML encodes v with the two-space indentation of hand-written
+// configuration files
+func encodeYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
 
-	return viper.WriteConfigAs(configPath)
+// Path returns the path of the configuration file that was loaded, or
+// where it is created if there is none
+func Path() (string, error) {
+	if path := overrideFile(); path != "" {
+		return path, nil
+	}
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.yaml"), nil
 }
 
 // getConfigDir returns the configuration directory path
@@ -91,41 +312,9 @@ func getConfigDir() (string, error) {
 	configDir := filepath.Join(homeDir, ".config", "sonarr-sabnzbd-cli")
 
 	// Create directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return "", err
 	}
 
 	return configDir, nil
 }
-
-// createDefaultConfig creates a default configuration file
-func createDefaultConfig(_ string) (*models.Config, error) {
-	// Create config with placeholder values - user must configure
-	config := &models.Config{
-		Sonarr: models.SonarrConfig{
-			Host:    "localhost",
-			Port:    8989,
-			APIKey:  "", // User must set this
-			Timeout: 30 * time.Second,
-		},
-		Sabnzbd: models.SabnzbdConfig{
-			Host:     "localhost",
-			Port:     8080,
-			APIKey:   "", // User must set this
-			Username: "",
-			Password: "",
-			Timeout:  30 * time.Second,
-		},
-		UI: models.UIConfig{
-			Colors:     true,
-			MaxResults: 10,
-		},
-	}
-
-	// Save the default config
-	if err := SaveConfig(config); err != nil {
-		return nil, fmt.Errorf("failed to save default config: %w", err)
-	}
-
-	return config, nil
-}
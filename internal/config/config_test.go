@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfig writes a configuration file in a fresh home directory and
+// returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	viper.Reset()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, ".config", "sonarr-sabnzbd-cli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigKeepsLocalhost(t *testing.T) {
+	content := "version: 2\nsonarr:\n  host: localhost\n  port: 8989\n  api_key: abc123\n"
+	path := writeConfig(t, content)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Sonarr.Host != "localhost" || config.Sonarr.APIKey != "abc123" {
+		t.Errorf("Sonarr = %+v, want localhost with abc123", config.Sonarr)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("config was rewritten:\n%s", data)
+	}
+}
+
+func TestLoadConfigMigratesVersion1(t *testing.T) {
+	content := "# My servers\nsonarr:\n  host: media\n  apikey: abc123 # from Settings > General\n  timeout: 30\n"
+	path := writeConfig(t, content)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Version != CurrentVersion || config.Sonarr.APIKey != "abc123" || config.Sonarr.Timeout != 30*time.Second {
+		t.Errorf("config = %+v, want api_key abc123 and a 30s timeout", config)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"version: 2", "# My servers", "api_key: abc123 # from Settings > General", "timeout: 30s"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("migrated config does not contain %q:\n%s", want, data)
+		}
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != content {
+		t.Errorf("backup = %q, want the original file", backup)
+	}
+}
+
+func TestLoadConfigMigratesUnwritableFile(t *testing.T) {
+	content := "sonarr:\n  host: media\n  apikey: abc123\n"
+	path := writeConfig(t, content)
+	// A directory in the way of the backup makes saving fail, which file
+	// permissions would not when running as root
+	if err := os.Mkdir(path+".v1.bak", 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.Sonarr.APIKey != "abc123" {
+		t.Errorf("Sonarr = %+v, want the API key migrated in memory", config.Sonarr)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("config was rewritten without a backup:\n%s", data)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	writeConfig(t, `version: 2
+sonarr:
+  host: media
+  port: 0
+  api_key: abc123
+  api_kye: typo
+sabnzbd:
+  url: "ftp://media"
+  timeout: 30
+current_instance: 4k
+`)
+
+	config, err := LoadConfig()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("LoadConfig() error = %v, want a ValidationError", err)
+	}
+	if config == nil {
+		t.Fatal("LoadConfig() returned no configuration with the ValidationError")
+	}
+
+	for _, want := range []string{
+		"sonarr.api_kye: unknown key",
+		"sonarr.port: must be between 1 and 65535, got 0",
+		"sabnzbd.url: must be an http or https URL",
+		"sabnzbd.timeout: 30ns is too short",
+		"current_instance: unknown instance '4k'",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestSaveConfigKeepsFile(t *testing.T) {
+	content := `# My servers
+version: 2
+sonarr:
+  host: media # the NAS
+  api_key: abc123
+  headers:
+    X-Token: one
+    X-Other: two
+ui:
+  # Fewer results fit my terminal
+  max_results: 5
+`
+	path := writeConfig(t, content)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(config, "sonarr.host", "nas"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(config, "http.retries", "5"); err != nil {
+		t.Fatal(err)
+	}
+	delete(config.Sonarr.Headers, "x-other")
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# My servers
+version: 2
+sonarr:
+  host: nas # the NAS
+  api_key: abc123
+  headers:
+    X-Token: one
+ui:
+  # Fewer results fit my terminal
+  max_results: 5
+http:
+  retries: 5
+`
+	if string(data) != want {
+		t.Errorf("saved config:\n%s\nwant only the changes:\n%s", data, want)
+	}
+}
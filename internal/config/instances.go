@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// DefaultInstance names the instance made of the top-level sonarr and
+// sabnzbd settings
+const DefaultInstance = "default"
+
+// InstanceName returns the instance selected by name, falling back to
+// current_instance and then the default instance. Names are case
+// insensitive, since the configuration file's keys are.
+func InstanceName(config *models.Config, name string) string {
+	if name == "" {
+		name = config.CurrentInstance
+	}
+	if name == "" {
+		return DefaultInstance
+	}
+	return strings.ToLower(name)
+}
+
+// InstanceNames returns the default instance followed by the named
+// instances in alphabetical order
+func InstanceNames(config *models.Config) []string {
+	names := make([]string, 0, len(config.Instances))
+	for name := range config.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultInstance}, names...)
+}
+
+// SelectInstance returns a copy of config whose sonarr and sabnzbd
+// settings are those of the named instance. Flags and environment
+// variables such as --sonarr-url override the selected instance as they
+// override the top-level settings.
+func SelectInstance(config *models.Config, name string) (*models.Config, error) {
+	name = InstanceName(config, name)
+	selected := *config
+	if name == DefaultInstance {
+		return &selected, nil
+	}
+
+	instance, ok := config.Instances[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown instance '%s': expected one of %s", name, strings.Join(InstanceNames(config), ", "))
+	}
+
+	if instance.Sonarr != nil {
+		sonarr := config.Sonarr
+		sonarr.APIKey, sonarr.APIKeyCmd, sonarr.APIKeyFile = "", "", ""
+		sonarr.External = nil
+		overlay(&sonarr, instance.Sonarr, "instances."+name+".sonarr.")
+		sonarr.Instance = name
+		selected.Sonarr = sonarr
+	}
+	if instance.Sabnzbd != nil {
+		sabnzbd := config.Sabnzbd
+		sabnzbd.APIKey, sabnzbd.APIKeyCmd, sabnzbd.APIKeyFile = "", "", ""
+		sabnzbd.Username, sabnzbd.Password, sabnzbd.PasswordCmd, sabnzbd.PasswordFile = "", "", "", ""
+		sabnzbd.External = nil
+		overlay(&sabnzbd, instance.Sabnzbd, "instances."+name+".sabnzbd.")
+		sabnzbd.Instance = name
+		selected.Sabnzbd = sabnzbd
+	}
+
+	// The top-level settings already hold the overrides
+	for _, key := range Keys() {
+		// Secrets are overridden when they are resolved; see secretOverride
+		if slices.Contains(SecretNames, key) || !overridden(key) {
+			continue
+		}
+		if strings.HasPrefix(key, "sonarr.") || strings.HasPrefix(key, "sabnzbd.") {
+			keyField(&selected, key).Set(keyField(config, key))
+		}
+	}
+	return &selected, nil
+}
+
+// SonarrInstances returns the instances with their own Sonarr server. The
+// default instance is left out when named instances exist and it has no
+// Sonarr API key, as happens when every Sonarr server is a named instance.
+func SonarrInstances(config *models.Config) []string {
+	var names []string
+	for _, name := range InstanceNames(config) {
+		if name == DefaultInstance {
+			if len(config.Instances) == 0 || hasTopLevelSecret(config, SonarrAPIKey, config.Sonarr.APIKey, config.Sonarr.APIKeyCmd, config.Sonarr.APIKeyFile) {
+				names = append(names, name)
+			}
+		} else if config.Instances[name].Sonarr != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SabnzbdInstances returns the instances with their own Sabnzbd server, in
+// the same way as SonarrInstances
+func SabnzbdInstances(config *models.Config) []string {
+	var names []string
+	for _, name := range InstanceNames(config) {
+		if name == DefaultInstance {
+			if len(config.Instances) == 0 || hasTopLevelSecret(config, SabnzbdAPIKey, config.Sabnzbd.APIKey, config.Sabnzbd.APIKeyCmd, config.Sabnzbd.APIKeyFile) {
+				names = append(names, name)
+			}
+		} else if config.Instances[name].Sabnzbd != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// hasTopLevelSecret reports whether a top-level secret is set, looking in
+// the secret store only when it has already been created
+func hasTopLevelSecret(config *models.Config, name, value, command, file string) bool {
+	if override, _ := secretOverride(name); value != "" || command != "" || file != "" || override != "" {
+		return true
+	}
+	if !storeExists(config.Secrets) {
+		return false
+	}
+	store, err := OpenStore(config.Secrets)
+	if err != nil {
+		// Let the error surface when the secret is resolved
+		return true
+	}
+	_, ok := store.Get(name)
+	return ok
+}
+
+// overlay copies every field of src that is set over dst, which must be
+// pointers to structs of the same type. A field is set when it is not
+// zero, or when the configuration file gives it under prefix, so that an
+// instance can turn off a setting such as insecure_skip_verify.
+func overlay(dst, src any, prefix string) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := range s.NumField() {
+		tag, _, _ := strings.Cut(s.Type().Field(i).Tag.Get("mapstructure"), ",")
+		if field := s.Field(i); !field.IsZero() || (tag != "" && tag != "-" && inFile(loaded.raw, prefix+tag)) {
+			d.Field(i).Set(field)
+		}
+	}
+}
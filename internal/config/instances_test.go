@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+func TestSelectInstance(t *testing.T) {
+	config := &models.Config{
+		Sonarr:  models.SonarrConfig{Host: "media", Port: 8989, APIKey: "hd-key", Timeout: 30 * time.Second},
+		Sabnzbd: models.SabnzbdConfig{Host: "media", Port: 8080, APIKey: "sab-key"},
+		Instances: map[string]models.InstanceConfig{
+			"4k":    {Sonarr: &models.SonarrConfig{Port: 8990, APIKey: "4k-key"}},
+			"anime": {Sonarr: &models.SonarrConfig{Port: 8991}},
+		},
+		CurrentInstance: "4k",
+	}
+
+	selected, err := SelectInstance(config, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := selected.Sonarr; got.Host != "media" || got.Port != 8990 || got.APIKey != "4k-key" || got.Timeout != 30*time.Second || got.Instance != "4k" {
+		t.Errorf("4k Sonarr = %+v, want the top-level settings with port 8990 and 4k-key", got)
+	}
+	if selected.Sabnzbd.APIKey != "sab-key" || selected.Sabnzbd.Instance != "" {
+		t.Errorf("4k Sabnzbd = %+v, want the shared top-level settings", selected.Sabnzbd)
+	}
+
+	// Secrets are never inherited from the top-level settings
+	selected, err = SelectInstance(config, "ANIME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected.Sonarr.APIKey != "" {
+		t.Errorf("anime inherited API key %q", selected.Sonarr.APIKey)
+	}
+
+	if _, err := SelectInstance(config, "missing"); err == nil {
+		t.Error("selecting an unknown instance succeeded")
+	}
+
+	if got := SonarrInstances(config); len(got) != 3 {
+		t.Errorf("SonarrInstances() = %v, want default, 4k and anime", got)
+	}
+	if got := SabnzbdInstances(config); len(got) != 1 || got[0] != DefaultInstance {
+		t.Errorf("SabnzbdInstances() = %v, want only default", got)
+	}
+	if got := AllSecretNames(config); len(got) != 5 || got[3] != "4k.sonarr.api_key" {
+		t.Errorf("AllSecretNames() = %v", got)
+	}
+}
+
+func TestSelectInstanceOverrides(t *testing.T) {
+	writeConfig(t, `version: 2
+sonarr:
+  host: media
+  api_key: hd-key
+  insecure_skip_verify: true
+instances:
+  4k:
+    sonarr:
+      port: 8990
+      api_key: 4k-key
+      insecure_skip_verify: false
+`)
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance turns off a setting of the top level
+	selected, err := SelectInstance(config, "4k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected.Sonarr.InsecureSkipVerify || selected.Sonarr.Port != 8990 {
+		t.Errorf("4k Sonarr = %+v, want port 8990 with verification", selected.Sonarr)
+	}
+
+	// Flags and the environment override the instance
+	t.Setenv(EnvVar("sonarr.url"), "http://other:8989")
+	t.Setenv(SecretEnv(SonarrAPIKey), "env-key")
+	if config, err = LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if selected, err = SelectInstance(config, "4k"); err != nil {
+		t.Fatal(err)
+	}
+	if selected.Sonarr.URL != "http://other:8989" {
+		t.Errorf("4k Sonarr URL = %q, want the environment's", selected.Sonarr.URL)
+	}
+	if err := ResolveSonarr(&selected.Sonarr, selected.Secrets); err != nil {
+		t.Fatal(err)
+	}
+	if selected.Sonarr.APIKey != "env-key" {
+		t.Errorf("4k Sonarr API key = %q, want the environment's", selected.Sonarr.APIKey)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+func TestSetGet(t *testing.T) {
+	config := &models.Config{
+		Sonarr: models.SonarrConfig{APIKey: "hd-key"},
+		Instances: map[string]models.InstanceConfig{
+			"4k": {Sonarr: &models.SonarrConfig{APIKey: "4k-key"}},
+		},
+	}
+
+	if err := Set(config, "sonarr.timeout", "10s"); err != nil {
+		t.Fatal(err)
+	}
+	if config.Sonarr.Timeout != 10*time.Second {
+		t.Errorf("sonarr.timeout = %v, want 10s", config.Sonarr.Timeout)
+	}
+	if err := Set(config, "instances.4K.sonarr.port", "8990"); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Instances["4k"].Sonarr; got.Port != 8990 || got.APIKey != "4k-key" {
+		t.Errorf("4k Sonarr = %+v, want port 8990 and the existing key", got)
+	}
+	if err := Set(config, "instances.anime.sabnzbd.host", "media"); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Instances["anime"].Sabnzbd; got == nil || got.Host != "media" {
+		t.Errorf("anime Sabnzbd = %+v, want host media", got)
+	}
+
+	if got, err := Get(config, "ui.max_results"); err != nil || got != 0 {
+		t.Errorf("Get(ui.max_results) = %v, %v", got, err)
+	}
+	if _, err := Get(config, "instances.anime.sonarr.port"); err == nil {
+		t.Error("Get of a section that is not set succeeded")
+	}
+	if _, err := Get(config, "sonarr.timeout.seconds"); err == nil {
+		t.Error("Get below a value succeeded")
+	}
+
+	redacted := Redact(config)
+	if redacted.Sonarr.APIKey != Redacted || redacted.Instances["4k"].Sonarr.APIKey != Redacted {
+		t.Errorf("Redact() = %+v, want masked API keys", redacted)
+	}
+	if config.Instances["4k"].Sonarr.APIKey != "4k-key" {
+		t.Error("Redact() changed the original configuration")
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.yaml.in/yaml/v3"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// CurrentVersion is the version of the configuration file format. Bump it
+// together with a new entry in migrations whenever a key is renamed or its
+// meaning changes.
+const CurrentVersion = 2
+
+// migrations upgrade a configuration file one version at a time;
+// migrations[i] turns version i+1 into version i+2. They edit the YAML
+// node tree so that comments and key order survive.
+var migrations = []func(root *yaml.Node){
+	migrateV1,
+}
+
+// migrateV1 renames the apikey keys documented by early READMEs to
+// api_key, and turns timeouts given as a bare number of seconds into
+// durations, which would otherwise be read as nanoseconds
+func migrateV1(root *yaml.Node) {
+	for _, service := range serviceNodes(root) {
+		if key := mappingKey(service, "apikey"); key != nil && mappingKey(service, "api_key") == nil {
+			key.Value = "api_key"
+		}
+		if value := mappingValue(service, "timeout"); value != nil && value.Tag == "!!int" {
+			value.SetString(value.Value + "s")
+		}
+	}
+}
+
+// migrateFile upgrades the configuration file at path to CurrentVersion.
+// The old file is kept next to it as config.yaml.v<version>.bak. It
+// returns the contents of the file, migrated or not; a file that cannot be
+// saved is only migrated in memory, with a warning.
+func migrateFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Empty files and other mistakes are reported by validation
+		return data, nil
+	}
+	root := doc.Content[0]
+
+	version := 1
+	if value := mappingValue(root, "version"); value != nil {
+		if version, err = strconv.Atoi(value.Value); err != nil {
+			return nil, fmt.Errorf("failed to parse config: version '%s' is not a number", value.Value)
+		}
+	}
+	if version >= CurrentVersion || version < 1 {
+		return data, nil
+	}
+
+	for _, migrate := range migrations[version-1:] {
+		migrate(root)
+	}
+	if value := mappingValue(root, "version"); value != nil {
+		value.Value, value.Tag = strconv.Itoa(CurrentVersion), "!!int"
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "version"},
+			{Kind: yaml.ScalarNode, Value: strconv.Itoa(CurrentVersion), Tag: "!!int"},
+		}, root.Content...)
+	}
+
+	migrated, err := encodeYAML(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	// A file that cannot be written, such as one on a read-only mount, is
+	// migrated in memory on every load instead
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := secrets.WriteFile(backup, data); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not back up %s to migrate it from version %d to %d: %v\n", path, version, CurrentVersion, err)
+		return migrated, nil
+	}
+	if err := secrets.WriteFile(path, migrated); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not save %s migrated from version %d to %d: %v\n", path, version, CurrentVersion, err)
+		return migrated, nil
+	}
+	fmt.Fprintf(os.Stderr, "ℹ️  Migrated %s from version %d to %d; the old file was saved as %s\n", path, version, CurrentVersion, backup)
+	return migrated, nil
+}
+
+// serviceNodes returns the sonarr and sabnzbd mappings at the top level
+// and in every instance
+func serviceNodes(root *yaml.Node) []*yaml.Node {
+	var nodes []*yaml.Node
+	add := func(parent *yaml.Node) {
+		for _, name := range []string{"sonarr", "sabnzbd"} {
+			if node := mappingValue(parent, name); node != nil && node.Kind == yaml.MappingNode {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	add(root)
+	if instances := mappingValue(root, "instances"); instances != nil && instances.Kind == yaml.MappingNode {
+		for i := 1; i < len(instances.Content); i += 2 {
+			add(instances.Content[i])
+		}
+	}
+	return nodes
+}
+
+// mappingKey returns the key node for key in a mapping node
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
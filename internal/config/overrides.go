@@ -0,0 +1,243 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// ConfigFileEnv names the configuration file to use, like --config
+const ConfigFileEnv = "SONCLI_CONFIG"
+
+// envReplacer turns a key such as sonarr.api_key into the suffix of its
+// environment variable
+var envReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+var (
+	// configFile is the file given by --config
+	configFile string
+
+	// flags are the command-line flags bound to configuration keys
+	flags = make(map[string]*pflag.Flag)
+
+	// loaded describes the last configuration loaded, so that SaveConfig
+	// can leave out values that came from flags and the environment, and
+	// change only what differs in the file
+	loaded struct {
+		raw  map[string]any
+		file *models.Config
+		doc  *yaml.Node
+	}
+)
+
+// EnvVar returns the environment variable that overrides key, e.g.
+// SONCLI_SONARR_URL for sonarr.url
+func EnvVar(key string) string {
+	return "SONCLI_" + strings.ToUpper(envReplacer.Replace(key))
+}
+
+// SetConfigFile makes LoadConfig and SaveConfig use path instead of the
+// default file. An empty path restores the default, or SONCLI_CONFIG. The
+// configuration loaded before is forgotten, so that a configuration built
+// from scratch, as setup does, is saved as it is.
+func SetConfigFile(path string) {
+	configFile = path
+	loaded.raw, loaded.file, loaded.doc = nil, nil, nil
+}
+
+// BindFlag makes flag override key when it is given. Secrets given by a
+// flag take precedence over every other source and are never saved.
+func BindFlag(key string, flag *pflag.Flag) {
+	flags[key] = flag
+}
+
+// Keys returns every key of the top-level configuration that can be
+// overridden, in the order of the configuration file. Named instances
+// and maps such as headers are only set in the file.
+func Keys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if tag == "" || tag == "-" || tag == "version" {
+				continue
+			}
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				walk(field.Type, prefix+tag+".")
+			case reflect.Map, reflect.Pointer:
+			default:
+				keys = append(keys, prefix+tag)
+			}
+		}
+	}
+	walk(reflect.TypeOf(models.Config{}), "")
+	return keys
+}
+
+// bindOverrides binds the environment variable and flag of every key to
+// v. Secrets are left to ResolveSonarr and ResolveSabnzbd, which keep them
+// out of the saved file.
+func bindOverrides(v *viper.Viper) error {
+	v.SetEnvKeyReplacer(envReplacer)
+	for _, key := range Keys() {
+		if slices.Contains(SecretNames, key) {
+			continue
+		}
+		if err := v.BindEnv(key, EnvVar(key)); err != nil {
+			return err
+		}
+		if flag, ok := flags[key]; ok {
+			if err := v.BindPFlag(key, flag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// overrideFile returns the configuration file given by --config or
+// SONCLI_CONFIG, or "" for the default
+func overrideFile() string {
+	if configFile != "" {
+		return expandHome(configFile)
+	}
+	return expandHome(os.Getenv(ConfigFileEnv))
+}
+
+// Origin describes where the value of key came from: a flag, the
+// environment, the configuration file or the defaults
+func Origin(key string) string {
+	if flag, ok := flags[key]; ok && flag.Changed {
+		return "flag (--" + flag.Name + ")"
+	}
+	if env := EnvVar(key); os.Getenv(env) != "" {
+		return "environment (" + env + ")"
+	}
+	if inFile(loaded.raw, key) {
+		return "config file"
+	}
+	return "default"
+}
+
+// overridden reports whether key was set by a flag or the environment
+func overridden(key string) bool {
+	origin := Origin(key)
+	return strings.HasPrefix(origin, "flag") || strings.HasPrefix(origin, "environment")
+}
+
+// inFile reports whether key is set in the parsed configuration file.
+// Keys are matched ignoring case, as viper does.
+func inFile(raw map[string]any, key string) bool {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := raw[part]
+		if !ok {
+			for name, v := range raw {
+				if strings.EqualFold(name, part) {
+					value, ok = v, true
+					break
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if raw, ok = value.(map[string]any); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// KeyOrigin is a configuration value together with where it came from
+type KeyOrigin struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// Origins returns the value and origin of every key in Keys. Secrets
+// given by a flag or the environment are included; commands, files and
+// the secret store are not read.
+func Origins(config *models.Config) []KeyOrigin {
+	var origins []KeyOrigin
+	for _, key := range Keys() {
+		value, _ := Get(config, key)
+		origin := Origin(key)
+		if slices.Contains(SecretNames, key) {
+			if secret, _ := secretOverride(key); secret != "" {
+				value = secret
+			}
+			origin = SecretOrigin(config, key, false)
+			if origin == "" && storeExists(config.Secrets) {
+				origin = "secret store"
+			} else if origin == "" {
+				origin = "not set"
+			}
+		}
+		origins = append(origins, KeyOrigin{Key: key, Value: value, Origin: origin})
+	}
+	return origins
+}
+
+// restoreOverrides returns a copy of config where values that still hold
+// what a flag or the environment set are replaced by those of the file, so
+// that saving a configuration never writes them
+func restoreOverrides(config models.Config) models.Config {
+	if loaded.file == nil {
+		return config
+	}
+	for _, key := range Keys() {
+		if slices.Contains(SecretNames, key) || !overridden(key) {
+			continue
+		}
+		value, _ := Get(&config, key)
+		file, _ := Get(loaded.file, key)
+		if reflect.DeepEqual(value, file) {
+			continue
+		}
+		// A value changed since loading, e.g. by config set, is kept
+		if given, ok := flagOrEnvValue(key); !ok || !reflect.DeepEqual(value, given) {
+			continue
+		}
+		keyField(&config, key).Set(reflect.ValueOf(file))
+	}
+	return config
+}
+
+// keyField returns the field of config that holds key, which must be one
+// of Keys
+func keyField(config *models.Config, key string) reflect.Value {
+	field := reflect.ValueOf(config).Elem()
+	for _, part := range strings.Split(key, ".") {
+		field, _ = fieldByTag(field, part)
+	}
+	return field
+}
+
+// flagOrEnvValue returns the value a flag or the environment gives key,
+// parsed as the type of key
+func flagOrEnvValue(key string) (any, bool) {
+	raw := os.Getenv(EnvVar(key))
+	if flag, ok := flags[key]; ok && flag.Changed {
+		raw = flag.Value.String()
+	}
+	var parsed models.Config
+	if err := Set(&parsed, key, raw); err != nil {
+		return nil, false
+	}
+	value, err := Get(&parsed, key)
+	return value, err == nil
+}
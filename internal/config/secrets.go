@@ -0,0 +1,329 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+// Names of the secrets that can be kept outside the configuration file.
+// The environment variable of a secret is SONCLI_ followed by its name in
+// upper case with dots replaced by underscores. Secrets of a named instance
+// are prefixed with the instance name, e.g. 4k.sonarr.api_key.
+const (
+	SonarrAPIKey    = "sonarr.api_key"
+	SabnzbdAPIKey   = "sabnzbd.api_key"
+	SabnzbdPassword = "sabnzbd.password"
+)
+
+// SecretNames lists every secret name of the default instance
+var SecretNames = []string{SonarrAPIKey, SabnzbdAPIKey, SabnzbdPassword}
+
+var (
+	storeMu sync.Mutex
+	stores  = make(map[string]*secrets.Store)
+)
+
+// SecretEnv returns the environment variable holding a secret
+func SecretEnv(name string) string {
+	return EnvVar(name)
+}
+
+// secretOverride returns a secret given by a flag or the environment,
+// along with where it came from. A secret of a named instance is also
+// overridden by the flag and variable of the top-level secret, such as
+// --sonarr-api-key, as the other settings of the instance are.
+func secretOverride(name string) (string, string) {
+	if flag, ok := flags[name]; ok && flag.Changed && flag.Value.String() != "" {
+		return flag.Value.String(), "flag (--" + flag.Name + ")"
+	}
+	env := SecretEnv(name)
+	if value := os.Getenv(env); value != "" {
+		return value, "environment (" + env + ")"
+	}
+	for _, secret := range SecretNames {
+		if strings.HasSuffix(name, "."+secret) {
+			return secretOverride(secret)
+		}
+	}
+	return "", ""
+}
+
+// SecretOrigin describes where a secret is resolved from, or returns ""
+// if it is not set anywhere. stored reports whether the secret store
+// holds it.
+func SecretOrigin(config *models.Config, name string, stored bool) string {
+	if _, origin := secretOverride(name); origin != "" {
+		return origin
+	}
+
+	value, command, file, _ := SecretFields(config, name)
+	switch {
+	case command != "":
+		return "command (" + command + ")"
+	case file != "":
+		return "file (" + file + ")"
+	case value != nil && *value != "":
+		return "config file (plaintext)"
+	case stored:
+		return "secret store"
+	default:
+		return ""
+	}
+}
+
+// AllSecretNames returns the secret names of every instance in config
+func AllSecretNames(config *models.Config) []string {
+	names := slices.Clone(SecretNames)
+	for _, instance := range InstanceNames(config)[1:] {
+		if config.Instances[instance].Sonarr != nil {
+			names = append(names, scopedName(instance, SonarrAPIKey))
+		}
+		if config.Instances[instance].Sabnzbd != nil {
+			names = append(names, scopedName(instance, SabnzbdAPIKey), scopedName(instance, SabnzbdPassword))
+		}
+	}
+	return names
+}
+
+// SecretFields returns a pointer to the configuration value of a secret
+// along with its command and file sources
+func SecretFields(config *models.Config, name string) (value *string, command, file string, ok bool) {
+	sonarr, sabnzbd := &config.Sonarr, &config.Sabnzbd
+	if instance, base, found := strings.Cut(name, "."); found && slices.Contains(SecretNames, base) {
+		settings, exists := config.Instances[instance]
+		if !exists {
+			return nil, "", "", false
+		}
+		sonarr, sabnzbd = settings.Sonarr, settings.Sabnzbd
+		name = base
+	}
+
+	switch {
+	case name == SonarrAPIKey && sonarr != nil:
+		return &sonarr.APIKey, sonarr.APIKeyCmd, sonarr.APIKeyFile, true
+	case name == SabnzbdAPIKey && sabnzbd != nil:
+		return &sabnzbd.APIKey, sabnzbd.APIKeyCmd, sabnzbd.APIKeyFile, true
+	case name == SabnzbdPassword && sabnzbd != nil:
+		return &sabnzbd.Password, sabnzbd.PasswordCmd, sabnzbd.PasswordFile, true
+	}
+	return nil, "", "", false
+}
+
+// scopedName returns the name of a secret of an instance
+func scopedName(instance, name string) string {
+	if instance == "" || instance == DefaultInstance {
+		return name
+	}
+	return instance + "." + name
+}
+
+// StorePath returns the path of the encrypted secret store
+func StorePath(config models.SecretsConfig) (string, error) {
+	if config.File != "" {
+		return expandHome(config.File), nil
+	}
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "secrets.enc"), nil
+}
+
+// OpenStore opens the encrypted secret store, asking for the passphrase
+// once per process
+func OpenStore(config models.SecretsConfig) (*secrets.Store, error) {
+	path, err := StorePath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store, ok := stores[path]; ok {
+		return store, nil
+	}
+
+	passphrase, err := secrets.Passphrase(config.PassphraseCmd)
+	if err != nil {
+		return nil, err
+	}
+	store, err := secrets.Open(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	stores[path] = store
+	return store, nil
+}
+
+// ResolveSonarr fills in the Sonarr API key from its configured source
+func ResolveSonarr(config *models.SonarrConfig, secretsConfig models.SecretsConfig) error {
+	name := scopedName(config.Instance, SonarrAPIKey)
+	external, err := resolveSecret(name, &config.APIKey, config.APIKeyCmd, config.APIKeyFile, secretsConfig)
+	if err != nil {
+		return err
+	}
+	if config.APIKey == "" {
+		return missingSecret(name)
+	}
+	setExternal(&config.External, "api_key", external)
+	return nil
+}
+
+// ResolveSabnzbd fills in the Sabnzbd API key and password from their
+// configured sources
+func ResolveSabnzbd(config *models.SabnzbdConfig, secretsConfig models.SecretsConfig) error {
+	name := scopedName(config.Instance, SabnzbdAPIKey)
+	external, err := resolveSecret(name, &config.APIKey, config.APIKeyCmd, config.APIKeyFile, secretsConfig)
+	if err != nil {
+		return err
+	}
+	if config.APIKey == "" {
+		return missingSecret(name)
+	}
+	setExternal(&config.External, "api_key", external)
+
+	// A password is only used together with a username
+	if config.Username == "" {
+		return nil
+	}
+	external, err = resolveSecret(scopedName(config.Instance, SabnzbdPassword), &config.Password, config.PasswordCmd, config.PasswordFile, secretsConfig)
+	if err != nil {
+		return err
+	}
+	setExternal(&config.External, "password", external)
+	return nil
+}
+
+// resolveSecret sets value from, in order of precedence, a flag, the
+// environment, a command, a file, the configuration file itself and the encrypted
+// store. It reports whether the value came from outside the configuration
+// file.
+func resolveSecret(name string, value *string, command, file string, secretsConfig models.SecretsConfig) (bool, error) {
+	if secret, _ := secretOverride(name); secret != "" {
+		*value = secret
+		return true, nil
+	}
+
+	if command != "" {
+		secret, err := secrets.Command(command)
+		if err != nil {
+			return false, fmt.Errorf("failed to get %s: %w", name, err)
+		}
+		*value = secret
+		return true, nil
+	}
+
+	if file != "" {
+		secret, err := secrets.File(expandHome(file))
+		if err != nil {
+			return false, fmt.Errorf("failed to get %s: %w", name, err)
+		}
+		*value = secret
+		return true, nil
+	}
+
+	if *value != "" || !storeExists(secretsConfig) {
+		return false, nil
+	}
+
+	store, err := OpenStore(secretsConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	if secret, ok := store.Get(name); ok {
+		*value = secret
+		return true, nil
+	}
+	return false, nil
+}
+
+// storeExists reports whether the encrypted secret store has been created
+func storeExists(config models.SecretsConfig) bool {
+	path, err := StorePath(config)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// CheckSecrets reports API keys without a source. Commands are not run
+// and the secret store is not searched, so a store that exists counts as
+// a source.
+func CheckSecrets(config *models.Config) []Problem {
+	var problems []Problem
+	check := func(name string) {
+		value, command, file, _ := SecretFields(config, name)
+		if override, _ := secretOverride(name); *value == "" && command == "" && file == "" && override == "" && !storeExists(config.Secrets) {
+			problems = append(problems, missingSecretProblem(name))
+		}
+	}
+	for _, instance := range SonarrInstances(config) {
+		check(scopedName(instance, SonarrAPIKey))
+	}
+	for _, instance := range SabnzbdInstances(config) {
+		check(scopedName(instance, SabnzbdAPIKey))
+	}
+	return problems
+}
+
+// missingSecret reports a secret that has no value in any source
+func missingSecret(name string) error {
+	return &ValidationError{Problems: []Problem{missingSecretProblem(name)}}
+}
+
+// missingSecretProblem describes a secret that has no value in any source
+func missingSecretProblem(name string) Problem {
+	path := name
+	if instance, base, found := strings.Cut(name, "."); found && slices.Contains(SecretNames, base) {
+		path = "instances." + instance + "." + base
+	}
+	return Problem{
+		Path:    path,
+		Message: fmt.Sprintf("is not set; run setup, set %s or see 'secrets --help'", SecretEnv(name)),
+	}
+}
+
+// setExternal records whether a secret field came from an external source
+func setExternal(external *map[string]bool, field string, value bool) {
+	if !value {
+		delete(*external, field)
+		return
+	}
+	if *external == nil {
+		*external = make(map[string]bool)
+	}
+	(*external)[field] = true
+}
+
+// stripExternal returns a copy of config without secrets resolved from
+// external sources
+func stripExternal(config models.Config) models.Config {
+	if config.Sonarr.External["api_key"] {
+		config.Sonarr.APIKey = ""
+	}
+	if config.Sabnzbd.External["api_key"] {
+		config.Sabnzbd.APIKey = ""
+	}
+	if config.Sabnzbd.External["password"] {
+		config.Sabnzbd.Password = ""
+	}
+	return config
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/secrets"
+)
+
+func TestResolveSecretPrecedence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(secrets.PassphraseEnv, "passphrase")
+
+	dir := t.TempDir()
+	secretsConfig := models.SecretsConfig{File: filepath.Join(dir, "secrets.enc")}
+	store, err := OpenStore(secretsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set(SonarrAPIKey, "from-store")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "api_key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		config   models.SonarrConfig
+		env      string
+		want     string
+		external bool
+	}{
+		{"store", models.SonarrConfig{}, "", "from-store", true},
+		{"plaintext", models.SonarrConfig{APIKey: "from-config"}, "", "from-config", false},
+		{"file", models.SonarrConfig{APIKey: "from-config", APIKeyFile: keyFile}, "", "from-file", true},
+		{"command", models.SonarrConfig{APIKeyCmd: "echo from-cmd", APIKeyFile: keyFile}, "", "from-cmd", true},
+		{"environment", models.SonarrConfig{APIKeyCmd: "echo from-cmd"}, "from-env", "from-env", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(SecretEnv(SonarrAPIKey), tt.env)
+			config := tt.config
+			if err := ResolveSonarr(&config, secretsConfig); err != nil {
+				t.Fatal(err)
+			}
+			if config.APIKey != tt.want {
+				t.Errorf("APIKey = %q, want %q", config.APIKey, tt.want)
+			}
+			if config.External["api_key"] != tt.external {
+				t.Errorf("External = %v, want %v", config.External["api_key"], tt.external)
+			}
+		})
+	}
+}
+
+func TestSaveConfigOmitsExternalSecrets(t *testing.T) {
+	viper.Reset()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(SecretEnv(SonarrAPIKey), "from-env")
+
+	config := &models.Config{
+		Sonarr:  models.SonarrConfig{Host: "127.0.0.1", Port: 8989},
+		Sabnzbd: models.SabnzbdConfig{Host: "127.0.0.1", Port: 8080, APIKey: "plaintext-key"},
+	}
+	if err := ResolveSonarr(&config.Sonarr, config.Secrets); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(home, ".config", "sonarr-sabnzbd-cli", "config.yaml")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config permissions = %o, want 600", perm)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "from-env") {
+		t.Errorf("config contains a secret from the environment:\n%s", data)
+	}
+	if !strings.Contains(string(data), "plaintext-key") {
+		t.Errorf("config lost the plaintext API key:\n%s", data)
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// Problem is an invalid configuration value at a path such as sonarr.port
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError lists every problem found in a configuration file
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		fmt.Fprintf(&b, "invalid configuration in %s:", e.File)
+	} else {
+		b.WriteString("invalid configuration:")
+	}
+	for _, problem := range e.Problems {
+		b.WriteString("\n  " + problem.String())
+	}
+	return b.String()
+}
+
+// Validate checks config for invalid values. raw is the configuration
+// file as parsed YAML, used to report unknown keys; it may be nil.
+func Validate(config *models.Config, raw map[string]any) []Problem {
+	var problems []Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if raw != nil {
+		for _, path := range unknownKeys(raw, reflect.TypeOf(models.Config{}), "") {
+			add(path, "unknown key")
+		}
+	}
+
+	if config.Version > CurrentVersion {
+		add("version", "%d is newer than this release supports (%d); upgrade soncli", config.Version, CurrentVersion)
+	}
+
+	validateService(add, "sonarr", config.Sonarr.URL, config.Sonarr.Host, config.Sonarr.Port, config.Sonarr.Timeout,
+		config.Sonarr.CertFile, config.Sonarr.KeyFile, config.Sonarr.CAFile, true)
+	validateService(add, "sabnzbd", config.Sabnzbd.URL, config.Sabnzbd.Host, config.Sabnzbd.Port, config.Sabnzbd.Timeout,
+		config.Sabnzbd.CertFile, config.Sabnzbd.KeyFile, config.Sabnzbd.CAFile, true)
+	if config.Sonarr.VersionCacheTTL < 0 {
+		add("sonarr.version_cache_ttl", "must not be negative")
+	}
+
+	for _, name := range InstanceNames(config)[1:] {
+		instance := config.Instances[name]
+		prefix := "instances." + name
+		if name == DefaultInstance {
+			add(prefix, "'%s' is reserved for the top-level settings", DefaultInstance)
+		}
+		if instance.Sonarr == nil && instance.Sabnzbd == nil {
+			add(prefix, "must define sonarr or sabnzbd")
+		}
+		if s := instance.Sonarr; s != nil {
+			validateService(add, prefix+".sonarr", s.URL, s.Host, s.Port, s.Timeout, s.CertFile, s.KeyFile, s.CAFile, false)
+		}
+		if s := instance.Sabnzbd; s != nil {
+			validateService(add, prefix+".sabnzbd", s.URL, s.Host, s.Port, s.Timeout, s.CertFile, s.KeyFile, s.CAFile, false)
+		}
+	}
+	if name := config.CurrentInstance; name != "" && name != DefaultInstance {
+		if _, ok := config.Instances[strings.ToLower(name)]; !ok {
+			add("current_instance", "unknown instance '%s'", name)
+		}
+	}
+
+	if config.UI.MaxResults < 1 {
+		add("ui.max_results", "must be at least 1, got %d", config.UI.MaxResults)
+	}
+	if config.HTTP.Retries < 0 {
+		add("http.retries", "must not be negative")
+	}
+	if config.HTTP.RetryWait < 0 || config.HTTP.RetryMaxWait < 0 {
+		add("http.retry_wait", "durations must not be negative")
+	} else if config.HTTP.RetryMaxWait < config.HTTP.RetryWait {
+		add("http.retry_max_wait", "must not be shorter than http.retry_wait")
+	}
+	if config.HTTP.RateLimit < 0 {
+		add("http.rate_limit", "must not be negative")
+	} else if config.HTTP.RateLimit > 0 && config.HTTP.RateBurst < 1 {
+		add("http.rate_burst", "must be at least 1 when http.rate_limit is set")
+	}
+
+	return problems
+}
+
+// validateService checks the connection settings of a service. Settings
+// of a named instance may leave out the host and port they inherit.
+func validateService(add func(path, format string, args ...any), path, rawURL, host string, port int, timeout time.Duration, certFile, keyFile, caFile string, required bool) {
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(path+".url", "must be an http or https URL, got '%s'", rawURL)
+		}
+	} else {
+		if required && host == "" {
+			add(path+".host", "is required unless url is set")
+		}
+		if (required || port != 0) && (port < 1 || port > 65535) {
+			add(path+".port", "must be between 1 and 65535, got %d", port)
+		}
+	}
+
+	if timeout < 0 {
+		add(path+".timeout", "must not be negative")
+	} else if timeout > 0 && timeout < time.Millisecond {
+		add(path+".timeout", "%s is too short; give a unit, e.g. 30s", timeout)
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		add(path+".cert_file", "cert_file and key_file must be set together")
+	}
+	for _, file := range []struct{ key, path string }{{"ca_file", caFile}, {"cert_file", certFile}, {"key_file", keyFile}} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(expandHome(file.path)); err != nil {
+			add(path+"."+file.key, "%v", err)
+		}
+	}
+}
+
+// unknownKeys returns the paths of keys in raw that have no field in t,
+// matched through mapstructure tags like the configuration decoder does
+func unknownKeys(raw map[string]any, t reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fields[tag] = field.Type
+	}
+
+	var unknown []string
+	for key, value := range raw {
+		path := prefix + key
+		fieldType, ok := fields[strings.ToLower(key)]
+		if !ok {
+			unknown = append(unknown, path)
+			continue
+		}
+		nested, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			unknown = append(unknown, unknownKeys(nested, fieldType, path+".")...)
+		case reflect.Map:
+			// Maps of structs, such as instances, are checked per entry
+			elem := fieldType.Elem()
+			for elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				continue
+			}
+			for name, entry := range nested {
+				if entry, ok := entry.(map[string]any); ok {
+					unknown = append(unknown, unknownKeys(entry, elem, path+"."+name+".")...)
+				}
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
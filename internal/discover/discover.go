@@ -0,0 +1,198 @@
+// Package discover finds Sonarr and Sabnzbd servers for the setup wizard,
+// and reads their settings from the files they keep on disk
+package discover
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hosts are probed in order; 172.17.0.1 is the default Docker bridge
+// gateway, which reaches the host from inside a container
+var Hosts = []string{"localhost", "host.docker.internal", "172.17.0.1"}
+
+// Default ports of Sonarr and of Sabnzbd, which also often runs on 8085 or
+// 9090 to stay clear of other services on 8080
+var (
+	SonarrPorts  = []int{8989}
+	SabnzbdPorts = []int{8080, 8085, 9090}
+)
+
+// Timeout bounds each probe, so hosts that drop packets don't stall setup
+var Timeout = 750 * time.Millisecond
+
+// Server is a server found by probing
+type Server struct {
+	Host string
+	Port int
+	// URLBase is the path prefix the server answered under, if any
+	URLBase string
+}
+
+// Sonarr returns the first host and port that answer like Sonarr. Its
+// /ping endpoint needs no API key.
+func Sonarr(ctx context.Context) (Server, bool) {
+	return probe(ctx, SonarrPorts, func(body []byte) bool {
+		var ping struct {
+			Status string `json:"status"`
+		}
+		return json.Unmarshal(body, &ping) == nil && strings.EqualFold(ping.Status, "ok")
+	}, "/ping", "")
+}
+
+// Sabnzbd returns the first host and port that answer like Sabnzbd, with
+// the /sabnzbd URL base when it only answers there. Its version API needs
+// no API key.
+func Sabnzbd(ctx context.Context) (Server, bool) {
+	return probe(ctx, SabnzbdPorts, func(body []byte) bool {
+		var version struct {
+			Version string `json:"version"`
+		}
+		return json.Unmarshal(body, &version) == nil && version.Version != ""
+	}, "/api?mode=version&output=json", "", "/sabnzbd")
+}
+
+// probe requests path under each URL base on every host and port at once,
+// and returns the first server in the order of Hosts and ports whose
+// answer matches
+func probe(ctx context.Context, ports []int, match func(body []byte) bool, path string, bases ...string) (Server, bool) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	client := &http.Client{Timeout: Timeout}
+
+	var candidates []Server
+	for _, host := range Hosts {
+		for _, port := range ports {
+			candidates = append(candidates, Server{Host: host, Port: port})
+		}
+	}
+
+	found := make([]bool, len(candidates))
+	var wg sync.WaitGroup
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, base := range bases {
+				if answers(ctx, client, candidate, base+path, match) {
+					candidates[i].URLBase = base
+					found[i] = true
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, ok := range found {
+		if ok {
+			return candidates[i], true
+		}
+	}
+	return Server{}, false
+}
+
+// answers reports whether server answers path with a matching body
+func answers(ctx context.Context, client *http.Client, server Server, path string, match func(body []byte) bool) bool {
+	url := "http://" + net.JoinHostPort(server.Host, strconv.Itoa(server.Port)) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var body json.RawMessage
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&body) != nil {
+		return false
+	}
+	return match(body)
+}
+
+// Settings are connection settings read from a server's own files
+type Settings struct {
+	APIKey   string
+	Port     int
+	URLBase  string
+	Username string
+	Password string
+}
+
+// SonarrConfigXML reads the API key, port and URL base from Sonarr's
+// config.xml, found in its data directory
+func SonarrConfigXML(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to read Sonarr config: %w", err)
+	}
+
+	var config struct {
+		APIKey  string `xml:"ApiKey"`
+		Port    string `xml:"Port"`
+		URLBase string `xml:"UrlBase"`
+	}
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return Settings{}, fmt.Errorf("failed to parse Sonarr config %s: %w", path, err)
+	}
+	if config.APIKey == "" {
+		return Settings{}, fmt.Errorf("no ApiKey in Sonarr config %s", path)
+	}
+
+	port, _ := strconv.Atoi(strings.TrimSpace(config.Port))
+	return Settings{APIKey: strings.TrimSpace(config.APIKey), Port: port, URLBase: strings.TrimSpace(config.URLBase)}, nil
+}
+
+// SabnzbdINI reads the API key, port, URL base and login from the [misc]
+// section of sabnzbd.ini
+func SabnzbdINI(path string) (Settings, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to read Sabnzbd config: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			// Nested sections such as [[servers]] use more brackets
+			section = strings.Trim(line, "[]")
+		case section == "misc":
+			if key, value, ok := strings.Cut(line, "="); ok {
+				values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Settings{}, fmt.Errorf("failed to read Sabnzbd config: %w", err)
+	}
+	if values["api_key"] == "" {
+		return Settings{}, fmt.Errorf("no api_key in the [misc] section of %s", path)
+	}
+
+	port, _ := strconv.Atoi(values["port"])
+	return Settings{
+		APIKey:   values["api_key"],
+		Port:     port,
+		URLBase:  values["url_base"],
+		Username: values["username"],
+		Password: values["password"],
+	}, nil
+}
@@ -0,0 +1,106 @@
+package discover
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	sonarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			w.Write([]byte(`{"status": "OK"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer sonarr.Close()
+	sabnzbd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sabnzbd/api" && r.URL.Query().Get("mode") == "version" {
+			w.Write([]byte(`{"version": "4.3.2"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer sabnzbd.Close()
+
+	port := func(server *httptest.Server) int {
+		_, p, _ := net.SplitHostPort(server.Listener.Addr().String())
+		n, _ := strconv.Atoi(p)
+		return n
+	}
+	hosts, sonarrPorts, sabnzbdPorts := Hosts, SonarrPorts, SabnzbdPorts
+	defer func() { Hosts, SonarrPorts, SabnzbdPorts = hosts, sonarrPorts, sabnzbdPorts }()
+	Hosts = []string{"127.0.0.1"}
+	SonarrPorts = []int{port(sabnzbd), port(sonarr)}
+	SabnzbdPorts = []int{port(sonarr), port(sabnzbd)}
+
+	ctx := context.Background()
+	if got, ok := Sonarr(ctx); !ok || got.Port != port(sonarr) {
+		t.Errorf("Sonarr() = %+v, %v, want port %d", got, ok, port(sonarr))
+	}
+	if got, ok := Sabnzbd(ctx); !ok || got.Port != port(sabnzbd) || got.URLBase != "/sabnzbd" {
+		t.Errorf("Sabnzbd() = %+v, %v, want port %d under /sabnzbd", got, ok, port(sabnzbd))
+	}
+	if got, ok := Sonarr(ctx); !ok || got.URLBase != "" {
+		t.Errorf("Sonarr() = %+v, %v, want no URL base", got, ok)
+	}
+
+	sonarr.Close()
+	if got, ok := Sonarr(ctx); ok {
+		t.Errorf("Sonarr() found %+v after the server stopped", got)
+	}
+}
+
+func TestServerFiles(t *testing.T) {
+	dir := t.TempDir()
+	configXML := filepath.Join(dir, "config.xml")
+	os.WriteFile(configXML, []byte(`<Config>
+  <BindAddress>*</BindAddress>
+  <Port>8990</Port>
+  <UrlBase>/sonarr</UrlBase>
+  <ApiKey>0123456789abcdef</ApiKey>
+</Config>`), 0600)
+	ini := filepath.Join(dir, "sabnzbd.ini")
+	os.WriteFile(ini, []byte(`__version__ = 19
+[misc]
+# comments are skipped
+port = 8085
+url_base = /sabnzbd
+api_key = fedcba9876543210
+username = "admin"
+password = secret
+[servers]
+[[news.example.com]]
+username = news
+`), 0600)
+
+	sonarr, err := SonarrConfigXML(configXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sonarr.APIKey != "0123456789abcdef" || sonarr.Port != 8990 || sonarr.URLBase != "/sonarr" {
+		t.Errorf("SonarrConfigXML() = %+v", sonarr)
+	}
+
+	sabnzbd, err := SabnzbdINI(ini)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Settings{APIKey: "fedcba9876543210", Port: 8085, URLBase: "/sabnzbd", Username: "admin", Password: "secret"}
+	if sabnzbd != want {
+		t.Errorf("SabnzbdINI() = %+v, want %+v", sabnzbd, want)
+	}
+
+	if _, err := SabnzbdINI(configXML); err == nil {
+		t.Error("SabnzbdINI() of a file without api_key succeeded")
+	}
+	if _, err := SonarrConfigXML(ini); err == nil {
+		t.Error("SonarrConfigXML() of an INI file succeeded")
+	}
+}
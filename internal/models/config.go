@@ -4,31 +4,98 @@ import "time"
 
 // Config represents the application configuration
 type Config struct {
+	Version int           `mapstructure:"version" yaml:"version"`
 	Sonarr  SonarrConfig  `mapstructure:"sonarr" yaml:"sonarr"`
 	Sabnzbd SabnzbdConfig `mapstructure:"sabnzbd" yaml:"sabnzbd"`
 	UI      UIConfig      `mapstructure:"ui" yaml:"ui"`
+	HTTP    HTTPConfig    `mapstructure:"http" yaml:"http"`
+	Secrets SecretsConfig `mapstructure:"secrets" yaml:"secrets,omitempty"`
+
+	Instances       map[string]InstanceConfig `mapstructure:"instances" yaml:"instances,omitempty"`
+	CurrentInstance string                    `mapstructure:"current_instance" yaml:"current_instance,omitempty"`
+}
+
+// InstanceConfig holds a named instance. A service left out is shared with
+// the top-level configuration, and a service that is given inherits every
+// field it leaves out except its secrets.
+type InstanceConfig struct {
+	Sonarr  *SonarrConfig  `mapstructure:"sonarr" yaml:"sonarr,omitempty"`
+	Sabnzbd *SabnzbdConfig `mapstructure:"sabnzbd" yaml:"sabnzbd,omitempty"`
 }
 
 // SonarrConfig holds Sonarr connection settings
 type SonarrConfig struct {
-	Host    string        `mapstructure:"host" yaml:"host"`
-	Port    int           `mapstructure:"port" yaml:"port"`
-	APIKey  string        `mapstructure:"api_key" yaml:"api_key"`
-	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	URL                string            `mapstructure:"url" yaml:"url,omitempty"`
+	Host               string            `mapstructure:"host" yaml:"host"`
+	Port               int               `mapstructure:"port" yaml:"port"`
+	URLBase            string            `mapstructure:"url_base" yaml:"url_base,omitempty"`
+	APIKey             string            `mapstructure:"api_key" yaml:"api_key"`
+	APIKeyCmd          string            `mapstructure:"api_key_cmd" yaml:"api_key_cmd,omitempty"`
+	APIKeyFile         string            `mapstructure:"api_key_file" yaml:"api_key_file,omitempty"`
+	Timeout            time.Duration     `mapstructure:"timeout" yaml:"timeout"`
+	CAFile             string            `mapstructure:"ca_file" yaml:"ca_file,omitempty"`
+	CertFile           string            `mapstructure:"cert_file" yaml:"cert_file,omitempty"`
+	KeyFile            string            `mapstructure:"key_file" yaml:"key_file,omitempty"`
+	InsecureSkipVerify bool              `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify,omitempty"`
+	Headers            map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+	VersionCacheTTL    time.Duration     `mapstructure:"version_cache_ttl" yaml:"version_cache_ttl,omitempty"`
+
+	// Instance names the instance that defines these settings, which scopes
+	// their secrets. It is empty for the top-level settings.
+	Instance string `mapstructure:"-" yaml:"-"`
+
+	// External marks secrets resolved from outside the configuration file,
+	// which SaveConfig never writes
+	External map[string]bool `mapstructure:"-" yaml:"-"`
 }
 
 // SabnzbdConfig holds Sabnzbd connection settings
 type SabnzbdConfig struct {
-	Host     string        `mapstructure:"host" yaml:"host"`
-	Port     int           `mapstructure:"port" yaml:"port"`
-	APIKey   string        `mapstructure:"api_key" yaml:"api_key"`
-	Username string        `mapstructure:"username" yaml:"username"`
-	Password string        `mapstructure:"password" yaml:"password"`
-	Timeout  time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	URL                string            `mapstructure:"url" yaml:"url,omitempty"`
+	Host               string            `mapstructure:"host" yaml:"host"`
+	Port               int               `mapstructure:"port" yaml:"port"`
+	URLBase            string            `mapstructure:"url_base" yaml:"url_base,omitempty"`
+	APIKey             string            `mapstructure:"api_key" yaml:"api_key"`
+	APIKeyCmd          string            `mapstructure:"api_key_cmd" yaml:"api_key_cmd,omitempty"`
+	APIKeyFile         string            `mapstructure:"api_key_file" yaml:"api_key_file,omitempty"`
+	Username           string            `mapstructure:"username" yaml:"username"`
+	Password           string            `mapstructure:"password" yaml:"password"`
+	PasswordCmd        string            `mapstructure:"password_cmd" yaml:"password_cmd,omitempty"`
+	PasswordFile       string            `mapstructure:"password_file" yaml:"password_file,omitempty"`
+	Timeout            time.Duration     `mapstructure:"timeout" yaml:"timeout"`
+	CAFile             string            `mapstructure:"ca_file" yaml:"ca_file,omitempty"`
+	CertFile           string            `mapstructure:"cert_file" yaml:"cert_file,omitempty"`
+	KeyFile            string            `mapstructure:"key_file" yaml:"key_file,omitempty"`
+	InsecureSkipVerify bool              `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify,omitempty"`
+	Headers            map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+
+	// Instance names the instance that defines these settings, which scopes
+	// their secrets. It is empty for the top-level settings.
+	Instance string `mapstructure:"-" yaml:"-"`
+
+	// External marks secrets resolved from outside the configuration file,
+	// which SaveConfig never writes
+	External map[string]bool `mapstructure:"-" yaml:"-"`
 }
 
 // UIConfig holds UI-related settings
 type UIConfig struct {
-	Colors     bool `mapstructure:"colors" yaml:"colors"`
-	MaxResults int  `mapstructure:"max_results" yaml:"max_results"`
+	Colors     bool   `mapstructure:"colors" yaml:"colors"`
+	MaxResults int    `mapstructure:"max_results" yaml:"max_results"`
+	LogFile    string `mapstructure:"log_file" yaml:"log_file,omitempty"`
+}
+
+// HTTPConfig holds retry and rate limiting settings shared by both clients
+type HTTPConfig struct {
+	Retries      int           `mapstructure:"retries" yaml:"retries"`
+	RetryWait    time.Duration `mapstructure:"retry_wait" yaml:"retry_wait"`
+	RetryMaxWait time.Duration `mapstructure:"retry_max_wait" yaml:"retry_max_wait"`
+	RateLimit    float64       `mapstructure:"rate_limit" yaml:"rate_limit"`
+	RateBurst    int           `mapstructure:"rate_burst" yaml:"rate_burst"`
+}
+
+// SecretsConfig holds settings for the encrypted secret store
+type SecretsConfig struct {
+	File          string `mapstructure:"file" yaml:"file,omitempty"`
+	PassphraseCmd string `mapstructure:"passphrase_cmd" yaml:"passphrase_cmd,omitempty"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // SabnzbdResponse represents the base response from Sabnzbd API
 type SabnzbdResponse struct {
 	Status bool   `json:"status"`
@@ -18,8 +20,11 @@ type Queue struct {
 	PauseInt   string      `json:"pause_int"`
 	SpeedLimit string      `json:"speedlimit"`
 	Speed      string      `json:"speed"`
+	KBPerSec   string      `json:"kbpersec"`
 	Size       string      `json:"size"`
 	SizeLeft   string      `json:"sizeleft"`
+	MB         string      `json:"mb"`
+	MBLeft     string      `json:"mbleft"`
 	TimeLeft   string      `json:"timeleft"`
 	ETA        string      `json:"eta"`
 	Status     string      `json:"status"`
@@ -93,3 +98,185 @@ type VersionResponse struct {
 	SabnzbdResponse
 	Version string `json:"version"`
 }
+
+// FilesResponse represents the response from the get_files endpoint
+type FilesResponse struct {
+	Files []JobFile `json:"files"`
+}
+
+// JobFile represents a single file within a queued job
+type JobFile struct {
+	ID       string `json:"nzf_id"`
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	MB       string `json:"mb"`
+	MBLeft   string `json:"mbleft"`
+	Bytes    string `json:"bytes"`
+	Age      string `json:"age"`
+	Set      string `json:"set"`
+}
+
+// FullStatusResponse represents the fullstatus response
+type FullStatusResponse struct {
+	Status FullStatus `json:"status"`
+}
+
+// FullStatus represents the detailed system status of Sabnzbd
+type FullStatus struct {
+	LocalIPv4        string         `json:"localipv4"`
+	PublicIPv4       string         `json:"publicipv4"`
+	IPv6             string         `json:"ipv6"`
+	CPUModel         string         `json:"cpumodel"`
+	Pystone          int            `json:"pystone"`
+	LoadAvg          string         `json:"loadavg"`
+	Uptime           string         `json:"uptime"`
+	DownloadDir      string         `json:"downloaddir"`
+	DownloadDirSpeed float64        `json:"downloaddirspeed"`
+	CompleteDir      string         `json:"completedir"`
+	CompleteDirSpeed float64        `json:"completedirspeed"`
+	DiskSpace1       string         `json:"diskspace1"`
+	DiskSpace2       string         `json:"diskspace2"`
+	DiskSpaceTotal1  string         `json:"diskspacetotal1"`
+	DiskSpaceTotal2  string         `json:"diskspacetotal2"`
+	Servers          []ServerStatus `json:"servers"`
+	Folders          []string       `json:"folders"`
+
+	// Modules maps the helper modules and programs Sabnzbd has loaded,
+	// such as sabctools and unrar, to their version. Servers that list
+	// them as separate fields are read by UnmarshalJSON.
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// moduleFields are the fullstatus fields that report a loaded module
+var moduleFields = []string{"sabctools", "sabyenc", "par2", "multipar", "unrar", "7zip", "openssl", "nice", "ionice"}
+
+// UnmarshalJSON reads the full status, collecting the loaded modules. A
+// module reported as true has no known version; one reported as false or
+// empty is left out.
+func (s *FullStatus) UnmarshalJSON(data []byte) error {
+	type fullStatus FullStatus
+	if err := json.Unmarshal(data, (*fullStatus)(s)); err != nil {
+		return err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, name := range moduleFields {
+		var version string
+		switch value := fields[name].(type) {
+		case string:
+			version = value
+		case bool:
+			if value {
+				version = "loaded"
+			}
+		}
+		if version == "" {
+			continue
+		}
+		if s.Modules == nil {
+			s.Modules = make(map[string]string)
+		}
+		s.Modules[name] = version
+	}
+	return nil
+}
+
+// ServerStatus represents the connection status of a news server
+type ServerStatus struct {
+	Name              string             `json:"servername"`
+	ActiveConnections int                `json:"serveractiveconn"`
+	TotalConnections  int                `json:"servertotalconn"`
+	Active            bool               `json:"serveractive"`
+	Error             string             `json:"servererror"`
+	Priority          int                `json:"serverpriority"`
+	Optional          bool               `json:"serveroptional"`
+	Connections       []ServerConnection `json:"serverconnections"`
+}
+
+// ServerConnection represents an active connection to a news server
+type ServerConnection struct {
+	ThreadNum   int    `json:"thrdnum"`
+	JobName     string `json:"nzo_name"`
+	FileName    string `json:"nzf_name"`
+	ArticleName string `json:"art_name"`
+}
+
+// WarningsResponse represents the warnings response
+type WarningsResponse struct {
+	Warnings []Warning `json:"warnings"`
+}
+
+// Warning represents a warning or error logged by Sabnzbd
+type Warning struct {
+	Text   string `json:"text"`
+	Type   string `json:"type"`
+	Time   int64  `json:"time"`
+	Origin string `json:"origin"`
+}
+
+// ServerStats represents download totals reported by server_stats
+type ServerStats struct {
+	Total   int64                       `json:"total"`
+	Month   int64                       `json:"month"`
+	Week    int64                       `json:"week"`
+	Day     int64                       `json:"day"`
+	Servers map[string]ServerStatistics `json:"servers"`
+}
+
+// ServerStatistics represents download totals for a single news server.
+// Articles are counted per day like Daily.
+type ServerStatistics struct {
+	Total           int64            `json:"total"`
+	Month           int64            `json:"month"`
+	Week            int64            `json:"week"`
+	Day             int64            `json:"day"`
+	Daily           map[string]int64 `json:"daily"`
+	ArticlesTried   map[string]int64 `json:"articles_tried"`
+	ArticlesSuccess map[string]int64 `json:"articles_success"`
+}
+
+// ArticleErrors returns the number of articles the server failed to
+// deliver, over the days it was counted
+func (s ServerStatistics) ArticleErrors() int64 {
+	var errors int64
+	for _, tried := range s.ArticlesTried {
+		errors += tried
+	}
+	for _, success := range s.ArticlesSuccess {
+		errors -= success
+	}
+	return max(0, errors)
+}
+
+// ConfigResponse represents the get_config and set_config response
+type ConfigResponse struct {
+	SabnzbdResponse
+	Config map[string]any `json:"config"`
+}
+
+// ScriptsResponse represents the get_scripts response
+type ScriptsResponse struct {
+	Scripts []string `json:"scripts"`
+}
+
+// RSSConfigResponse represents the rss section of get_config
+type RSSConfigResponse struct {
+	SabnzbdResponse
+	Config struct {
+		RSS []RSSFeed `json:"rss"`
+	} `json:"config"`
+}
+
+// RSSFeed represents an RSS feed configured in Sabnzbd
+type RSSFeed struct {
+	Name     string   `json:"name"`
+	URI      []string `json:"uri"`
+	Category string   `json:"cat"`
+	PP       string   `json:"pp"`
+	Script   string   `json:"script"`
+	Enable   int      `json:"enable"`
+	Priority int      `json:"priority"`
+}
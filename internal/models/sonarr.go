@@ -14,7 +14,7 @@ type Series struct {
 	Year              int              `json:"year"`
 	Path              string           `json:"path"`
 	QualityProfileID  int              `json:"qualityProfileId"`
-	LanguageProfileID int              `json:"languageProfileId"`
+	LanguageProfileID int              `json:"languageProfileId,omitempty"`
 	SeasonFolder      bool             `json:"seasonFolder"`
 	Monitored         bool             `json:"monitored"`
 	UseSceneNumbering bool             `json:"useSceneNumbering"`
@@ -101,6 +101,25 @@ type QualityProfile struct {
 	UpgradeAllowed bool                 `json:"upgradeAllowed"`
 	Cutoff         interface{}          `json:"cutoff"` // Can be int or object
 	Items          []QualityProfileItem `json:"items"`
+
+	// Custom format scores, Sonarr v4 only
+	MinFormatScore    int                 `json:"minFormatScore,omitempty"`
+	CutoffFormatScore int                 `json:"cutoffFormatScore,omitempty"`
+	FormatItems       []ProfileFormatItem `json:"formatItems,omitempty"`
+}
+
+// ProfileFormatItem represents the score of a custom format in a quality
+// profile
+type ProfileFormatItem struct {
+	Format int    `json:"format"`
+	Name   string `json:"name"`
+	Score  int    `json:"score"`
+}
+
+// LanguageProfile represents a language profile, Sonarr v3 only
+type LanguageProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 // QualityProfileItem represents an item in a quality profile
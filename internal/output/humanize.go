@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatBytes formats bytes into human readable format
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// SxE formats a season and episode number as S01E02
+func SxE(season, episode int) string {
+	return fmt.Sprintf("S%02dE%02d", season, episode)
+}
+
+// Ago formats the time since t as "5m ago", "3h ago" or "2d ago", or
+// "in 2d" for times in the future
+func Ago(t time.Time) string {
+	d := time.Since(t)
+	suffix := " ago"
+	prefix := ""
+	if d < 0 {
+		d, prefix, suffix = -d, "in ", ""
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%s%dm%s", prefix, int(d.Minutes()), suffix)
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%s%dh%s", prefix, int(d.Hours()), suffix)
+	default:
+		return fmt.Sprintf("%s%dd%s", prefix, int(d.Hours()/24), suffix)
+	}
+}
@@ -0,0 +1,550 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A jsonpath template is text with {expressions} in it, as kubectl takes:
+//
+//	{.slots[*].nzo_id}
+//	{range .slots[*]}{.nzo_id}{"\t"}{.filename}{"\n"}{end}
+//	{.slots[?(@.status=="Paused")].filename}
+//
+// Expressions use the JSON field names of the output. A template without
+// braces is a single expression.
+
+// nodeKind is the kind of a piece of a jsonpath template
+type nodeKind int
+
+const (
+	textNode nodeKind = iota
+	exprNode
+	rangeNode
+)
+
+// jsonPathNode is literal text, an expression whose results are written,
+// or a range over an expression's results
+type jsonPathNode struct {
+	kind nodeKind
+	text string
+	expr pathExpr
+	body []jsonPathNode
+}
+
+// pathExpr is a path such as .slots[0].nzo_id. Paths start at the current
+// range item, or at the root with $.
+type pathExpr struct {
+	root  bool
+	steps []pathStep
+}
+
+// stepKind is the kind of a step of a path
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepWildcard
+	stepRecursive
+	stepIndex
+	stepSlice
+	stepFilter
+)
+
+// pathStep is a step of a path: .name, .*, ..name, [n], [a:b] or [?(...)]
+type pathStep struct {
+	kind stepKind
+	name string
+
+	index      int
+	start, end *int
+
+	filter *pathFilter
+}
+
+// pathFilter keeps the items of a list for which @path op value holds, or
+// that have @path when there is no op
+type pathFilter struct {
+	path  pathExpr
+	op    string
+	value any
+}
+
+// parseJSONPath parses a jsonpath template
+func parseJSONPath(text string) ([]jsonPathNode, error) {
+	if !strings.Contains(text, "{") {
+		text = "{" + text + "}"
+	}
+
+	var nodes []jsonPathNode
+	// Nodes outside the open ranges, and the ranges themselves
+	var outer [][]jsonPathNode
+	var ranges []jsonPathNode
+
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{kind: textNode, text: text})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{kind: textNode, text: text[:open]})
+		}
+		end := closing(text, open+1, '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid jsonpath: unclosed '{' in %q", text)
+		}
+		action := strings.TrimSpace(text[open+1 : end])
+		text = text[end+1:]
+
+		switch {
+		case action == "end":
+			if len(ranges) == 0 {
+				return nil, fmt.Errorf("invalid jsonpath: {end} without {range}")
+			}
+			node := ranges[len(ranges)-1]
+			node.body = nodes
+			nodes = append(outer[len(outer)-1], node)
+			ranges, outer = ranges[:len(ranges)-1], outer[:len(outer)-1]
+		case strings.HasPrefix(action, "range "):
+			expr, err := parsePath(strings.TrimPrefix(action, "range "))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, jsonPathNode{kind: rangeNode, expr: expr})
+			outer = append(outer, nodes)
+			nodes = nil
+		case strings.HasPrefix(action, `"`) || strings.HasPrefix(action, "'"):
+			literal, err := unquote(action)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonpath: bad string %s", action)
+			}
+			nodes = append(nodes, jsonPathNode{kind: textNode, text: literal})
+		default:
+			expr, err := parsePath(action)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, jsonPathNode{kind: exprNode, expr: expr})
+		}
+	}
+	if len(ranges) > 0 {
+		return nil, fmt.Errorf("invalid jsonpath: {range} without {end}")
+	}
+	return nodes, nil
+}
+
+// parsePath parses a path such as $.slots[*].nzo_id
+func parsePath(s string) (pathExpr, error) {
+	var expr pathExpr
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "$") {
+		expr.root = true
+		s = s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "@")
+	}
+
+	bad := func(reason string) (pathExpr, error) {
+		return pathExpr{}, fmt.Errorf("invalid jsonpath %q: %s", s, reason)
+	}
+	// name reads a field name from s[i:]
+	name := func(i int) (string, int) {
+		end := i
+		for end < len(s) && s[end] != '.' && s[end] != '[' {
+			end++
+		}
+		return s[i:end], end
+	}
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			if strings.HasPrefix(s[i:], "..") {
+				field, next := name(i + 2)
+				if field == "" {
+					return bad("expected a name after '..'")
+				}
+				expr.steps = append(expr.steps, pathStep{kind: stepRecursive, name: field})
+				i = next
+				continue
+			}
+			field, next := name(i + 1)
+			switch field {
+			case "":
+				// "." alone is the current item, ".[0]" is an index
+			case "*":
+				expr.steps = append(expr.steps, pathStep{kind: stepWildcard})
+			default:
+				expr.steps = append(expr.steps, pathStep{kind: stepField, name: field})
+			}
+			i = next
+		case '[':
+			end := closing(s, i+1, ']')
+			if end < 0 {
+				return bad("unclosed '['")
+			}
+			step, err := parseBracket(strings.TrimSpace(s[i+1 : end]))
+			if err != nil {
+				return bad(err.Error())
+			}
+			expr.steps = append(expr.steps, step)
+			i = end + 1
+		default:
+			if i > 0 {
+				return bad(fmt.Sprintf("unexpected %q", s[i]))
+			}
+			// A leading name without a dot
+			field, next := name(i)
+			expr.steps = append(expr.steps, pathStep{kind: stepField, name: field})
+			i = next
+		}
+	}
+	return expr, nil
+}
+
+// parseBracket parses what is between the brackets of [*], [n], [a:b],
+// ['name'] or [?(...)]
+func parseBracket(s string) (pathStep, error) {
+	switch {
+	case s == "*":
+		return pathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(s, "'") || strings.HasPrefix(s, `"`):
+		field, err := unquote(s)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("bad name %s", s)
+		}
+		return pathStep{kind: stepField, name: field}, nil
+	case strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")"):
+		filter, err := parseFilter(s[2 : len(s)-1])
+		if err != nil {
+			return pathStep{}, err
+		}
+		return pathStep{kind: stepFilter, filter: filter}, nil
+	case strings.Contains(s, ":"):
+		from, to, _ := strings.Cut(s, ":")
+		step := pathStep{kind: stepSlice}
+		for _, bound := range []struct {
+			text string
+			dest **int
+		}{{from, &step.start}, {to, &step.end}} {
+			if bound.text = strings.TrimSpace(bound.text); bound.text == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound.text)
+			if err != nil {
+				return pathStep{}, fmt.Errorf("bad slice [%s]", s)
+			}
+			*bound.dest = &n
+		}
+		return step, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return pathStep{}, fmt.Errorf("bad index [%s]", s)
+	}
+	return pathStep{kind: stepIndex, index: n}, nil
+}
+
+// filterOps are the comparisons of a filter, longest first
+var filterOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses @.path op value, or @.path alone
+func parseFilter(s string) (*pathFilter, error) {
+	left, op, right := s, "", ""
+	for _, candidate := range filterOps {
+		if i := strings.Index(s, candidate); i >= 0 {
+			left, op, right = s[:i], candidate, s[i+len(candidate):]
+			break
+		}
+	}
+	left = strings.TrimSpace(left)
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter %q must start with @", s)
+	}
+	path, err := parsePath(left)
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{path: path, op: op}
+	if op == "" {
+		return filter, nil
+	}
+
+	right = strings.TrimSpace(right)
+	switch {
+	case strings.HasPrefix(right, "'") || strings.HasPrefix(right, `"`):
+		filter.value, err = unquote(right)
+	case right == "true" || right == "false":
+		filter.value = right == "true"
+	case right == "null":
+	default:
+		filter.value, err = strconv.ParseFloat(right, 64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad value %s in filter", right)
+	}
+	return filter, nil
+}
+
+// closing returns the index of the end character that closes a bracket
+// opened before s[start], skipping quoted strings and nested brackets
+func closing(s string, start int, end byte) int {
+	var quote byte
+	depth := 0
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case depth > 0 && (c == ']' || c == ')'):
+			depth--
+		case c == end:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote reads a single- or double-quoted string
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2 {
+		return strings.ReplaceAll(s[1:len(s)-1], `\'`, "'"), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// writeJSONPath writes the jsonpath template text filled in from data
+func writeJSONPath(w io.Writer, text string, data any) error {
+	nodes, err := parseJSONPath(text)
+	if err != nil {
+		return err
+	}
+	// Expressions work on the JSON encoding, so they use its field names
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var root any
+	if err := decoder.Decode(&root); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if err := execJSONPath(&b, nodes, root, root); err != nil {
+		return err
+	}
+	return writeLine(w, b.String())
+}
+
+// execJSONPath writes nodes for the current item
+func execJSONPath(b *strings.Builder, nodes []jsonPathNode, root, current any) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case textNode:
+			b.WriteString(node.text)
+		case exprNode:
+			values := node.expr.eval(root, current)
+			for i, value := range values {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				text, err := jsonPathText(value)
+				if err != nil {
+					return err
+				}
+				b.WriteString(text)
+			}
+		case rangeNode:
+			for _, item := range node.expr.eval(root, current) {
+				if err := execJSONPath(b, node.body, root, item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonPathText returns strings and numbers as they are and anything else
+// as JSON
+func jsonPathText(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// eval returns the values the path leads to. Missing fields and indexes
+// out of range lead nowhere rather than being errors.
+func (e pathExpr) eval(root, current any) []any {
+	values := []any{current}
+	if e.root {
+		values = []any{root}
+	}
+	for _, step := range e.steps {
+		var next []any
+		for _, value := range values {
+			next = step.apply(next, value, root)
+		}
+		values = next
+	}
+	return values
+}
+
+// apply appends the values step leads to from value
+func (step pathStep) apply(values []any, value, root any) []any {
+	switch step.kind {
+	case stepField:
+		if object, ok := value.(map[string]any); ok {
+			if field, ok := object[step.name]; ok {
+				values = append(values, field)
+			}
+		}
+	case stepWildcard:
+		values = append(values, children(value)...)
+	case stepRecursive:
+		for _, descendant := range descendants(value) {
+			values = pathStep{kind: stepField, name: step.name}.apply(values, descendant, root)
+		}
+	case stepIndex:
+		list, _ := value.([]any)
+		index := step.index
+		if index < 0 {
+			index += len(list)
+		}
+		if index >= 0 && index < len(list) {
+			values = append(values, list[index])
+		}
+	case stepSlice:
+		list, _ := value.([]any)
+		start, end := 0, len(list)
+		if step.start != nil {
+			start = bound(*step.start, len(list))
+		}
+		if step.end != nil {
+			end = bound(*step.end, len(list))
+		}
+		if start < end {
+			values = append(values, list[start:end]...)
+		}
+	case stepFilter:
+		for _, item := range children(value) {
+			if step.filter.match(root, item) {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// bound clamps a slice bound to a list of length n, counting negative
+// bounds from the end
+func bound(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
+
+// children returns the items of a list or the values of an object, in key
+// order
+func children(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]any, len(keys))
+		for i, key := range keys {
+			items[i] = v[key]
+		}
+		return items
+	}
+	return nil
+}
+
+// descendants returns value and everything below it
+func descendants(value any) []any {
+	all := []any{value}
+	for _, child := range children(value) {
+		all = append(all, descendants(child)...)
+	}
+	return all
+}
+
+// match reports whether item passes the filter
+func (f *pathFilter) match(root, item any) bool {
+	values := f.path.eval(root, item)
+	if f.op == "" {
+		return len(values) > 0
+	}
+	if len(values) == 0 {
+		return false
+	}
+	left := values[0]
+	if n, ok := left.(json.Number); ok {
+		left, _ = n.Float64()
+	}
+
+	if l, ok := left.(float64); ok {
+		if r, ok := f.value.(float64); ok {
+			return compare(f.op, l < r, l == r)
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := f.value.(string); ok {
+			return compare(f.op, l < r, l == r)
+		}
+	}
+	switch f.op {
+	case "==":
+		return left == f.value
+	case "!=":
+		return left != f.value
+	}
+	return false
+}
+
+// compare applies op given whether the left side is less than or equal to
+// the right
+func compare(op string, less, equal bool) bool {
+	switch op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
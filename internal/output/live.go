@@ -0,0 +1,90 @@
+package output
+
+import (
+	"io"
+	"strings"
+)
+
+// ANSI escape sequences for redrawing the screen
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	cursorHome   = "\x1b[H"
+	clearLine    = "\x1b[K"
+	clearDown    = "\x1b[J"
+)
+
+// Live draws frames that replace each other, for --watch. On a terminal
+// frames are redrawn in place on the alternate screen, as watch(1) does,
+// and the last one is printed again on Close so that it stays visible.
+// Elsewhere frames are written one after another.
+type Live struct {
+	w       io.Writer
+	inPlace bool
+	last    string
+	drawn   bool
+}
+
+// NewLive returns a Live writing to w, redrawing in place when inPlace
+// is set
+func NewLive(w io.Writer, inPlace bool) *Live {
+	return &Live{w: w, inPlace: inPlace}
+}
+
+// Draw replaces the previous frame with frame
+func (l *Live) Draw(frame string) error {
+	var b strings.Builder
+	switch {
+	case l.inPlace:
+		if !l.drawn {
+			b.WriteString(altScreenOn + hideCursor)
+		}
+		b.WriteString(cursorHome)
+		// Clearing each line as it is overwritten rather than the whole
+		// screen first avoids flicker
+		for _, line := range strings.SplitAfter(frame, "\n") {
+			if text, ok := strings.CutSuffix(line, "\n"); ok {
+				b.WriteString(text + clearLine + "\n")
+			} else {
+				b.WriteString(line)
+			}
+		}
+		b.WriteString(clearDown)
+	default:
+		b.WriteString(frame)
+	}
+	l.last, l.drawn = frame, true
+	_, err := io.WriteString(l.w, b.String())
+	return err
+}
+
+// Close leaves the alternate screen and prints the last frame
+func (l *Live) Close() error {
+	if !l.inPlace || !l.drawn {
+		return nil
+	}
+	_, err := io.WriteString(l.w, showCursor+altScreenOff+l.last)
+	return err
+}
+
+// sparks are the bars of a sparkline, lowest first
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of bars scaled to the largest value
+func Sparkline(values []float64) string {
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if peak > 0 && v > 0 {
+			i = min(int(v/peak*float64(len(sparks)-1)+0.5), len(sparks)-1)
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,207 @@
+// Package output renders command results as aligned tables, JSON, YAML,
+// CSV, NDJSON, Go templates or JSONPath, as selected by the global
+// --output flag
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format. The template and jsonpath formats carry
+// their template after an equals sign, as in "jsonpath={.slots[*].nzo_id}".
+type Format string
+
+// Output formats. FormatWide is a table with extra columns that are never
+// truncated to the terminal width.
+const (
+	FormatTable    Format = "table"
+	FormatWide     Format = "wide"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatNDJSON   Format = "ndjson"
+	FormatTemplate Format = "template"
+	FormatJSONPath Format = "jsonpath"
+)
+
+// Formats lists every output format, for help and completion
+var Formats = []Format{FormatTable, FormatWide, FormatJSON, FormatYAML, FormatCSV, FormatNDJSON, FormatTemplate, FormatJSONPath}
+
+// TakesTemplate reports whether the format is followed by a template
+func (f Format) TakesTemplate() bool {
+	return f == FormatTemplate || f == FormatJSONPath
+}
+
+// ParseFormat returns the format named s. Templates are parsed here so
+// that mistakes are reported before any request is made.
+func ParseFormat(s string) (Format, error) {
+	name, text, hasText := strings.Cut(s, "=")
+	for _, format := range Formats {
+		if !strings.EqualFold(name, string(format)) {
+			continue
+		}
+		if !format.TakesTemplate() {
+			if hasText {
+				return "", fmt.Errorf("output format '%s' takes no template", format)
+			}
+			return format, nil
+		}
+		if text == "" {
+			return "", fmt.Errorf("output format '%s' needs a template, as in %s='...'", format, format)
+		}
+		var err error
+		if format == FormatTemplate {
+			_, err = parseTemplate(text)
+		} else {
+			_, err = parseJSONPath(text)
+		}
+		if err != nil {
+			return "", err
+		}
+		return format + "=" + Format(text), nil
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+		if format.TakesTemplate() {
+			names[i] += "=..."
+		}
+	}
+	return "", fmt.Errorf("unknown output format '%s' (expected %s)", s, strings.Join(names, ", "))
+}
+
+// Kind returns the format without its template
+func (f Format) Kind() Format {
+	kind, _, _ := strings.Cut(string(f), "=")
+	return Format(kind)
+}
+
+// template returns the template of a template or jsonpath format
+func (f Format) template() string {
+	_, text, _ := strings.Cut(string(f), "=")
+	return text
+}
+
+// String implements pflag.Value
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set implements pflag.Value, so an unknown format is a flag error
+func (f *Format) Set(s string) error {
+	format, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
+// Type implements pflag.Value
+func (f *Format) Type() string {
+	return "format"
+}
+
+// Text reports whether the format is for people rather than scripts, so
+// that commands can print messages such as "No series found"
+func (f Format) Text() bool {
+	return f == FormatTable || f == FormatWide
+}
+
+// Print renders data or table to stdout; see Render
+func Print(format Format, data any, table *Table) error {
+	return Render(os.Stdout, format, data, table)
+}
+
+// Render writes table as text or CSV, or data as JSON, YAML, NDJSON or
+// through a template. NDJSON writes each element of a slice on its own
+// line. A nil slice is written as an empty list rather than null. Without
+// a table, data is shown as a Record.
+func Render(w io.Writer, format Format, data any, table *Table) error {
+	data = emptyList(data)
+	if table == nil {
+		table = Record(data)
+	}
+	switch format.Kind() {
+	case FormatTemplate:
+		return writeTemplate(w, format.template(), data)
+	case FormatJSONPath:
+		return writeJSONPath(w, format.template(), data)
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(data)
+	case FormatNDJSON:
+		return writeNDJSON(w, data)
+	case FormatYAML:
+		return writeYAML(w, data)
+	case FormatCSV:
+		return table.writeCSV(csv.NewWriter(w))
+	case FormatWide:
+		return table.write(w, true, 0)
+	default:
+		return table.write(w, false, Width())
+	}
+}
+
+// emptyList replaces a nil slice with an empty one
+func emptyList(data any) any {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return data
+}
+
+// writeNDJSON writes each element of a slice, or data itself, as a line
+// of JSON
+func writeNDJSON(w io.Writer, data any) error {
+	encoder := json.NewEncoder(w)
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return encoder.Encode(data)
+	}
+	for i := range v.Len() {
+		if err := encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes data as YAML with the field names and order of its
+// JSON encoding, since models only carry json tags
+func writeYAML(w io.Writer, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML, so the node keeps the key order
+	var node yaml.Node
+	if err := yaml.Unmarshal(encoded, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow and quoting styles of JSON, leaving the
+// encoder to quote only strings that need it
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,281 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Inner struct {
+		Path string `json:"path"`
+	} `json:"inner"`
+}
+
+func render(t *testing.T, format Format, data any, table *Table) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Render(&b, format, data, table); err != nil {
+		t.Fatalf("Render(%s): %v", format, err)
+	}
+	return b.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"table", "wide", "json", "yaml", "csv", "ndjson", "JSON"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q): %v", name, err)
+		}
+	}
+	_, err := ParseFormat("xml")
+	if err == nil || !strings.Contains(err.Error(), "table, wide, json, yaml, csv, ndjson") {
+		t.Errorf("ParseFormat(xml) = %v, want list of formats", err)
+	}
+
+	var f Format
+	if err := f.Set("Yaml"); err != nil || f != FormatYAML {
+		t.Errorf("Set(Yaml) = %v, %q", err, f)
+	}
+	if FormatJSON.Text() || !FormatWide.Text() {
+		t.Error("Text() should only hold for table and wide")
+	}
+}
+
+func TestTable(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	table := NewTable("ID", "NAME").Wide("PATH")
+	table.Row(1, "Breaking Bad", "/tv/Breaking Bad")
+	table.Row(22, "Lost", nil)
+
+	want := "ID   NAME\n1    Breaking Bad\n22   Lost\n"
+	if got := render(t, FormatTable, nil, table); got != want {
+		t.Errorf("table:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = "ID   NAME           PATH\n1    Breaking Bad   /tv/Breaking Bad\n22   Lost\n"
+	if got := render(t, FormatWide, nil, table); got != want {
+		t.Errorf("wide:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = "id,name,path\n1,Breaking Bad,/tv/Breaking Bad\n22,Lost,\n"
+	if got := render(t, FormatCSV, nil, table); got != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTableFit(t *testing.T) {
+	t.Setenv("COLUMNS", "24")
+	table := NewTable("ID", "TITLE")
+	table.Row(1, "The Long Dark Tea-Time of the Soul")
+
+	got := render(t, FormatTable, nil, table)
+	for _, line := range strings.Split(strings.TrimSpace(got), "\n") {
+		if len(line) > 24 {
+			t.Errorf("line %q is wider than 24 columns", line)
+		}
+	}
+	if !strings.Contains(got, "1    The Long Dark Te...") {
+		t.Errorf("expected truncated title, got:\n%s", got)
+	}
+
+	// Wide output is never truncated
+	if got := render(t, FormatWide, nil, table); !strings.Contains(got, "of the Soul") {
+		t.Errorf("wide output was truncated:\n%s", got)
+	}
+}
+
+func TestStripColor(t *testing.T) {
+	colors = true
+	defer func() { colors = false }()
+
+	if got := Green("ok"); got == "ok" || StripColor(got) != "ok" {
+		t.Errorf("Green(ok) = %q", got)
+	}
+	if textWidth(Red("error")) != 5 {
+		t.Errorf("textWidth should ignore escape sequences")
+	}
+
+	table := NewTable("STATUS")
+	table.Row(Red("failed"))
+	if got := render(t, FormatCSV, nil, table); got != "status\nfailed\n" {
+		t.Errorf("csv kept colors: %q", got)
+	}
+}
+
+func TestStructuredFormats(t *testing.T) {
+	items := []item{{ID: 1, Name: "a"}, {ID: 2, Name: "b", Tags: []string{"x"}}}
+
+	if got := render(t, FormatJSON, []item(nil), nil); got != "[]\n" {
+		t.Errorf("nil slice as json = %q", got)
+	}
+
+	got := render(t, FormatNDJSON, items, nil)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], `{"id":2,"name":"b"`) {
+		t.Errorf("ndjson:\n%s", got)
+	}
+
+	got = render(t, FormatYAML, items[1], nil)
+	want := "id: 2\nname: b\ntags:\n  - x\ninner:\n  path: \"\"\n"
+	if got != want {
+		t.Errorf("yaml:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	got := render(t, FormatTable, item{ID: 7, Name: "x", Tags: []string{"a", "b"}}, nil)
+	want := "ID   NAME   TAGS\n7    x      a b\n"
+	if got != want {
+		t.Errorf("struct record:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = render(t, FormatCSV, map[string]any{"b": 2, "a": "one"}, nil)
+	if got != "a,b\none,2\n" {
+		t.Errorf("map record = %q", got)
+	}
+
+	got = render(t, FormatTable, "hello", nil)
+	if got != "VALUE\nhello\n" {
+		t.Errorf("scalar record = %q", got)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	items := []item{{ID: 1, Name: "a", Tags: []string{"x", "y"}}, {ID: 2, Name: "b"}}
+
+	got := render(t, mustFormat(t, "template={{range .}}{{.ID}}={{.Name}} {{end}}"), items, nil)
+	if got != "1=a 2=b \n" {
+		t.Errorf("template = %q", got)
+	}
+
+	tests := []struct {
+		template string
+		data     any
+		want     string
+	}{
+		{`{{humanBytes .}}`, int64(3 * 1024 * 1024), "3.0 MB\n"},
+		{`{{humanBytes .}}`, "2048", "2.0 KB\n"},
+		{`{{sxe 1 2}}`, nil, "S01E02\n"},
+		{`{{ago .}}`, time.Now().Add(-3 * time.Hour).Unix(), "3h ago\n"},
+		{`{{ago .}}`, time.Now().Add(-49 * time.Hour).Format(time.RFC3339), "2d ago\n"},
+		{`{{ago .}}`, "", ""},
+		{`{{json .Tags}}`, items[0], "[\"x\",\"y\"]\n"},
+	}
+	for _, test := range tests {
+		got := render(t, mustFormat(t, "template="+test.template), test.data, nil)
+		if got != test.want {
+			t.Errorf("%s on %v = %q, want %q", test.template, test.data, got, test.want)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := Render(&b, mustFormat(t, "template={{humanBytes .}}"), "lots", nil); err == nil {
+		t.Error("humanBytes should reject text")
+	}
+	if _, err := ParseFormat("template={{.ID"); err == nil {
+		t.Error("ParseFormat should reject a broken template")
+	}
+	if _, err := ParseFormat("template="); err == nil {
+		t.Error("ParseFormat should require a template")
+	}
+	if _, err := ParseFormat("json=x"); err == nil {
+		t.Error("ParseFormat should reject a template for json")
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	data := map[string]any{
+		"slots": []map[string]any{
+			{"nzo_id": "a", "status": "Paused", "mb": 100, "tags": []string{"x"}},
+			{"nzo_id": "b", "status": "Queued", "mb": 2.5},
+			{"nzo_id": "c", "status": "Queued", "mb": 300},
+		},
+		"paused": true,
+		"name":   "q",
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`{.slots[*].nzo_id}`, "a b c"},
+		{`.slots[*].nzo_id`, "a b c"},
+		{`{$.slots[0].nzo_id}`, "a"},
+		{`{.slots[-1].nzo_id}`, "c"},
+		{`{.slots[5].nzo_id}`, ""},
+		{`{.slots[1:].nzo_id}`, "b c"},
+		{`{.slots[:2].mb}`, "100 2.5"},
+		{`{.slots[?(@.status=="Queued")].nzo_id}`, "b c"},
+		{`{.slots[?(@.status!='Queued')].nzo_id}`, "a"},
+		{`{.slots[?(@.mb>50)].nzo_id}`, "a c"},
+		{`{.slots[?(@.tags)].nzo_id}`, "a"},
+		{`{..nzo_id}`, "a b c"},
+		{`{.paused} {.missing}`, "true "},
+		{`{.slots[0].tags}`, `["x"]`},
+		{`{['name']}`, "q"},
+		{`{range .slots[*]}{.nzo_id}:{$.name}{"\n"}{end}`, "a:q\nb:q\nc:q"},
+		{`{range .slots[?(@.mb<10)]}[{.nzo_id}]{end}`, "[b]"},
+	}
+	for _, test := range tests {
+		got := render(t, mustFormat(t, "jsonpath="+test.path), data, nil)
+		want := test.want
+		if want != "" {
+			want += "\n"
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", test.path, got, want)
+		}
+	}
+
+	for _, path := range []string{"{.slots[", "{range .slots}", "{end}", "{.slots[x]}", "{.slots[?(.a==1)]}"} {
+		if _, err := ParseFormat("jsonpath=" + path); err == nil {
+			t.Errorf("ParseFormat should reject %s", path)
+		}
+	}
+}
+
+func mustFormat(t *testing.T, s string) Format {
+	t.Helper()
+	format, err := ParseFormat(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return format
+}
+
+func TestLive(t *testing.T) {
+	var b bytes.Buffer
+	live := NewLive(&b, false)
+	live.Draw("one\n")
+	live.Draw("two\n")
+	live.Close()
+	if b.String() != "one\ntwo\n" {
+		t.Errorf("appended frames = %q", b.String())
+	}
+
+	b.Reset()
+	live = NewLive(&b, true)
+	live.Draw("a\nb\n")
+	live.Draw("c\n")
+	live.Close()
+	want := altScreenOn + hideCursor + cursorHome + "a" + clearLine + "\nb" + clearLine + "\n" + clearDown +
+		cursorHome + "c" + clearLine + "\n" + clearDown +
+		showCursor + altScreenOff + "c\n"
+	if b.String() != want {
+		t.Errorf("redrawn frames = %q, want %q", b.String(), want)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]float64{0, 1, 2, 4, 8}); got != "▁▂▃▅█" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	if got := Sparkline([]float64{0, 0}); got != "▁▁" {
+		t.Errorf("Sparkline of zeros = %q", got)
+	}
+}
@@ -0,0 +1,255 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// columnGap separates the columns of a table
+const columnGap = "   "
+
+// minColumnWidth is the narrowest a column is truncated to when a table
+// doesn't fit the terminal
+const minColumnWidth = 8
+
+// column is a table column; wide columns are only shown by -o wide and CSV
+type column struct {
+	header string
+	wide   bool
+}
+
+// Table is a list of rows with a header, rendered as aligned text or CSV
+type Table struct {
+	columns []column
+	rows    [][]string
+}
+
+// NewTable returns a table with the given column headers
+func NewTable(headers ...string) *Table {
+	t := &Table{}
+	for _, header := range headers {
+		t.columns = append(t.columns, column{header: header})
+	}
+	return t
+}
+
+// Wide adds columns that are only shown by -o wide and CSV. Rows give
+// their values after those of the other columns.
+func (t *Table) Wide(headers ...string) *Table {
+	for _, header := range headers {
+		t.columns = append(t.columns, column{header: header, wide: true})
+	}
+	return t
+}
+
+// Row adds a row with a value for every column, formatted with fmt.Sprint
+func (t *Table) Row(values ...any) {
+	row := make([]string, len(t.columns))
+	for i := range row {
+		if i < len(values) && values[i] != nil {
+			row[i] = fmt.Sprint(values[i])
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// write writes the header and rows with aligned columns. Unless wide,
+// wide columns are left out and, when width is set, the widest columns are
+// truncated until the table fits.
+func (t *Table) write(w io.Writer, wide bool, width int) error {
+	var shown []int
+	for i, col := range t.columns {
+		if wide || !col.wide {
+			shown = append(shown, i)
+		}
+	}
+
+	widths := make([]int, len(shown))
+	for j, i := range shown {
+		widths[j] = textWidth(t.columns[i].header)
+		for _, row := range t.rows {
+			widths[j] = max(widths[j], textWidth(row[i]))
+		}
+	}
+	if width > 0 {
+		fit(widths, width)
+	}
+
+	line := func(cells []string, style func(string) string) error {
+		var b strings.Builder
+		for j, i := range shown {
+			cell := cells[i]
+			if textWidth(cell) > widths[j] {
+				cell = truncate(StripColor(cell), widths[j])
+			}
+			b.WriteString(style(cell))
+			if j < len(shown)-1 {
+				b.WriteString(strings.Repeat(" ", widths[j]-textWidth(cell)))
+				b.WriteString(columnGap)
+			}
+		}
+		// Empty cells at the end leave padding behind
+		_, err := io.WriteString(w, strings.TrimRight(b.String(), " ")+"\n")
+		return err
+	}
+
+	headers := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		headers[i] = col.header
+	}
+	if err := line(headers, Bold); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := line(row, plain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes every column, with lower-case headers and without colors
+func (t *Table) writeCSV(w *csv.Writer) error {
+	headers := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		headers[i] = strings.ReplaceAll(strings.ToLower(col.header), " ", "_")
+	}
+	w.Write(headers)
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = StripColor(cell)
+		}
+		w.Write(cells)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// fit narrows the widest columns until they and the gaps between them fit
+// in width, or every column is down to minColumnWidth
+func fit(widths []int, width int) {
+	total := len(columnGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for j, w := range widths {
+			if w > widths[widest] {
+				widest = j
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// textWidth returns the number of characters shown for s
+func textWidth(s string) int {
+	return utf8.RuneCountInString(StripColor(s))
+}
+
+// truncate shortens s to width characters, marking the cut with "..."
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// plain returns s unchanged
+func plain(s string) string {
+	return s
+}
+
+// Record returns a table with a single row holding the fields of a struct,
+// the entries of a map or a single value, for results that are not a
+// list. Nested structs and maps are left out and slices are joined with
+// spaces.
+func Record(data any) *Table {
+	t := &Table{}
+	var values []any
+
+	v := reflect.Indirect(reflect.ValueOf(data))
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" || !scalar(v.Field(i)) {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			t.columns = append(t.columns, column{header: strings.ToUpper(name)})
+			values = append(values, cellValue(v.Field(i)))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			value := reflect.ValueOf(v.MapIndex(key).Interface())
+			if !scalar(value) {
+				continue
+			}
+			t.columns = append(t.columns, column{header: strings.ToUpper(fmt.Sprint(key))})
+			values = append(values, cellValue(value))
+		}
+	case reflect.Invalid:
+	default:
+		t.columns = append(t.columns, column{header: "VALUE"})
+		values = append(values, cellValue(v))
+	}
+
+	t.Row(values...)
+	return t
+}
+
+// scalar reports whether v can be shown in a single cell
+func scalar(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Invalid:
+		return false
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil() && scalar(v.Elem())
+	case reflect.Slice:
+		kind := v.Type().Elem().Kind()
+		return kind != reflect.Struct && kind != reflect.Map && kind != reflect.Pointer
+	}
+	return true
+}
+
+// cellValue returns v for a cell, joining slices with spaces
+func cellValue(v reflect.Value) any {
+	v = reflect.Indirect(v)
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return v.Interface()
+	}
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(items, " ")
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are the helpers available to -o template
+var templateFuncs = template.FuncMap{
+	"humanBytes": func(v any) (string, error) {
+		n, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		return FormatBytes(int64(n)), nil
+	},
+	"ago": func(v any) (string, error) {
+		t, err := toTime(v)
+		if err != nil || t.IsZero() {
+			return "", err
+		}
+		return Ago(t), nil
+	},
+	"sxe": func(season, episode any) (string, error) {
+		s, err := toFloat(season)
+		if err != nil {
+			return "", err
+		}
+		e, err := toFloat(episode)
+		if err != nil {
+			return "", err
+		}
+		return SxE(int(s), int(e)), nil
+	},
+	"json": func(v any) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// parseTemplate parses the text of -o template
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate executes a Go template on data, which keeps the Go field
+// names of the models (.Title, .Statistics.SizeOnDisk)
+func writeTemplate(w io.Writer, text string, data any) error {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return writeLine(w, b.String())
+}
+
+// writeLine writes s, ending it with a newline if it has none
+func writeLine(w io.Writer, s string) error {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// toFloat converts a number, or a string holding one, as Sabnzbd sends
+// most of its numbers
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("not a number: %q", n)
+		}
+		return f, nil
+	case json.Number:
+		return n.Float64()
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), nil
+	case rv.CanUint():
+		return float64(rv.Uint()), nil
+	case rv.CanFloat():
+		return rv.Float(), nil
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
+
+// toTime converts a time, a Unix timestamp or a date string as sent by
+// Sonarr; an empty string is the zero time
+func toTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, nil
+		}
+		return *t, nil
+	case string:
+		if t == "" {
+			return time.Time{}, nil
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("not a time: %q", t)
+	}
+	seconds, err := toFloat(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not a time: %v", v)
+	}
+	if seconds == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ANSI escape sequences for the colors used in tables
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	faint  = "\x1b[2m"
+)
+
+// ansi matches ANSI escape sequences
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// colors is set by SetColors
+var colors bool
+
+// SetColors turns colors on when enabled is set, NO_COLOR is unset and
+// stdout is a terminal; see https://no-color.org
+func SetColors(enabled bool) {
+	colors = enabled && os.Getenv("NO_COLOR") == "" && IsTerminal()
+}
+
+// Colors reports whether output is colored
+func Colors() bool {
+	return colors
+}
+
+// IsTerminal reports whether stdout is a terminal
+func IsTerminal() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// Width returns the width of the terminal, or 0 if stdout is not a
+// terminal. COLUMNS overrides it.
+func Width() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if !IsTerminal() {
+		return 0
+	}
+	return terminalWidth(os.Stdout.Fd())
+}
+
+// Bold, Red, Green, Yellow and Faint color s when colors are on
+func Bold(s string) string   { return paint(bold, s) }
+func Red(s string) string    { return paint(red, s) }
+func Green(s string) string  { return paint(green, s) }
+func Yellow(s string) string { return paint(yellow, s) }
+func Faint(s string) string  { return paint(faint, s) }
+
+// paint wraps s in the escape sequence code when colors are on
+func paint(code, s string) string {
+	if !colors || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+// StripColor removes ANSI escape sequences from s
+func StripColor(s string) string {
+	return ansi.ReplaceAllString(s, "")
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package output
+
+// terminalWidth returns 0, leaving tables untruncated, where the terminal
+// size can't be read; COLUMNS still applies
+func terminalWidth(fd uintptr) int {
+	return 0
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package output
+
+import "golang.org/x/sys/unix"
+
+// terminalWidth returns the number of columns of the terminal fd, or 0
+func terminalWidth(fd uintptr) int {
+	size, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(size.Col)
+}
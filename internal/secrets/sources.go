@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Command runs a shell command such as "pass show sonarr" and returns the
+// first line of its output
+func Command(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("'%s' failed: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("'%s' failed: %w", command, err)
+	}
+
+	// Like git credential helpers, only the first line is the secret
+	line, _, _ := strings.Cut(string(out), "\n")
+	return trimNewline(line), nil
+}
+
+// File reads a secret from a file such as a Docker secret, ignoring
+// surrounding whitespace
+func File(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// trimNewline removes a trailing line ending
+func trimNewline(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
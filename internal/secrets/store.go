@@ -0,0 +1,225 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+
+	"sonarr-sabnzbd-cli/internal/term"
+)
+
+// ErrWrongPassphrase is returned when a store cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secret store")
+
+// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+const pbkdf2Iterations = 600000
+
+// storeFile is the on-disk format of an encrypted store
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Store is a set of named secrets encrypted with a passphrase using
+// AES-256-GCM and a PBKDF2 derived key
+type Store struct {
+	path       string
+	passphrase string
+	secrets    map[string]string
+}
+
+// Open decrypts the store at path. A missing file opens an empty store
+// that is created on Save.
+func Open(path, passphrase string) (*Store, error) {
+	s := &Store{path: path, passphrase: passphrase, secrets: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse secret store: %w", err)
+	}
+	if file.Version != 1 {
+		return nil, fmt.Errorf("unsupported secret store version %d", file.Version)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plain, &s.secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secret store: %w", err)
+	}
+	return s, nil
+}
+
+// Get returns the secret stored under name
+func (s *Store) Get(name string) (string, bool) {
+	value, ok := s.secrets[name]
+	return value, ok
+}
+
+// Set stores a secret under name
+func (s *Store) Set(name, value string) {
+	s.secrets[name] = value
+}
+
+// Delete removes the secret stored under name
+func (s *Store) Delete(name string) bool {
+	_, ok := s.secrets[name]
+	delete(s.secrets, name)
+	return ok
+}
+
+// Names returns the names of all stored secrets in alphabetical order
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.secrets))
+	for name := range s.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the store with a fresh salt and nonce and writes it with
+// owner-only permissions
+func (s *Store) Save() error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	file := storeFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(s.passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secret store directory: %w", err)
+	}
+	return WriteFile(s.path, data)
+}
+
+// newGCM derives a key from the passphrase and salt
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("secret store passphrase is empty")
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteFile atomically replaces path with data readable only by the owner
+func WriteFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// PassphraseEnv is the environment variable holding the store passphrase
+const PassphraseEnv = "SONCLI_PASSPHRASE"
+
+// noPrompts is set by SetPrompts
+var noPrompts bool
+
+// SetPrompts allows or forbids asking for the passphrase, which is
+// forbidden where nobody would see the question, such as during shell
+// completion
+func SetPrompts(enabled bool) {
+	noPrompts = !enabled
+}
+
+// Passphrase returns the store passphrase from SONCLI_PASSPHRASE, from the
+// output of command, or by prompting when stdin is a terminal
+func Passphrase(command string) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if command != "" {
+		return Command(command)
+	}
+
+	if noPrompts || !IsTerminal() {
+		return "", fmt.Errorf("secret store is locked: set %s or secrets.passphrase_cmd", PassphraseEnv)
+	}
+	return ReadSecret("🔑 Secret store passphrase: ")
+}
+
+// IsTerminal reports whether stdin is a terminal
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadSecret prints prompt to stderr and reads a line from stdin without
+// echoing it. Ctrl-C ends the prompt with context.Canceled, restoring the
+// terminal and leaving the rest of stdin unread.
+func ReadSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	line, err := term.ReadPassword(ctx, int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return trimNewline(string(line)), nil
+}
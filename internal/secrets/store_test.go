@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "secrets.enc")
+
+	store, err := Open(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("sonarr.api_key", "abc123")
+	store.Set("sabnzbd.api_key", "def456")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("store permissions = %o, want 600", perm)
+	}
+
+	store, err = Open(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get("sonarr.api_key"); got != "abc123" {
+		t.Errorf("Get(sonarr.api_key) = %q, want abc123", got)
+	}
+	if names := store.Names(); len(names) != 2 || names[0] != "sabnzbd.api_key" {
+		t.Errorf("Names() = %v", names)
+	}
+
+	if _, err := Open(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open with wrong passphrase: got %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	got, err := Command("printf 'first\\nsecond\\n'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "first" {
+		t.Errorf("Command() = %q, want first", got)
+	}
+
+	if _, err := Command("echo denied >&2; exit 1"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("failing command: got %v, want error with stderr", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("  abc123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "abc123" {
+		t.Errorf("File() = %q, want abc123", got)
+	}
+}
+
+func TestReadSecretInterrupted(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	// Keep interrupts from ending the test binary while none is registered
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, os.Interrupt)
+	defer signal.Stop(caught)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ReadSecret("")
+		done <- err
+	}()
+
+	self, _ := os.FindProcess(os.Getpid())
+	deadline := time.After(5 * time.Second)
+	for {
+		if err := self.Signal(os.Interrupt); err != nil {
+			t.Skipf("cannot interrupt the test: %v", err)
+		}
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("ReadSecret error = %v, want context.Canceled", err)
+			}
+			// The cancelled prompt must not keep reading stdin
+			fmt.Fprintln(w, "next")
+			if got, err := ReadSecret(""); err != nil || got != "next" {
+				t.Errorf("ReadSecret after interrupt = %q, %v, want next", got, err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("ReadSecret did not return on interrupt")
+		}
+	}
+}
+
+func TestSetPrompts(t *testing.T) {
+	// /dev/null is a character device, so it passes for a terminal
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+	stdin := os.Stdin
+	os.Stdin = null
+	defer func() { os.Stdin = stdin }()
+	t.Setenv(PassphraseEnv, "")
+	if !IsTerminal() {
+		t.Skip("/dev/null is not a character device")
+	}
+
+	SetPrompts(false)
+	_, err = Passphrase("")
+	SetPrompts(true)
+	if err == nil || !strings.Contains(err.Error(), "secret store is locked") {
+		t.Errorf("Passphrase error without prompts = %v, want locked", err)
+	}
+
+	// The prompt reads the empty stdin instead
+	if _, err := Passphrase(""); err == nil || strings.Contains(err.Error(), "secret store is locked") {
+		t.Errorf("Passphrase error with prompts = %v, want a read error", err)
+	}
+}
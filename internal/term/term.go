@@ -0,0 +1,3 @@
+// Package term sets up the terminal for the dashboard and for secret
+// prompts, on top of the termios ioctls
+package term
@@ -0,0 +1,51 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package term
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// errUnsupported is returned where raw terminal input isn't implemented
+var errUnsupported = errors.New("the dashboard is not supported on this platform")
+
+// MakeRaw fails, as raw mode isn't implemented here
+func MakeRaw(fd int) (func() error, error) {
+	return nil, errUnsupported
+}
+
+// ReadPassword reads a line from fd. Echo can't be turned off here, and a
+// read cancelled with ctx is left pending until a line is entered.
+func ReadPassword(ctx context.Context, fd int) ([]byte, error) {
+	type answer struct {
+		line string
+		err  error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		line, err := bufio.NewReader(os.NewFile(uintptr(fd), "stdin")).ReadString('\n')
+		answers <- answer{line, err}
+	}()
+
+	select {
+	case a := <-answers:
+		if a.err != nil && a.line == "" {
+			return nil, a.err
+		}
+		return []byte(strings.TrimSuffix(a.line, "\n")), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Size fails, as the terminal size can't be read here
+func Size(fd int) (width, height int, err error) {
+	return 0, 0, errUnsupported
+}
+
+// NotifyResize does nothing, as there is no resize signal here
+func NotifyResize(c chan<- os.Signal) {}
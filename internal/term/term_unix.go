@@ -0,0 +1,118 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package term
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// pollInterval is how often a pending read checks whether it was cancelled
+const pollInterval = 100 * time.Millisecond
+
+// MakeRaw puts the terminal fd in raw mode, so that keys are read as they
+// are pressed without being echoed, and returns a function restoring it
+func MakeRaw(fd int) (func() error, error) {
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *saved
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+	}, nil
+}
+
+// ReadPassword reads a line from fd without echoing it, restoring the
+// terminal before it returns. When ctx is cancelled the read stops with
+// ctx.Err(), leaving the rest of the input unread. If fd is not a terminal
+// the line is read as it is.
+func ReadPassword(ctx context.Context, fd int) ([]byte, error) {
+	if saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios); err == nil {
+		noEcho := *saved
+		noEcho.Lflag &^= unix.ECHO
+		noEcho.Lflag |= unix.ICANON | unix.ISIG
+		noEcho.Iflag |= unix.ICRNL
+		if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho); err != nil {
+			return nil, err
+		}
+		defer unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+	}
+
+	var line []byte
+	var buf [1]byte
+	for {
+		ready, err := waitReadable(ctx, fd)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			continue
+		}
+
+		// One byte at a time, so nothing after the line is consumed
+		n, err := unix.Read(fd, buf[:])
+		switch {
+		case errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN):
+			continue
+		case err != nil:
+			return nil, err
+		case n == 0:
+			if len(line) == 0 {
+				return nil, io.EOF
+			}
+			return line, nil
+		case buf[0] == '\n':
+			return line, nil
+		}
+		line = append(line, buf[0])
+	}
+}
+
+// waitReadable waits up to pollInterval for fd to have input, failing with
+// ctx.Err() once ctx is cancelled. select is used rather than poll, which
+// doesn't work on terminals on macOS.
+func waitReadable(ctx context.Context, fd int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	var fds unix.FdSet
+	fds.Set(fd)
+	timeout := unix.NsecToTimeval(pollInterval.Nanoseconds())
+	n, err := unix.Select(fd+1, &fds, nil, nil, &timeout)
+	if errors.Is(err, unix.EINTR) {
+		return false, nil
+	}
+	return n > 0, err
+}
+
+// Size returns the number of columns and rows of the terminal fd
+func Size(fd int) (width, height int, err error) {
+	size, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(size.Col), int(size.Row), nil
+}
+
+// NotifyResize sends on c when the terminal is resized
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, unix.SIGWINCH)
+}
@@ -0,0 +1,9 @@
+package term
+
+import "golang.org/x/sys/unix"
+
+// The ioctls reading and setting terminal attributes
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
@@ -0,0 +1,207 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sonarr-sabnzbd-cli/internal/config"
+)
+
+// CLIEnv runs cobra commands against fake Sonarr and Sabnzbd servers with a
+// temporary home directory and configuration file.
+//
+// The API clients are created once per process, so a package creates a
+// single environment in TestMain and calls Reset between tests.
+type CLIEnv struct {
+	Root    *cobra.Command
+	Sonarr  *FakeSonarr
+	Sabnzbd *FakeSabnzbd
+	Home    string
+}
+
+// StartCLI starts the fake servers, points HOME and the cache directory at a
+// temporary directory and writes a configuration file for the fakes
+func StartCLI(root *cobra.Command) (*CLIEnv, error) {
+	home, err := os.MkdirTemp("", "soncli-test-")
+	if err != nil {
+		return nil, err
+	}
+
+	env := &CLIEnv{
+		Root:    root,
+		Sonarr:  StartFakeSonarr(),
+		Sabnzbd: StartFakeSabnzbd(),
+		Home:    home,
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	if err := env.WriteConfig(""); err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+// ConfigPath returns the path of the configuration file
+func (e *CLIEnv) ConfigPath() string {
+	return filepath.Join(e.Home, ".config", "sonarr-sabnzbd-cli", "config.yaml")
+}
+
+// WriteConfig writes a configuration file for the fake servers. Extra YAML
+// is appended to the file.
+func (e *CLIEnv) WriteConfig(extra string) error {
+	sonarr, sabnzbd := e.Sonarr.Config(), e.Sabnzbd.Config()
+	content := fmt.Sprintf(`version: %d
+sonarr:
+  host: %s
+  port: %d
+  api_key: %s
+  timeout: 5s
+sabnzbd:
+  host: %s
+  port: %d
+  api_key: %s
+  timeout: 5s
+http:
+  retries: 0
+`, config.CurrentVersion, sonarr.Host, sonarr.Port, sonarr.APIKey, sabnzbd.Host, sabnzbd.Port, sabnzbd.APIKey)
+
+	if err := os.MkdirAll(filepath.Dir(e.ConfigPath()), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(e.ConfigPath(), []byte(content+extra), 0600)
+}
+
+// Close stops the fake servers and removes the home directory
+func (e *CLIEnv) Close() {
+	e.Sonarr.Close()
+	e.Sabnzbd.Close()
+	os.RemoveAll(e.Home)
+}
+
+// Reset restores the default state of both fake servers
+func (e *CLIEnv) Reset() {
+	e.Sonarr.Reset()
+	e.Sabnzbd.Reset()
+}
+
+// Run executes the root command with args and returns what it wrote to
+// stdout
+func (e *CLIEnv) Run(t testing.TB, args ...string) (string, error) {
+	t.Helper()
+	return e.RunWithInput(t, "", args...)
+}
+
+// RunWithInput executes the root command with input on stdin and returns
+// what it wrote to stdout
+func (e *CLIEnv) RunWithInput(t testing.TB, input string, args ...string) (string, error) {
+	t.Helper()
+	return e.run(t, context.Background(), input, args)
+}
+
+// RunContext executes the root command with ctx, which ends commands that
+// run until interrupted such as --watch, and returns what it wrote to
+// stdout
+func (e *CLIEnv) RunContext(t testing.TB, ctx context.Context, args ...string) (string, error) {
+	t.Helper()
+	return e.run(t, ctx, "", args)
+}
+
+// run executes the root command with ctx and input on stdin
+func (e *CLIEnv) run(t testing.TB, ctx context.Context, input string, args []string) (string, error) {
+	t.Helper()
+	resetFlags(e.Root)
+	resetContexts(e.Root)
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin.WriteString(input)
+	stdin.Seek(0, io.SeekStart)
+	defer stdin.Close()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, w
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		output <- buf.String()
+	}()
+
+	var stderr bytes.Buffer
+	e.Root.SetOut(w)
+	e.Root.SetErr(&stderr)
+	e.Root.SetArgs(args)
+	err = e.Root.ExecuteContext(ctx)
+
+	w.Close()
+	return <-output, err
+}
+
+// MustRun executes the root command and fails the test on error
+func (e *CLIEnv) MustRun(t testing.TB, args ...string) string {
+	t.Helper()
+	out, err := e.Run(t, args...)
+	if err != nil {
+		t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// AssertContains fails the test unless out contains every string in want
+func AssertContains(t testing.TB, out string, want ...string) {
+	t.Helper()
+	for _, s := range want {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q:\n%s", s, out)
+		}
+	}
+}
+
+// resetFlags restores every flag in the command tree to its default, since
+// cobra keeps flag values between executions
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
+
+// resetContexts clears the context of every command in the tree, since
+// cobra only passes the root's context on to commands that have none
+func resetContexts(cmd *cobra.Command) {
+	cmd.SetContext(nil)
+	for _, child := range cmd.Commands() {
+		resetContexts(child)
+	}
+}
@@ -0,0 +1,208 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Fixture is a recorded HTTP exchange stored as a golden file
+type Fixture struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// secretQueryParams are removed from recorded URLs and fixture keys
+var secretQueryParams = []string{"apikey", "ma_username", "ma_password"}
+
+// fixtureName matches characters that are not safe in fixture file names
+var fixtureName = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Recorder is an http.RoundTripper that replays responses from golden files
+// in a directory. When Next is set, requests are sent there instead and the
+// responses are written to the directory, overwriting existing fixtures.
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+
+	// Secrets are replaced with "REDACTED" in recorded bodies
+	Secrets []string
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := fixtureKey(req)
+	path := filepath.Join(r.Dir, fixtureFile(req.Method, key))
+
+	if r.Next == nil {
+		return r.replay(req, path, key)
+	}
+	return r.record(req, path, key)
+}
+
+// replay loads a response from a golden file
+func (r *Recorder) replay(req *http.Request, path, key string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s: %w", req.Method, key, err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	body := []byte(fixture.Text)
+	if len(fixture.Body) > 0 {
+		body = fixture.Body
+	}
+	header := fixture.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record forwards a request and writes the response to a golden file
+func (r *Recorder) record(req *http.Request, path, key string) (*http.Response, error) {
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	recorded := string(data)
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			recorded = strings.ReplaceAll(recorded, secret, "REDACTED")
+		}
+	}
+
+	fixture := Fixture{
+		Method: req.Method,
+		URL:    key,
+		Status: resp.StatusCode,
+		Header: http.Header{},
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		fixture.Header.Set("Content-Type", contentType)
+	}
+	if json.Valid([]byte(recorded)) {
+		var indented bytes.Buffer
+		json.Indent(&indented, []byte(recorded), "", "  ")
+		fixture.Body = indented.Bytes()
+	} else {
+		fixture.Text = recorded
+	}
+
+	out, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NewReplayServer starts a server that answers from the golden files in dir.
+// When upstream is set, requests are proxied to it and recorded instead, so
+// fixtures can be captured from a real Sonarr or Sabnzbd:
+//
+//	SONCLI_RECORD_SONARR=http://localhost:8989 SONCLI_SONARR_API_KEY=... go test ./internal/api/sonarr -run Replay
+func NewReplayServer(t testing.TB, dir, upstream string, secrets ...string) *httptest.Server {
+	t.Helper()
+
+	recorder := &Recorder{Dir: dir, Secrets: secrets}
+	var target *url.URL
+	if upstream != "" {
+		var err error
+		target, err = url.Parse(upstream)
+		if err != nil {
+			t.Fatalf("invalid upstream %q: %v", upstream, err)
+		}
+		recorder.Next = http.DefaultTransport
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := r.Clone(r.Context())
+		out.RequestURI = ""
+		out.URL.Scheme, out.URL.Host = "http", r.Host
+		if target != nil {
+			out.URL.Scheme, out.URL.Host = target.Scheme, target.Host
+			out.URL.Path = strings.TrimSuffix(target.Path, "/") + r.URL.Path
+			out.Host = target.Host
+		}
+
+		resp, err := recorder.RoundTrip(out)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fixtureKey returns the path and query of a request without credentials
+func fixtureKey(req *http.Request) string {
+	query := req.URL.Query()
+	for _, param := range secretQueryParams {
+		query.Del(param)
+	}
+	key := req.URL.Path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+// fixtureFile returns a readable, unique file name for a request
+func fixtureFile(method, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + key))
+	name := strings.Trim(fixtureName.ReplaceAllString(key, "_"), "_")
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(method), name, hex.EncodeToString(sum[:4]))
+}
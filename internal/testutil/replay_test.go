@@ -0,0 +1,72 @@
+package testutil
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	fake := NewFakeSabnzbd(t)
+	dir := t.TempDir()
+
+	recorder := NewReplayServer(t, dir, fake.URL, SabnzbdAPIKey)
+	want := get(t, recorder.URL+"/api?mode=get_config&section=misc&apikey="+SabnzbdAPIKey+"&output=json")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), SabnzbdAPIKey) {
+		t.Error("fixture contains the API key")
+	}
+
+	// Replay without the upstream server
+	fake.Close()
+	replayer := NewReplayServer(t, dir, "")
+	got := get(t, replayer.URL+"/api?mode=get_config&section=misc&apikey=other&output=json")
+
+	var wantConfig, gotConfig map[string]any
+	json.Unmarshal([]byte(want), &wantConfig)
+	json.Unmarshal([]byte(got), &gotConfig)
+	misc := gotConfig["config"].(map[string]any)["misc"].(map[string]any)
+	if misc["cache_limit"] != "1G" {
+		t.Errorf("replayed cache_limit = %v, want 1G", misc["cache_limit"])
+	}
+	if misc["api_key"] != "REDACTED" {
+		t.Errorf("replayed api_key = %v, want REDACTED", misc["api_key"])
+	}
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	replayer := NewReplayServer(t, t.TempDir(), "")
+
+	resp, err := http.Get(replayer.URL + "/api/v3/series")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+}
+
+// get returns the body of a successful GET request
+func get(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s: %s", url, resp.Status, body)
+	}
+	return string(body)
+}
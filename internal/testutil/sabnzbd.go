@@ -0,0 +1,529 @@
+package testutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// SabnzbdAPIKey is the API key accepted by the fake Sabnzbd server
+const SabnzbdAPIKey = "sabnzbd-test-key"
+
+// SabnzbdState is the scriptable state served by FakeSabnzbd
+type SabnzbdState struct {
+	Version     string
+	Queue       models.Queue
+	History     models.History
+	Categories  []string
+	Files       map[string][]models.JobFile
+	FullStatus  models.FullStatus
+	Warnings    []models.Warning
+	ServerStats models.ServerStats
+	Scripts     []string
+
+	// Config holds the configuration tree. Plain sections such as misc are
+	// maps; named sections such as servers, categories and rss are lists of
+	// maps with a "name" field.
+	Config map[string]any
+
+	// RSSRuns counts rss_now requests
+	RSSRuns int
+
+	// Failures maps an API mode to an error reported with status:false
+	Failures map[string]string
+}
+
+// FakeSabnzbd is an in-process server implementing the subset of the
+// Sabnzbd API used by the client
+type FakeSabnzbd struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	state  SabnzbdState
+	calls  []url.Values
+	nextID int
+}
+
+// NewFakeSabnzbd starts a fake Sabnzbd server seeded with
+// DefaultSabnzbdState. The server is closed when the test finishes.
+func NewFakeSabnzbd(t testing.TB) *FakeSabnzbd {
+	t.Helper()
+	f := StartFakeSabnzbd()
+	t.Cleanup(f.Close)
+	return f
+}
+
+// StartFakeSabnzbd starts a fake Sabnzbd server that the caller must close,
+// for use in TestMain
+func StartFakeSabnzbd() *FakeSabnzbd {
+	f := &FakeSabnzbd{state: DefaultSabnzbdState(), nextID: 100}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Reset restores the default state and forgets recorded requests
+func (f *FakeSabnzbd) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = DefaultSabnzbdState()
+	f.calls = nil
+	f.nextID = 100
+}
+
+// DefaultSabnzbdState returns a queue with two jobs, a short history, one
+// news server and a small configuration
+func DefaultSabnzbdState() SabnzbdState {
+	today := time.Now().Format("2006-01-02")
+	return SabnzbdState{
+		Version: "4.3.2",
+		Queue: models.Queue{
+			Version:    "4.3.2",
+			SpeedLimit: "100",
+			Speed:      "12.5 M",
+			KBPerSec:   "12800.00",
+			Size:       "5.0 GB",
+			SizeLeft:   "3.2 GB",
+			MB:         "5120.00",
+			MBLeft:     "3276.80",
+			TimeLeft:   "0:04:20",
+			Status:     "Downloading",
+			Slots: []models.QueueSlot{
+				{ID: "SABnzbd_nzo_1", Name: "Show.S01E01.1080p", Category: "tv", Size: "2.0 GB", SizeLeft: "0.2 GB", TimeLeft: "0:00:20", Status: "Downloading", Index: 0, Percentage: "90"},
+				{ID: "SABnzbd_nzo_2", Name: "Show.S01E02.1080p", Category: "tv", Size: "3.0 GB", SizeLeft: "3.0 GB", TimeLeft: "0:04:00", Status: "Queued", Index: 1, Percentage: "0"},
+			},
+		},
+		History: models.History{
+			Version: "4.3.2",
+			Slots: []models.HistorySlot{
+				{ID: "SABnzbd_nzo_3", Name: "Movie.2023.1080p", Category: "movies", Size: "8.0 GB", Status: "Completed", Bytes: 8 * 1024 * 1024 * 1024, Completed: time.Now().Add(-time.Hour).Unix()},
+				{ID: "SABnzbd_nzo_4", Name: "Broken.Release", Category: "tv", Size: "1.0 GB", Status: "Failed", FailMessage: "Repair failed, not enough repair blocks"},
+			},
+		},
+		Categories: []string{"*", "movies", "tv"},
+		Files: map[string][]models.JobFile{
+			"SABnzbd_nzo_1": {
+				{ID: "SABnzbd_nzf_1", Filename: "show.s01e01.part01.rar", Status: "finished", MB: "100.00", MBLeft: "0.00", Bytes: "104857600", Set: "show.s01e01"},
+				{ID: "SABnzbd_nzf_2", Filename: "show.s01e01.part02.rar", Status: "active", MB: "100.00", MBLeft: "50.00", Bytes: "104857600", Set: "show.s01e01"},
+				{ID: "SABnzbd_nzf_3", Filename: "show.s01e01.par2", Status: "queued", MB: "10.00", MBLeft: "10.00", Bytes: "10485760"},
+			},
+		},
+		FullStatus: models.FullStatus{
+			CPUModel:        "Test CPU",
+			Pystone:         250000,
+			LoadAvg:         "0.50 | 0.40 | 0.30",
+			Uptime:          "2d",
+			DownloadDir:     "/downloads/incomplete",
+			CompleteDir:     "/downloads/complete",
+			DiskSpace1:      "120.50",
+			DiskSpace2:      "800.00",
+			DiskSpaceTotal1: "500.00",
+			DiskSpaceTotal2: "2000.00",
+			Servers: []models.ServerStatus{
+				{Name: "news.example.com", ActiveConnections: 8, TotalConnections: 20, Active: true, Priority: 0},
+			},
+			Folders: []string{"Orphaned.Job.1", "Orphaned.Job.2"},
+			Modules: map[string]string{"sabctools": "8.2.0", "unrar": "7.00", "7zip": "23.01"},
+		},
+		Warnings: []models.Warning{},
+		ServerStats: models.ServerStats{
+			Total: 2 * 1024 * 1024 * 1024 * 1024,
+			Month: 300 * 1024 * 1024 * 1024,
+			Week:  50 * 1024 * 1024 * 1024,
+			Day:   5 * 1024 * 1024 * 1024,
+			Servers: map[string]models.ServerStatistics{
+				"news.example.com": {
+					Total:           2 * 1024 * 1024 * 1024 * 1024,
+					Month:           300 * 1024 * 1024 * 1024,
+					Week:            50 * 1024 * 1024 * 1024,
+					Day:             5 * 1024 * 1024 * 1024,
+					Daily:           map[string]int64{today: 5 * 1024 * 1024 * 1024},
+					ArticlesTried:   map[string]int64{today: 7000},
+					ArticlesSuccess: map[string]int64{today: 6958},
+				},
+			},
+		},
+		Scripts: []string{"None", "notify.py"},
+		Config: map[string]any{
+			"misc": map[string]any{
+				"cache_limit":   "1G",
+				"download_dir":  "/downloads/incomplete",
+				"complete_dir":  "/downloads/complete",
+				"api_key":       SabnzbdAPIKey,
+				"nzb_key":       "nzb-test-key",
+				"bandwidth_max": "",
+			},
+			"servers": []any{
+				map[string]any{"name": "news.example.com", "host": "news.example.com", "port": 563, "ssl": 1, "connections": 20, "username": "user", "password": "secret", "enable": 1},
+			},
+			"categories": []any{
+				map[string]any{"name": "*", "dir": "", "priority": 0},
+				map[string]any{"name": "tv", "dir": "tv", "priority": 0},
+			},
+			"rss": []any{
+				map[string]any{"name": "shows", "uri": []any{"https://indexer.example.com/rss"}, "cat": "tv", "enable": 1, "priority": -100},
+			},
+		},
+	}
+}
+
+// Config returns a configuration pointing at the fake server
+func (f *FakeSabnzbd) Config() models.SabnzbdConfig {
+	u, _ := url.Parse(f.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return models.SabnzbdConfig{
+		Host:    u.Hostname(),
+		Port:    port,
+		APIKey:  SabnzbdAPIKey,
+		Timeout: 5 * time.Second,
+	}
+}
+
+// Update changes the state of the server
+func (f *FakeSabnzbd) Update(fn func(*SabnzbdState)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(&f.state)
+}
+
+// State returns a copy of the state of the server
+func (f *FakeSabnzbd) State() SabnzbdState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Calls returns the query parameters of every API request received, without
+// the API key
+func (f *FakeSabnzbd) Calls() []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// LastCall returns the query parameters of the most recent API request
+func (f *FakeSabnzbd) LastCall() url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.calls) == 0 {
+		return nil
+	}
+	return f.calls[len(f.calls)-1]
+}
+
+// serveHTTP routes an API request by mode
+func (f *FakeSabnzbd) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/api" {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	mode := query.Get("mode")
+	apiKey := query.Get("apikey")
+	query.Del("apikey")
+	f.calls = append(f.calls, query)
+
+	// The version endpoint does not require an API key
+	if mode == "version" {
+		writeJSON(w, http.StatusOK, map[string]any{"version": f.state.Version})
+		return
+	}
+	if apiKey != SabnzbdAPIKey {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "API Key Incorrect"})
+		return
+	}
+	if message, ok := f.state.Failures[mode]; ok {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": message})
+		return
+	}
+
+	switch mode {
+	case "queue":
+		f.queue(w, query)
+	case "history":
+		writeJSON(w, http.StatusOK, map[string]any{"history": f.state.History})
+	case "addurl":
+		f.addURL(w, query)
+	case "pause":
+		f.state.Queue.Paused = true
+		f.state.Queue.Status = "Paused"
+		writeStatus(w)
+	case "resume":
+		f.state.Queue.Paused = false
+		f.state.Queue.Status = "Downloading"
+		writeStatus(w)
+	case "speedlimit":
+		f.state.Queue.SpeedLimit = query.Get("value")
+		writeStatus(w)
+	case "get_cats":
+		writeJSON(w, http.StatusOK, map[string]any{"categories": nonNil(f.state.Categories)})
+	case "get_files":
+		writeJSON(w, http.StatusOK, map[string]any{"files": nonNil(f.state.Files[query.Get("value")])})
+	case "move_nzf_bulk":
+		f.moveFiles(w, query)
+	case "fullstatus":
+		writeJSON(w, http.StatusOK, map[string]any{"status": f.state.FullStatus})
+	case "status":
+		f.orphans(w, query)
+	case "warnings":
+		if query.Get("name") == "clear" {
+			f.state.Warnings = nil
+			writeStatus(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"warnings": nonNil(f.state.Warnings)})
+	case "server_stats":
+		writeJSON(w, http.StatusOK, f.state.ServerStats)
+	case "get_scripts":
+		writeJSON(w, http.StatusOK, map[string]any{"scripts": nonNil(f.state.Scripts)})
+	case "get_config":
+		f.getConfig(w, query)
+	case "set_config":
+		f.setConfig(w, query)
+	case "del_config":
+		f.deleteConfig(w, query)
+	case "rss_now":
+		f.state.RSSRuns++
+		writeStatus(w)
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "not implemented"})
+	}
+}
+
+// queue serves the queue listing and the queue actions selected by name
+func (f *FakeSabnzbd) queue(w http.ResponseWriter, query url.Values) {
+	switch query.Get("name") {
+	case "":
+		writeJSON(w, http.StatusOK, map[string]any{"queue": f.state.Queue})
+	case "delete":
+		ids := strings.Split(query.Get("value"), ",")
+		before := len(f.state.Queue.Slots)
+		f.state.Queue.Slots = slices.DeleteFunc(f.state.Queue.Slots, func(slot models.QueueSlot) bool {
+			return slices.Contains(ids, slot.ID)
+		})
+		if len(f.state.Queue.Slots) == before {
+			writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Job not found"})
+			return
+		}
+		writeStatus(w)
+	case "pause", "resume":
+		status := "Paused"
+		if query.Get("name") == "resume" {
+			status = "Queued"
+		}
+		for i, slot := range f.state.Queue.Slots {
+			if slot.ID == query.Get("value") {
+				f.state.Queue.Slots[i].Status = status
+				writeStatus(w)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Job not found"})
+	case "delete_nzf":
+		nzoID := query.Get("value")
+		ids := strings.Split(query.Get("value2"), ",")
+		files, ok := f.state.Files[nzoID]
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Job not found"})
+			return
+		}
+		f.state.Files[nzoID] = slices.DeleteFunc(files, func(file models.JobFile) bool {
+			return slices.Contains(ids, file.ID)
+		})
+		writeStatus(w)
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "not implemented"})
+	}
+}
+
+// addURL appends a queued job for the given URL
+func (f *FakeSabnzbd) addURL(w http.ResponseWriter, query url.Values) {
+	name := query.Get("name")
+	if name == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "expects one parameter"})
+		return
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("SABnzbd_nzo_%d", f.nextID)
+	f.state.Queue.Slots = append(f.state.Queue.Slots, models.QueueSlot{
+		ID:       id,
+		Name:     name,
+		Category: query.Get("cat"),
+		Status:   "Queued",
+		Index:    len(f.state.Queue.Slots),
+	})
+	writeJSON(w, http.StatusOK, map[string]any{"status": true, "nzo_ids": []string{id}})
+}
+
+// moveFiles moves files up or down within a job
+func (f *FakeSabnzbd) moveFiles(w http.ResponseWriter, query url.Values) {
+	nzoID := query.Get("value")
+	files, ok := f.state.Files[nzoID]
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Job not found"})
+		return
+	}
+
+	size, _ := strconv.Atoi(query.Get("size"))
+	if size <= 0 {
+		size = 1
+	}
+	ids := strings.Split(query.Get("nzf_ids"), ",")
+	for _, id := range ids {
+		idx := slices.IndexFunc(files, func(file models.JobFile) bool { return file.ID == id })
+		if idx < 0 {
+			continue
+		}
+		target := idx
+		switch query.Get("name") {
+		case "up":
+			target = max(idx-size, 0)
+		case "down":
+			target = min(idx+size, len(files)-1)
+		case "top":
+			target = 0
+		case "bottom":
+			target = len(files) - 1
+		}
+		file := files[idx]
+		files = slices.Delete(files, idx, idx+1)
+		files = slices.Insert(files, target, file)
+	}
+	f.state.Files[nzoID] = files
+	writeStatus(w)
+}
+
+// orphans handles the orphan actions of the status endpoint
+func (f *FakeSabnzbd) orphans(w http.ResponseWriter, query url.Values) {
+	folder := query.Get("value")
+	switch query.Get("name") {
+	case "add_orphan", "delete_orphan":
+		idx := slices.Index(f.state.FullStatus.Folders, folder)
+		if idx < 0 {
+			writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Folder not found"})
+			return
+		}
+		f.state.FullStatus.Folders = slices.Delete(f.state.FullStatus.Folders, idx, idx+1)
+	case "add_all_orphan", "delete_all_orphan":
+		f.state.FullStatus.Folders = nil
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "not implemented"})
+		return
+	}
+	writeStatus(w)
+}
+
+// getConfig returns the whole configuration, a section or a single keyword
+func (f *FakeSabnzbd) getConfig(w http.ResponseWriter, query url.Values) {
+	section := query.Get("section")
+	if section == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"config": f.state.Config})
+		return
+	}
+
+	value, ok := f.state.Config[section]
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Unknown section"})
+		return
+	}
+	if keyword := query.Get("keyword"); keyword != "" {
+		switch v := value.(type) {
+		case map[string]any:
+			value = map[string]any{keyword: v[keyword]}
+		case []any:
+			var items []any
+			for _, item := range v {
+				if item.(map[string]any)["name"] == keyword {
+					items = append(items, item)
+				}
+			}
+			value = items
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"config": map[string]any{section: value}})
+}
+
+// setConfig changes a plain value or creates or updates a named item
+func (f *FakeSabnzbd) setConfig(w http.ResponseWriter, query url.Values) {
+	section, keyword := query.Get("section"), query.Get("keyword")
+	if section == "" || keyword == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Missing section or keyword"})
+		return
+	}
+
+	switch v := f.state.Config[section].(type) {
+	case map[string]any:
+		v[keyword] = configValue(query.Get("value"))
+		writeJSON(w, http.StatusOK, map[string]any{"config": map[string]any{section: map[string]any{keyword: v[keyword]}}})
+	case []any:
+		var item map[string]any
+		for _, existing := range v {
+			if existing.(map[string]any)["name"] == keyword {
+				item = existing.(map[string]any)
+			}
+		}
+		if item == nil {
+			item = map[string]any{"name": keyword}
+			f.state.Config[section] = append(v, item)
+		}
+		for key, values := range query {
+			switch key {
+			case "mode", "output", "section", "keyword":
+				continue
+			}
+			item[key] = configValue(values[0])
+		}
+		// Sabnzbd returns feed URIs as a list
+		if uri, ok := item["uri"].(string); ok && section == "rss" {
+			item["uri"] = []any{uri}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"config": map[string]any{section: []any{item}}})
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Unknown section"})
+	}
+}
+
+// deleteConfig removes a named item
+func (f *FakeSabnzbd) deleteConfig(w http.ResponseWriter, query url.Values) {
+	section, keyword := query.Get("section"), query.Get("keyword")
+	items, ok := f.state.Config[section].([]any)
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Unknown section"})
+		return
+	}
+	remaining := slices.DeleteFunc(slices.Clone(items), func(item any) bool {
+		return item.(map[string]any)["name"] == keyword
+	})
+	if len(remaining) == len(items) {
+		writeJSON(w, http.StatusOK, map[string]any{"status": false, "error": "Item not found"})
+		return
+	}
+	f.state.Config[section] = remaining
+	writeStatus(w)
+}
+
+// configValue converts a set_config value to the type Sabnzbd returns
+func configValue(value string) any {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return value
+}
+
+// writeStatus writes a successful status response
+func writeStatus(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": true})
+}
@@ -0,0 +1,387 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// SonarrAPIKey is the API key accepted by the fake Sonarr server
+const SonarrAPIKey = "sonarr-test-key"
+
+// SonarrState is the scriptable state served by FakeSonarr. The major
+// version in Status selects the API shape: 2 serves the unversioned API,
+// 3 requires language profiles and 4 has none.
+type SonarrState struct {
+	Status           models.SystemStatus
+	Series           []models.Series
+	Lookup           []models.Series
+	Episodes         map[int][]models.Episode
+	Profiles         []models.QualityProfile
+	LanguageProfiles []models.LanguageProfile
+	RootFolders      []models.RootFolder
+
+	// Commands records the bodies posted to /command
+	Commands []map[string]any
+
+	// AddRequests records the bodies posted to /series
+	AddRequests []map[string]any
+
+	// LegacyProfiles serves quality profiles at /profile only, as older
+	// Sonarr versions do
+	LegacyProfiles bool
+
+	// Failures maps a request path such as "/api/v3/series" to a status
+	// code returned instead of the normal response
+	Failures map[string]int
+}
+
+// FakeSonarr is an in-process server implementing the subset of the Sonarr
+// v3 API used by the client
+type FakeSonarr struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	state    SonarrState
+	requests []string
+	nextID   int
+}
+
+// NewFakeSonarr starts a fake Sonarr server seeded with DefaultSonarrState.
+// The server is closed when the test finishes.
+func NewFakeSonarr(t testing.TB) *FakeSonarr {
+	t.Helper()
+	f := StartFakeSonarr()
+	t.Cleanup(f.Close)
+	return f
+}
+
+// StartFakeSonarr starts a fake Sonarr server that the caller must close,
+// for use in TestMain
+func StartFakeSonarr() *FakeSonarr {
+	f := &FakeSonarr{state: DefaultSonarrState(), nextID: 100}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Reset restores the default state and forgets recorded requests
+func (f *FakeSonarr) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = DefaultSonarrState()
+	f.requests = nil
+	f.nextID = 100
+}
+
+// DefaultSonarrState returns a small library of series, episodes, quality
+// profiles and root folders
+func DefaultSonarrState() SonarrState {
+	return SonarrState{
+		Status: models.SystemStatus{
+			Version: "3.0.10.1567",
+			Branch:  "main",
+			OsName:  "ubuntu",
+			IsLinux: true,
+		},
+		Series: []models.Series{
+			{
+				ID:        1,
+				Title:     "Breaking Bad",
+				Year:      2008,
+				Status:    "ended",
+				Network:   "AMC",
+				TVDBID:    81189,
+				TitleSlug: "breaking-bad",
+				Path:      "/tv/Breaking Bad",
+				Monitored: true,
+				Statistics: models.SeriesStatistics{
+					SeasonCount:       5,
+					EpisodeFileCount:  62,
+					EpisodeCount:      62,
+					TotalEpisodeCount: 62,
+					SizeOnDisk:        150 * 1024 * 1024 * 1024,
+					PercentOfEpisodes: 100,
+				},
+			},
+			{
+				ID:        2,
+				Title:     "The Office (US)",
+				Year:      2005,
+				Status:    "ended",
+				Network:   "NBC",
+				TVDBID:    73244,
+				TitleSlug: "the-office-us",
+				Path:      "/tv/The Office (US)",
+				Monitored: false,
+				Statistics: models.SeriesStatistics{
+					SeasonCount:       9,
+					EpisodeFileCount:  100,
+					EpisodeCount:      201,
+					TotalEpisodeCount: 201,
+					SizeOnDisk:        80 * 1024 * 1024 * 1024,
+					PercentOfEpisodes: 49.75,
+				},
+			},
+		},
+		Lookup: []models.Series{
+			{
+				Title:     "Stranger Things",
+				Year:      2016,
+				Status:    "continuing",
+				Network:   "Netflix",
+				Overview:  "When a young boy vanishes, a small town uncovers a mystery.",
+				TVDBID:    305288,
+				TitleSlug: "stranger-things",
+			},
+			{
+				Title:     "Beyond Stranger Things",
+				Year:      2017,
+				Status:    "ended",
+				Network:   "Netflix",
+				TVDBID:    332302,
+				TitleSlug: "beyond-stranger-things",
+			},
+		},
+		Episodes: map[int][]models.Episode{
+			1: {
+				{ID: 11, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot", AirDate: "2008-01-20", HasFile: true, Monitored: true},
+				{ID: 12, SeriesID: 1, SeasonNumber: 1, EpisodeNumber: 2, Title: "Cat's in the Bag...", AirDate: "2008-01-27", HasFile: false, Monitored: true},
+			},
+		},
+		Profiles: []models.QualityProfile{
+			{ID: 1, Name: "Any", UpgradeAllowed: true, Cutoff: 1},
+			{ID: 4, Name: "HD-1080p", Cutoff: 9},
+		},
+		LanguageProfiles: []models.LanguageProfile{
+			{ID: 2, Name: "English"},
+		},
+		RootFolders: []models.RootFolder{
+			{ID: 1, Path: "/tv", FreeSpace: 500 * 1024 * 1024 * 1024},
+		},
+	}
+}
+
+// Config returns a configuration pointing at the fake server
+func (f *FakeSonarr) Config() models.SonarrConfig {
+	u, _ := url.Parse(f.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return models.SonarrConfig{
+		Host:    u.Hostname(),
+		Port:    port,
+		APIKey:  SonarrAPIKey,
+		Timeout: 5 * time.Second,
+	}
+}
+
+// Update changes the state of the server
+func (f *FakeSonarr) Update(fn func(*SonarrState)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(&f.state)
+}
+
+// State returns a copy of the state of the server
+func (f *FakeSonarr) State() SonarrState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Requests returns the method and path of every request received
+func (f *FakeSonarr) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+// serveHTTP routes a request to the matching endpoint
+func (f *FakeSonarr) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	// Sonarr answers pings without an API key
+	if r.URL.Path == "/ping" && f.major() >= 3 {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "OK"})
+		return
+	}
+	if r.Header.Get("X-Api-Key") != SonarrAPIKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Unauthorized"})
+		return
+	}
+	if status, ok := f.state.Failures[r.URL.Path]; ok {
+		writeJSON(w, status, map[string]string{"message": http.StatusText(status)})
+		return
+	}
+
+	prefix := "/api/v3"
+	if f.major() <= 2 {
+		prefix = "/api"
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, prefix)
+	if !ok || strings.HasPrefix(path, "/v3/") {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "NotFound"})
+		return
+	}
+
+	switch {
+	case path == "/system/status" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, f.state.Status)
+	case path == "/series" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, nonNil(f.state.Series))
+	case path == "/series" && r.Method == http.MethodPost:
+		f.addSeries(w, r)
+	case path == "/series/lookup" && r.Method == http.MethodGet:
+		f.lookupSeries(w, r)
+	case strings.HasPrefix(path, "/series/"):
+		f.seriesByID(w, r, strings.TrimPrefix(path, "/series/"))
+	case path == "/episode" && r.Method == http.MethodGet:
+		id, _ := strconv.Atoi(r.URL.Query().Get("seriesId"))
+		writeJSON(w, http.StatusOK, nonNil(f.state.Episodes[id]))
+	case path == "/qualityprofile" && r.Method == http.MethodGet && !f.state.LegacyProfiles && f.major() >= 3:
+		writeJSON(w, http.StatusOK, nonNil(f.state.Profiles))
+	case path == "/profile" && r.Method == http.MethodGet && (f.state.LegacyProfiles || f.major() <= 2):
+		writeJSON(w, http.StatusOK, nonNil(f.state.Profiles))
+	case path == "/languageprofile" && r.Method == http.MethodGet && f.major() == 3:
+		writeJSON(w, http.StatusOK, nonNil(f.state.LanguageProfiles))
+	case path == "/rootfolder" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, nonNil(f.state.RootFolders))
+	case path == "/command" && r.Method == http.MethodPost:
+		var command map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		f.state.Commands = append(f.state.Commands, command)
+		command["id"] = len(f.state.Commands)
+		command["status"] = "queued"
+		writeJSON(w, http.StatusCreated, command)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "NotFound"})
+	}
+}
+
+// lookupSeries returns the lookup results whose title contains the term
+func (f *FakeSonarr) lookupSeries(w http.ResponseWriter, r *http.Request) {
+	term := strings.ToLower(r.URL.Query().Get("term"))
+	results := []models.Series{}
+	for _, series := range f.state.Lookup {
+		if strings.Contains(strings.ToLower(series.Title), term) {
+			results = append(results, series)
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// major returns the major version of the fake server
+func (f *FakeSonarr) major() int {
+	major, _, _ := strings.Cut(f.state.Status.Version, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+// addSeries validates and stores a posted series
+func (f *FakeSonarr) addSeries(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	f.state.AddRequests = append(f.state.AddRequests, body)
+
+	var series models.Series
+	data, _ := json.Marshal(body)
+	json.Unmarshal(data, &series)
+
+	for _, existing := range f.state.Series {
+		if existing.TVDBID == series.TVDBID {
+			writeJSON(w, http.StatusBadRequest, []map[string]string{{
+				"propertyName": "TvdbId",
+				"errorMessage": "This series has already been added",
+				"errorCode":    "SeriesExistsValidator",
+			}})
+			return
+		}
+	}
+	required := []string{"QualityProfileId"}
+	switch f.major() {
+	case 2:
+		required = append(required, "ProfileId")
+	case 3:
+		required = append(required, "LanguageProfileId")
+	}
+	for _, property := range required {
+		field := strings.ToLower(property[:1]) + property[1:]
+		if id, _ := body[field].(float64); id <= 0 {
+			writeJSON(w, http.StatusBadRequest, []map[string]string{{
+				"propertyName": property,
+				"errorMessage": fmt.Sprintf("'%s' must be greater than '0'.", property),
+				"errorCode":    "GreaterThanValidator",
+			}})
+			return
+		}
+	}
+
+	f.nextID++
+	series.ID = f.nextID
+	f.state.Series = append(f.state.Series, series)
+	writeJSON(w, http.StatusCreated, series)
+}
+
+// seriesByID serves GET and PUT for a single series
+func (f *FakeSonarr) seriesByID(w http.ResponseWriter, r *http.Request, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "NotFound"})
+		return
+	}
+
+	for i, series := range f.state.Series {
+		if series.ID != id {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, series)
+		case http.MethodPut:
+			var updated models.Series
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+				return
+			}
+			updated.ID = id
+			f.state.Series[i] = updated
+			writeJSON(w, http.StatusAccepted, updated)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Method Not Allowed"})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("Series with ID %d does not exist", id)})
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// nonNil returns an empty slice instead of nil so lists encode as []
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
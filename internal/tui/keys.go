@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// KeyCode is a key without a character of its own
+type KeyCode int
+
+// Keys read from the terminal
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBackTab
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyCtrlC
+	KeyUnknown
+)
+
+// Key is a key press: a character or a special key
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// escapeKeys are the sequences terminals send for special keys
+var escapeKeys = map[string]KeyCode{
+	"\x1b[A": KeyUp, "\x1bOA": KeyUp,
+	"\x1b[B": KeyDown, "\x1bOB": KeyDown,
+	"\x1b[C": KeyRight, "\x1bOC": KeyRight,
+	"\x1b[D": KeyLeft, "\x1bOD": KeyLeft,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome, "\x1bOH": KeyHome, "\x1b[1~": KeyHome,
+	"\x1b[F": KeyEnd, "\x1bOF": KeyEnd, "\x1b[4~": KeyEnd,
+	"\x1b[Z": KeyBackTab,
+}
+
+// ParseKeys splits what the terminal sent in one read into key presses
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		key, n := parseKey(b)
+		keys = append(keys, key)
+		b = b[n:]
+	}
+	return keys
+}
+
+// parseKey returns the first key in b and its length
+func parseKey(b []byte) (Key, int) {
+	switch b[0] {
+	case '\r', '\n':
+		return Key{Code: KeyEnter}, 1
+	case '\t':
+		return Key{Code: KeyTab}, 1
+	case 3:
+		return Key{Code: KeyCtrlC}, 1
+	case 0x1b:
+		if len(b) == 1 {
+			return Key{Code: KeyEscape}, 1
+		}
+		// A sequence ends with a letter or ~ after ESC [ or ESC O
+		for end := 2; end < len(b) && end < 8; end++ {
+			c := b[end]
+			if c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+				if code, ok := escapeKeys[string(b[:end+1])]; ok {
+					return Key{Code: code}, end + 1
+				}
+				return Key{Code: KeyUnknown}, end + 1
+			}
+		}
+		return Key{Code: KeyEscape}, 1
+	}
+	r, n := utf8.DecodeRune(b)
+	return Key{Code: KeyRune, Rune: r}, n
+}
+
+// readKeys sends the keys read from r until it fails
+func readKeys(r io.Reader, keys chan<- Key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// Picker is a full-screen list to choose an item from, with details of the
+// item under the cursor beside it
+type Picker struct {
+	Title string
+	Items []string
+
+	// Preview describes an item in lines, which are wrapped to fit; without
+	// it the list takes the whole screen
+	Preview func(i int) []string
+	// Poster draws the poster of an item under its preview. It is slow, so
+	// it runs in the background.
+	Poster func(i, width, height int) (string, error)
+
+	cursor        int
+	chosen        int
+	width, height int
+
+	// posters holds the posters drawn or being drawn, by item
+	posters map[int]*string
+	results chan func(*Picker)
+	pending int
+}
+
+// Pick shows p until an item is chosen with enter, returning its index, or
+// the list is left with esc or q, returning -1
+func (s *Screen) Pick(ctx context.Context, p *Picker) (int, error) {
+	p.init()
+	p.width, p.height = s.Size()
+	p.loadPoster()
+
+	for {
+		if err := s.Draw(p.View()); err != nil {
+			return -1, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case key, ok := <-s.keys:
+			if !ok {
+				return -1, nil
+			}
+			if p.Key(key) {
+				return p.chosen, nil
+			}
+		case result := <-p.results:
+			p.pending--
+			result(p)
+		case <-s.resize:
+			p.width, p.height = s.Size()
+		}
+	}
+}
+
+// init readies a picker for showing
+func (p *Picker) init() {
+	p.chosen = -1
+	p.posters = map[int]*string{}
+	p.results = make(chan func(*Picker), 16)
+	p.width, p.height = 80, 24
+}
+
+// Key handles a key press and reports whether picking is over
+func (p *Picker) Key(key Key) bool {
+	switch {
+	case key.Code == KeyEnter:
+		if len(p.Items) > 0 {
+			p.chosen = p.cursor
+		}
+		return true
+	case key.Code == KeyEscape || key.Code == KeyCtrlC || key.Rune == 'q':
+		p.chosen = -1
+		return true
+	case key.Code == KeyUp || key.Rune == 'k':
+		p.move(-1)
+	case key.Code == KeyDown || key.Rune == 'j':
+		p.move(1)
+	case key.Code == KeyPageUp:
+		p.move(-p.visibleRows())
+	case key.Code == KeyPageDown:
+		p.move(p.visibleRows())
+	case key.Code == KeyHome || key.Rune == 'g':
+		p.move(-len(p.Items))
+	case key.Code == KeyEnd || key.Rune == 'G':
+		p.move(len(p.Items))
+	}
+	return false
+}
+
+// move moves the cursor by n items and starts drawing the poster of the
+// item it lands on
+func (p *Picker) move(n int) {
+	p.cursor = max(0, min(p.cursor+n, len(p.Items)-1))
+	p.loadPoster()
+}
+
+// loadPoster draws the poster of the item under the cursor in the
+// background
+func (p *Picker) loadPoster() {
+	if p.Poster == nil || len(p.Items) == 0 || p.posters[p.cursor] != nil {
+		return
+	}
+	i := p.cursor
+	width, height := p.previewWidth(), p.visibleRows()-len(p.preview(i))-1
+	if height < 4 {
+		return
+	}
+	p.posters[i] = new(string)
+	p.pending++
+	go func() {
+		poster, err := p.Poster(i, width, height)
+		p.results <- func(p *Picker) {
+			if err != nil {
+				poster = output.Faint("No poster: " + err.Error())
+			}
+			p.posters[i] = &poster
+		}
+	}()
+}
+
+// visibleRows returns how many rows of the list fit on screen
+func (p *Picker) visibleRows() int {
+	return max(1, p.height-3)
+}
+
+// listWidth returns the width of the list, which leaves most of the
+// screen to the preview
+func (p *Picker) listWidth() int {
+	if p.Preview == nil {
+		return p.width
+	}
+	return min(40, p.width/2)
+}
+
+// previewWidth returns the width of the preview right of the list and its
+// separator
+func (p *Picker) previewWidth() int {
+	return max(0, p.width-p.listWidth()-3)
+}
+
+// preview returns the details of item i wrapped to the preview, if there
+// are any
+func (p *Picker) preview(i int) []string {
+	if p.Preview == nil || len(p.Items) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, line := range p.Preview(i) {
+		lines = append(lines, wrap(line, p.previewWidth(), plain)...)
+	}
+	return lines
+}
+
+// View returns the screen as lines as wide as the screen: the title, the
+// list beside the preview and help
+func (p *Picker) View() []string {
+	lines := []string{fit(output.Bold(" "+p.Title), p.width), fit("", p.width)}
+
+	rows := p.visibleRows()
+	listWidth := p.listWidth()
+	details := p.preview(p.cursor)
+	if poster := p.posters[p.cursor]; poster != nil {
+		details = append(details, "")
+		if *poster == "" {
+			details = append(details, output.Faint("Loading poster..."))
+		} else {
+			details = append(details, strings.Split(*poster, "\n")...)
+		}
+	}
+
+	offset := max(0, p.cursor-rows+1)
+	for row := range rows {
+		line := ""
+		if i := offset + row; i < len(p.Items) {
+			line = fit("  "+p.Items[i], listWidth)
+			if i == p.cursor {
+				line = reverse + fit("> "+p.Items[i], listWidth) + reset
+			}
+		}
+		line = fit(line, listWidth)
+		if p.Preview != nil {
+			detail := ""
+			if row < len(details) {
+				detail = details[row]
+			}
+			line += " " + output.Faint("│") + " " + fit(detail, p.previewWidth())
+		}
+		lines = append(lines, line)
+	}
+
+	help := "↑↓ move  enter choose  esc cancel"
+	return append(lines, fit(" "+output.Faint(help), p.width))
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newPicker returns a picker of three items sized 80x16
+func newPicker() *Picker {
+	p := &Picker{
+		Title: "Add a series",
+		Items: []string{"Stranger Things (2016)", "Beyond Stranger Things (2017)", "Stranger (2020)"},
+		Preview: func(i int) []string {
+			return []string{fmt.Sprintf("Item %d", i), "", strings.Repeat("word ", 20)}
+		},
+		Poster: func(i, width, height int) (string, error) {
+			if i == 2 {
+				return "", errors.New("no suitable image")
+			}
+			return fmt.Sprintf("poster %d %dx%d", i, width, height), nil
+		},
+	}
+	p.init()
+	p.width, p.height = 80, 16
+	p.loadPoster()
+	return p
+}
+
+// settlePicker applies results until no poster is being drawn
+func settlePicker(p *Picker) {
+	for p.pending > 0 {
+		p.pending--
+		(<-p.results)(p)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	p := newPicker()
+	settlePicker(p)
+
+	lines := p.View()
+	if len(lines) != 16 {
+		t.Fatalf("got %d lines, want 16", len(lines))
+	}
+	for i, line := range lines {
+		if width := textWidth(line); width != 80 {
+			t.Errorf("line %d is %d wide, want 80: %q", i, width, line)
+		}
+	}
+
+	out := ansiPattern.Replace(strings.Join(lines, "\n"))
+	for _, want := range []string{
+		"> Stranger Things (2016)", "  Beyond Stranger Things (2017)",
+		"│ Item 0",
+		// The preview is wrapped beside the list
+		"│ word word word word word word word",
+		"│ poster 0 37x7",
+		"enter choose  esc cancel",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing %q:\n%s", want, out)
+		}
+	}
+
+	for _, key := range ParseKeys([]byte("jjj")) {
+		p.Key(key)
+	}
+	settlePicker(p)
+	out = ansiPattern.Replace(strings.Join(p.View(), "\n"))
+	if !strings.Contains(out, "> Stranger (2020)") || !strings.Contains(out, "No poster: no suitable image") {
+		t.Errorf("j should move to the last item and show its poster error:\n%s", out)
+	}
+
+	if !p.Key(Key{Code: KeyEnter}) || p.chosen != 2 {
+		t.Errorf("enter chose %d, want 2", p.chosen)
+	}
+}
+
+func TestPickerCancel(t *testing.T) {
+	p := newPicker()
+	p.Key(Key{Code: KeyDown})
+	if !p.Key(Key{Code: KeyEscape}) || p.chosen != -1 {
+		t.Errorf("esc chose %d, want -1", p.chosen)
+	}
+}
+
+func TestPickerWithoutPreview(t *testing.T) {
+	p := &Picker{Title: "Episodes to monitor", Items: []string{"All episodes", "No episodes"}}
+	p.init()
+
+	out := ansiPattern.Replace(strings.Join(p.View(), "\n"))
+	if strings.Contains(out, "│") {
+		t.Errorf("a picker without preview should use the whole screen:\n%s", out)
+	}
+	p.Key(Key{Code: KeyEnd})
+	if !p.Key(Key{Code: KeyEnter}) || p.chosen != 1 {
+		t.Errorf("end and enter chose %d, want 1", p.chosen)
+	}
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/term"
+)
+
+// ANSI escape sequences for taking over the screen
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	cursorHome   = "\x1b[H"
+)
+
+// Screen is the terminal taken over by full-screen views. Keys are read
+// by one reader for the life of the screen, so that views shown one after
+// another don't lose keys to each other.
+type Screen struct {
+	in, out *os.File
+	restore func() error
+	keys    chan Key
+	resize  chan os.Signal
+}
+
+// OpenScreen puts the terminal in raw mode on the alternate screen
+func OpenScreen(in, out *os.File) (*Screen, error) {
+	restore, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	s := &Screen{
+		in:      in,
+		out:     out,
+		restore: restore,
+		keys:    make(chan Key, 16),
+		resize:  make(chan os.Signal, 1),
+	}
+	term.NotifyResize(s.resize)
+	go readKeys(in, s.keys)
+	io.WriteString(out, altScreenOn+hideCursor)
+	return s, nil
+}
+
+// Close gives the terminal back as it was
+func (s *Screen) Close() error {
+	io.WriteString(s.out, showCursor+altScreenOff)
+	return s.restore()
+}
+
+// Size returns the number of columns and rows of the screen, or 80x24 if
+// it can't be read
+func (s *Screen) Size() (width, height int) {
+	width, height, err := term.Size(int(s.out.Fd()))
+	if err != nil || width == 0 || height == 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Draw writes the lines of a view over the previous one
+func (s *Screen) Draw(lines []string) error {
+	_, err := io.WriteString(s.out, cursorHome+strings.Join(lines, "\r\n"))
+	return err
+}
+
+// Run shows the dashboard on the terminal until q is pressed or ctx is
+// cancelled, restoring the terminal afterwards
+func Run(ctx context.Context, app *App, in, out *os.File) error {
+	screen, err := OpenScreen(in, out)
+	if err != nil {
+		return err
+	}
+	defer screen.Close()
+	app.Resize(screen.Size())
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	app.Refresh(true)
+	for {
+		if err := screen.Draw(app.View()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-screen.keys:
+			if !ok || app.Key(key) {
+				return nil
+			}
+		case result := <-app.Results():
+			app.Apply(result)
+		case <-screen.resize:
+			app.Resize(screen.Size())
+		case <-ticker.C:
+			// Slow services shouldn't pile up requests
+			if app.pending == 0 {
+				app.Refresh(false)
+			}
+		}
+	}
+}
@@ -0,0 +1,447 @@
+// Package tui is the full-screen dashboard of the tui command: the Sabnzbd
+// queue and history next to the Sonarr library and episodes, with actions
+// on the selected row
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// Sonarr is the part of the Sonarr client the dashboard uses
+type Sonarr interface {
+	GetSeries(ctx context.Context) ([]models.Series, error)
+	GetEpisodes(ctx context.Context, seriesID int) ([]models.Episode, error)
+	UpdateSeries(ctx context.Context, series models.Series) (*models.Series, error)
+	SearchSeries(ctx context.Context, seriesID int) error
+	SearchEpisodes(ctx context.Context, episodeIDs []int) error
+}
+
+// Sabnzbd is the part of the Sabnzbd client the dashboard uses
+type Sabnzbd interface {
+	GetQueue(ctx context.Context) (*models.Queue, error)
+	GetHistory(ctx context.Context) (*models.History, error)
+	PauseQueue(ctx context.Context) error
+	ResumeQueue(ctx context.Context) error
+	PauseJob(ctx context.Context, nzoID string) error
+	ResumeJob(ctx context.Context, nzoID string) error
+	DeleteFromQueue(ctx context.Context, nzoID string) error
+}
+
+// Poster returns a series poster as ASCII art fitting width by height
+type Poster func(series models.Series, width, height int) (string, error)
+
+// pane identifies one of the four panes
+type pane int
+
+const (
+	queuePane pane = iota
+	historyPane
+	seriesPane
+	episodesPane
+	paneCount
+)
+
+// paneTitles are the titles of the panes, in order
+var paneTitles = [paneCount]string{"Queue", "History", "Series", "Episodes"}
+
+// App is the state of the dashboard. It is only changed on the event
+// loop: requests run in the background and hand back a function that
+// applies their result.
+type App struct {
+	ctx     context.Context
+	sonarr  Sonarr
+	sabnzbd Sabnzbd
+	poster  Poster
+
+	// Errors explaining a missing client, shown in its panes
+	sonarrErr, sabnzbdErr error
+
+	queue    *models.Queue
+	history  *models.History
+	series   []models.Series
+	episodes []models.Episode
+
+	// Errors of the last load of each pane
+	errs [paneCount]error
+
+	focus  pane
+	cursor [paneCount]int
+
+	// episodesOf is the series the episodes pane shows
+	episodesOf *models.Series
+	// posterText replaces the episodes with the poster of episodesOf
+	posterText string
+	showPoster bool
+
+	// confirm is a yes/no question, such as whether to delete a job,
+	// and what to do on yes
+	confirm   string
+	onConfirm func()
+
+	message   string
+	messageOK bool
+
+	width, height int
+
+	results chan func(*App)
+	pending int
+}
+
+// New returns a dashboard using the given clients. A nil client leaves its
+// panes showing err instead.
+func New(ctx context.Context, sonarr Sonarr, sonarrErr error, sabnzbd Sabnzbd, sabnzbdErr error, poster Poster) *App {
+	return &App{
+		ctx:        ctx,
+		sonarr:     sonarr,
+		sonarrErr:  sonarrErr,
+		sabnzbd:    sabnzbd,
+		sabnzbdErr: sabnzbdErr,
+		poster:     poster,
+		width:      80,
+		height:     24,
+		results:    make(chan func(*App), 16),
+	}
+}
+
+// Resize sets the size of the screen
+func (a *App) Resize(width, height int) {
+	a.width, a.height = max(width, 20), max(height, 8)
+}
+
+// task runs fn in the background; what it returns is applied on the event
+// loop by Results
+func (a *App) task(fn func(ctx context.Context) func(*App)) {
+	a.pending++
+	go func() { a.results <- fn(a.ctx) }()
+}
+
+// Results delivers the results of background requests to the event loop
+func (a *App) Results() <-chan func(*App) {
+	return a.results
+}
+
+// Apply applies the result of a background request
+func (a *App) Apply(result func(*App)) {
+	a.pending--
+	result(a)
+}
+
+// Refresh reloads the queue and history, and the library on full
+func (a *App) Refresh(full bool) {
+	if a.sabnzbd != nil {
+		a.task(func(ctx context.Context) func(*App) {
+			queue, err := a.sabnzbd.GetQueue(ctx)
+			return func(a *App) {
+				a.errs[queuePane] = err
+				if err == nil {
+					a.queue = queue
+					a.clamp(queuePane)
+				}
+			}
+		})
+		a.task(func(ctx context.Context) func(*App) {
+			history, err := a.sabnzbd.GetHistory(ctx)
+			return func(a *App) {
+				a.errs[historyPane] = err
+				if err == nil {
+					a.history = history
+					a.clamp(historyPane)
+				}
+			}
+		})
+	}
+	if full && a.sonarr != nil {
+		a.task(func(ctx context.Context) func(*App) {
+			series, err := a.sonarr.GetSeries(ctx)
+			return func(a *App) {
+				a.errs[seriesPane] = err
+				if err != nil {
+					return
+				}
+				a.series = series
+				a.clamp(seriesPane)
+				// Start with the episodes of the first series
+				if a.episodesOf == nil && len(series) > 0 {
+					a.loadEpisodes(series[0])
+				}
+			}
+		})
+	}
+}
+
+// loadEpisodes shows the episodes of series in the episodes pane
+func (a *App) loadEpisodes(series models.Series) {
+	a.episodesOf = &series
+	a.episodes, a.posterText, a.showPoster = nil, "", false
+	a.cursor[episodesPane] = 0
+	a.task(func(ctx context.Context) func(*App) {
+		episodes, err := a.sonarr.GetEpisodes(ctx, series.ID)
+		return func(a *App) {
+			// The selection may have moved on while loading
+			if a.episodesOf == nil || a.episodesOf.ID != series.ID {
+				return
+			}
+			a.errs[episodesPane] = err
+			a.episodes = episodes
+		}
+	})
+}
+
+// loadPoster shows the poster of the series in the episodes pane
+func (a *App) loadPoster() {
+	if a.episodesOf == nil || a.poster == nil {
+		return
+	}
+	series := *a.episodesOf
+	a.showPoster = true
+	if a.posterText != "" {
+		return
+	}
+	// The poster fills the pane inside its box
+	width, height := a.paneSize(episodesPane)
+	a.task(func(ctx context.Context) func(*App) {
+		poster, err := a.poster(series, width-2, height-2)
+		return func(a *App) {
+			if a.episodesOf == nil || a.episodesOf.ID != series.ID {
+				return
+			}
+			if err != nil {
+				a.showPoster = false
+				a.fail(fmt.Errorf("could not load poster: %w", err))
+				return
+			}
+			a.posterText = poster
+		}
+	})
+}
+
+// action runs a change in the background, reporting done or the error and
+// reloading afterwards
+func (a *App) action(done string, fn func(ctx context.Context) error, full bool) {
+	a.message, a.messageOK = "Working...", true
+	a.task(func(ctx context.Context) func(*App) {
+		err := fn(ctx)
+		return func(a *App) {
+			if err != nil {
+				a.fail(err)
+				return
+			}
+			a.message, a.messageOK = done, true
+			a.Refresh(full)
+		}
+	})
+}
+
+// fail shows err in the status bar
+func (a *App) fail(err error) {
+	a.message, a.messageOK = err.Error(), false
+}
+
+// rows returns the number of rows of a pane
+func (a *App) rows(p pane) int {
+	switch p {
+	case queuePane:
+		if a.queue != nil {
+			return len(a.queue.Slots)
+		}
+	case historyPane:
+		if a.history != nil {
+			return len(a.history.Slots)
+		}
+	case seriesPane:
+		return len(a.series)
+	case episodesPane:
+		return len(a.episodes)
+	}
+	return 0
+}
+
+// clamp keeps the cursor of a pane on one of its rows
+func (a *App) clamp(p pane) {
+	a.cursor[p] = max(0, min(a.cursor[p], a.rows(p)-1))
+}
+
+// selectedJob returns the queue slot under the cursor
+func (a *App) selectedJob() (models.QueueSlot, bool) {
+	if a.rows(queuePane) == 0 {
+		return models.QueueSlot{}, false
+	}
+	return a.queue.Slots[a.cursor[queuePane]], true
+}
+
+// selectedSeries returns the series under the cursor
+func (a *App) selectedSeries() (models.Series, bool) {
+	if len(a.series) == 0 {
+		return models.Series{}, false
+	}
+	return a.series[a.cursor[seriesPane]], true
+}
+
+// Key handles a key press and reports whether to quit
+func (a *App) Key(key Key) bool {
+	// A pending question takes the next key
+	if a.confirm != "" {
+		onConfirm := a.onConfirm
+		a.confirm, a.onConfirm = "", nil
+		if key.Rune == 'y' || key.Rune == 'Y' {
+			onConfirm()
+		} else {
+			a.message, a.messageOK = "Cancelled", true
+		}
+		return false
+	}
+
+	switch {
+	case key.Rune == 'q' || key.Code == KeyCtrlC:
+		return true
+	case key.Code == KeyTab:
+		a.focus = (a.focus + 1) % paneCount
+	case key.Code == KeyBackTab:
+		a.focus = (a.focus + paneCount - 1) % paneCount
+	case key.Rune >= '1' && key.Rune <= '4':
+		a.focus = pane(key.Rune - '1')
+	case key.Code == KeyLeft:
+		a.focus = a.focus % 2
+	case key.Code == KeyRight:
+		a.focus = a.focus%2 + 2
+	case key.Code == KeyUp || key.Rune == 'k':
+		a.move(-1)
+	case key.Code == KeyDown || key.Rune == 'j':
+		a.move(1)
+	case key.Code == KeyPageUp:
+		a.move(-a.visibleRows())
+	case key.Code == KeyPageDown:
+		a.move(a.visibleRows())
+	case key.Code == KeyHome || key.Rune == 'g':
+		a.cursor[a.focus] = 0
+	case key.Code == KeyEnd || key.Rune == 'G':
+		a.cursor[a.focus] = max(0, a.rows(a.focus)-1)
+	case key.Code == KeyEscape:
+		a.showPoster, a.message = false, ""
+	case key.Rune == 'u':
+		a.Refresh(true)
+	default:
+		a.paneKey(key)
+	}
+	return false
+}
+
+// move moves the cursor of the focused pane by n rows
+func (a *App) move(n int) {
+	a.cursor[a.focus] += n
+	a.clamp(a.focus)
+}
+
+// paneKey handles the actions of the focused pane
+func (a *App) paneKey(key Key) {
+	switch a.focus {
+	case queuePane:
+		a.queueKey(key)
+	case seriesPane:
+		a.seriesKey(key)
+	case episodesPane:
+		a.episodesKey(key)
+	}
+}
+
+// queueKey pauses, resumes and deletes jobs
+func (a *App) queueKey(key Key) {
+	if a.sabnzbd == nil {
+		return
+	}
+	switch key.Rune {
+	case 'P':
+		a.action("Paused all downloads", a.sabnzbd.PauseQueue, false)
+		return
+	case 'R':
+		a.action("Resumed all downloads", a.sabnzbd.ResumeQueue, false)
+		return
+	}
+
+	job, ok := a.selectedJob()
+	if !ok {
+		return
+	}
+	switch key.Rune {
+	case 'p':
+		a.action("Paused "+job.Name, func(ctx context.Context) error {
+			return a.sabnzbd.PauseJob(ctx, job.ID)
+		}, false)
+	case 'r':
+		a.action("Resumed "+job.Name, func(ctx context.Context) error {
+			return a.sabnzbd.ResumeJob(ctx, job.ID)
+		}, false)
+	case 'd':
+		a.confirm = fmt.Sprintf("Delete %s? (y/n)", job.Name)
+		a.onConfirm = func() {
+			a.action("Deleted "+job.Name, func(ctx context.Context) error {
+				return a.sabnzbd.DeleteFromQueue(ctx, job.ID)
+			}, false)
+		}
+	}
+}
+
+// seriesKey opens the episodes or poster of a series, toggles monitoring
+// and starts searches
+func (a *App) seriesKey(key Key) {
+	series, ok := a.selectedSeries()
+	if !ok || a.sonarr == nil {
+		return
+	}
+	switch {
+	case key.Code == KeyEnter:
+		a.loadEpisodes(series)
+		a.focus = episodesPane
+	case key.Rune == 'v':
+		if a.episodesOf == nil || a.episodesOf.ID != series.ID {
+			a.loadEpisodes(series)
+		}
+		a.loadPoster()
+	case key.Rune == 'm':
+		series.Monitored = !series.Monitored
+		done := "Stopped monitoring " + series.Title
+		if series.Monitored {
+			done = "Monitoring " + series.Title
+		}
+		a.action(done, func(ctx context.Context) error {
+			_, err := a.sonarr.UpdateSeries(ctx, series)
+			return err
+		}, true)
+	case key.Rune == 's':
+		a.action("Searching for "+series.Title, func(ctx context.Context) error {
+			return a.sonarr.SearchSeries(ctx, series.ID)
+		}, false)
+	}
+}
+
+// episodesKey searches for the selected episode and shows the poster
+func (a *App) episodesKey(key Key) {
+	if a.sonarr == nil || a.episodesOf == nil {
+		return
+	}
+	switch key.Rune {
+	case 'v':
+		if a.showPoster {
+			a.showPoster = false
+		} else {
+			a.loadPoster()
+		}
+	case 's':
+		if len(a.episodes) == 0 {
+			return
+		}
+		episode := a.episodes[a.cursor[episodesPane]]
+		label := a.episodesOf.Title + " " + output.SxE(episode.SeasonNumber, episode.EpisodeNumber)
+		a.action("Searching for "+label, func(ctx context.Context) error {
+			return a.sonarr.SearchEpisodes(ctx, []int{episode.ID})
+		}, false)
+	}
+}
+
+// refreshInterval is how often the queue and history are reloaded
+const refreshInterval = 2 * time.Second
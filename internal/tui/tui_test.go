@@ -0,0 +1,326 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sonarr-sabnzbd-cli/internal/models"
+)
+
+// fakeSonarr serves two series and records the changes asked of it
+type fakeSonarr struct {
+	series   []models.Series
+	calls    []string
+	failWith error
+}
+
+func (f *fakeSonarr) GetSeries(ctx context.Context) ([]models.Series, error) {
+	return append([]models.Series(nil), f.series...), nil
+}
+
+func (f *fakeSonarr) GetEpisodes(ctx context.Context, seriesID int) ([]models.Episode, error) {
+	return []models.Episode{
+		{ID: seriesID*100 + 1, SeriesID: seriesID, SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot", AirDate: "2008-01-20", HasFile: true},
+		{ID: seriesID*100 + 2, SeriesID: seriesID, SeasonNumber: 1, EpisodeNumber: 2, Title: "Cat's in the Bag", Monitored: true},
+	}, nil
+}
+
+func (f *fakeSonarr) UpdateSeries(ctx context.Context, series models.Series) (*models.Series, error) {
+	f.calls = append(f.calls, fmt.Sprintf("update %d monitored=%t", series.ID, series.Monitored))
+	for i := range f.series {
+		if f.series[i].ID == series.ID {
+			f.series[i] = series
+		}
+	}
+	return &series, nil
+}
+
+func (f *fakeSonarr) SearchSeries(ctx context.Context, seriesID int) error {
+	f.calls = append(f.calls, fmt.Sprintf("search series %d", seriesID))
+	return f.failWith
+}
+
+func (f *fakeSonarr) SearchEpisodes(ctx context.Context, episodeIDs []int) error {
+	f.calls = append(f.calls, fmt.Sprintf("search episodes %v", episodeIDs))
+	return f.failWith
+}
+
+// fakeSabnzbd serves a queue of two jobs and records the changes asked of it
+type fakeSabnzbd struct {
+	calls []string
+}
+
+func (f *fakeSabnzbd) GetQueue(ctx context.Context) (*models.Queue, error) {
+	return &models.Queue{Status: "Downloading", Speed: "12.5 M", SizeLeft: "3.2 GB", Slots: []models.QueueSlot{
+		{ID: "SABnzbd_nzo_1", Name: "Show.S01E01", Status: "Downloading", Percentage: "40", TimeLeft: "0:04:22"},
+		{ID: "SABnzbd_nzo_2", Name: "Show.S01E02", Status: "Queued", Percentage: "0", TimeLeft: "0:10:00"},
+	}}, nil
+}
+
+func (f *fakeSabnzbd) GetHistory(ctx context.Context) (*models.History, error) {
+	return &models.History{Slots: []models.HistorySlot{
+		{ID: "SABnzbd_nzo_0", Name: "Show.S00E01", Status: "Completed", Bytes: 1 << 30},
+	}}, nil
+}
+
+func (f *fakeSabnzbd) PauseQueue(ctx context.Context) error {
+	f.calls = append(f.calls, "pause all")
+	return nil
+}
+
+func (f *fakeSabnzbd) ResumeQueue(ctx context.Context) error {
+	f.calls = append(f.calls, "resume all")
+	return nil
+}
+
+func (f *fakeSabnzbd) PauseJob(ctx context.Context, nzoID string) error {
+	f.calls = append(f.calls, "pause "+nzoID)
+	return nil
+}
+
+func (f *fakeSabnzbd) ResumeJob(ctx context.Context, nzoID string) error {
+	f.calls = append(f.calls, "resume "+nzoID)
+	return nil
+}
+
+func (f *fakeSabnzbd) DeleteFromQueue(ctx context.Context, nzoID string) error {
+	f.calls = append(f.calls, "delete "+nzoID)
+	return nil
+}
+
+// newApp returns a loaded dashboard on fake clients
+func newApp(t *testing.T) (*App, *fakeSonarr, *fakeSabnzbd) {
+	t.Helper()
+	sonarr := &fakeSonarr{series: []models.Series{
+		{ID: 1, Title: "Breaking Bad", Year: 2008, Monitored: true},
+		{ID: 2, Title: "Better Call Saul", Year: 2015},
+	}}
+	sabnzbd := &fakeSabnzbd{}
+	poster := func(series models.Series, width, height int) (string, error) {
+		return fmt.Sprintf("poster of %s %dx%d", series.Title, width, height), nil
+	}
+	app := New(context.Background(), sonarr, nil, sabnzbd, nil, poster)
+	app.Resize(120, 30)
+	app.Refresh(true)
+	settle(app)
+	return app, sonarr, sabnzbd
+}
+
+// settle applies results until no request is outstanding
+func settle(app *App) {
+	for app.pending > 0 {
+		app.Apply(<-app.Results())
+	}
+}
+
+// press handles the keys typed as text and settles
+func press(app *App, text string) bool {
+	quit := false
+	for _, key := range ParseKeys([]byte(text)) {
+		quit = app.Key(key)
+		settle(app)
+	}
+	return quit
+}
+
+// screen returns the view without escape sequences
+func screen(app *App) string {
+	return ansiPattern.Replace(strings.Join(app.View(), "\n"))
+}
+
+var ansiPattern = strings.NewReplacer(reverse, "", reset, "")
+
+func TestView(t *testing.T) {
+	app, _, _ := newApp(t)
+
+	lines := app.View()
+	if len(lines) != 30 {
+		t.Fatalf("got %d lines, want 30", len(lines))
+	}
+	for i, line := range lines {
+		if width := textWidth(line); width != 120 {
+			t.Errorf("line %d is %d wide, want 120: %q", i, width, line)
+		}
+	}
+
+	out := screen(app)
+	for _, want := range []string{
+		"Downloading  12.5 M  3.2 GB left",
+		"Show.S01E01", "████░░░░░░  40%", "0:04:22",
+		"Show.S00E01", "1.0 GB",
+		"Breaking Bad", "2008", "●",
+		"Episodes: Breaking Bad", "S01E01", "Pilot", "Downloaded", "Missing",
+		"p pause  r resume  d delete",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("view missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestViewMissingClient(t *testing.T) {
+	app := New(context.Background(), nil, errors.New("no API key"), &fakeSabnzbd{}, nil, nil)
+	app.Refresh(true)
+	settle(app)
+
+	out := screen(app)
+	if !strings.Contains(out, "Sonarr unavailable: no API key") {
+		t.Errorf("view should explain the missing client:\n%s", out)
+	}
+	if !strings.Contains(out, "Show.S01E01") {
+		t.Errorf("queue should still load:\n%s", out)
+	}
+	// Sonarr keys do nothing without a client
+	press(app, "3sm\r")
+}
+
+func TestNavigation(t *testing.T) {
+	app, _, _ := newApp(t)
+
+	press(app, "j")
+	if app.cursor[queuePane] != 1 {
+		t.Errorf("cursor = %d after j, want 1", app.cursor[queuePane])
+	}
+	press(app, "jjj")
+	if app.cursor[queuePane] != 1 {
+		t.Errorf("cursor = %d past the end, want 1", app.cursor[queuePane])
+	}
+	press(app, "\x1b[A")
+	if app.cursor[queuePane] != 0 {
+		t.Errorf("cursor = %d after up, want 0", app.cursor[queuePane])
+	}
+
+	press(app, "\t")
+	if app.focus != historyPane {
+		t.Errorf("focus = %d after tab, want history", app.focus)
+	}
+	press(app, "\x1b[Z\x1b[Z")
+	if app.focus != episodesPane {
+		t.Errorf("focus = %d after two shift-tabs, want episodes", app.focus)
+	}
+	press(app, "\x1b[D")
+	if app.focus != historyPane {
+		t.Errorf("focus = %d after left, want history", app.focus)
+	}
+
+	// Enter on a series opens its episodes
+	press(app, "3G\r")
+	if app.focus != episodesPane || app.episodesOf == nil || app.episodesOf.ID != 2 {
+		t.Errorf("enter should open the episodes of the last series, got %+v", app.episodesOf)
+	}
+	if !strings.Contains(screen(app), "Episodes: Better Call Saul") {
+		t.Errorf("episodes pane should be titled with the series:\n%s", screen(app))
+	}
+
+	if !press(app, "q") {
+		t.Error("q should quit")
+	}
+	if !press(app, "\x03") {
+		t.Error("Ctrl-C should quit")
+	}
+}
+
+func TestQueueActions(t *testing.T) {
+	app, _, sabnzbd := newApp(t)
+
+	press(app, "jp")
+	press(app, "r")
+	press(app, "P")
+	press(app, "R")
+
+	// Deleting asks first
+	press(app, "d")
+	if !strings.Contains(screen(app), "Delete Show.S01E02? (y/n)") {
+		t.Errorf("delete should ask first:\n%s", screen(app))
+	}
+	press(app, "n")
+	if app.message != "Cancelled" {
+		t.Errorf("message = %q after n, want Cancelled", app.message)
+	}
+	press(app, "dy")
+	if app.message != "Deleted Show.S01E02" {
+		t.Errorf("message = %q after y, want Deleted Show.S01E02", app.message)
+	}
+
+	want := []string{"pause SABnzbd_nzo_2", "resume SABnzbd_nzo_2", "pause all", "resume all", "delete SABnzbd_nzo_2"}
+	if !reflect.DeepEqual(sabnzbd.calls, want) {
+		t.Errorf("calls = %q, want %q", sabnzbd.calls, want)
+	}
+}
+
+func TestSeriesActions(t *testing.T) {
+	app, sonarr, _ := newApp(t)
+
+	press(app, "3m")
+	if app.message != "Stopped monitoring Breaking Bad" {
+		t.Errorf("message = %q", app.message)
+	}
+	// The library is reloaded to show the change
+	if app.series[0].Monitored {
+		t.Error("series should show as unmonitored after m")
+	}
+	press(app, "m")
+	press(app, "s")
+	press(app, "4js")
+
+	want := []string{"update 1 monitored=false", "update 1 monitored=true", "search series 1", "search episodes [102]"}
+	if !reflect.DeepEqual(sonarr.calls, want) {
+		t.Errorf("calls = %q, want %q", sonarr.calls, want)
+	}
+	if app.message != "Searching for Breaking Bad S01E02" {
+		t.Errorf("message = %q", app.message)
+	}
+
+	sonarr.failWith = errors.New("indexer unavailable")
+	press(app, "s")
+	if app.message != "indexer unavailable" || app.messageOK {
+		t.Errorf("a failed search should be shown, got %q", app.message)
+	}
+}
+
+func TestPoster(t *testing.T) {
+	app, _, _ := newApp(t)
+
+	press(app, "3v")
+	if !strings.Contains(screen(app), "poster of Breaking Bad 58x12") {
+		t.Errorf("v should show the poster in the episodes pane:\n%s", screen(app))
+	}
+	press(app, "\x1b")
+	if strings.Contains(screen(app), "poster of") {
+		t.Error("esc should close the poster")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := ParseKeys([]byte("a\r\t\x1b[A\x1bOB\x1b[5~\x1b[Z\x1b[99~é\x03\x1b"))
+	want := []Key{
+		{Code: KeyRune, Rune: 'a'}, {Code: KeyEnter}, {Code: KeyTab}, {Code: KeyUp}, {Code: KeyDown},
+		{Code: KeyPageUp}, {Code: KeyBackTab}, {Code: KeyUnknown}, {Code: KeyRune, Rune: 'é'},
+		{Code: KeyCtrlC}, {Code: KeyEscape},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeys = %v, want %v", got, want)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 3, "abc"},
+		{"ép", 1, "é"},
+		{"\x1b[1mbold\x1b[0m", 2, "\x1b[1mbo\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := fit(tt.in, tt.width); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sonarr-sabnzbd-cli/internal/models"
+	"sonarr-sabnzbd-cli/internal/output"
+)
+
+// reverse shows the selected row in reverse video, which works with
+// colors off too
+const (
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+)
+
+// paneHelp lists the actions of each pane in the status bar
+var paneHelp = [paneCount]string{
+	"p pause  r resume  d delete  P/R pause/resume all",
+	"",
+	"enter episodes  v poster  m monitor  s search",
+	"s search  v poster",
+}
+
+// View returns the screen as lines as wide as the screen: a header, the
+// panes in two columns and a status bar
+func (a *App) View() []string {
+	lines := []string{a.header()}
+
+	left := append(a.pane(queuePane), a.pane(historyPane)...)
+	right := append(a.pane(seriesPane), a.pane(episodesPane)...)
+	for i := range left {
+		lines = append(lines, left[i]+right[i])
+	}
+
+	return append(lines, a.statusBar())
+}
+
+// layout returns the width of the left column and the height of the top
+// row of panes
+func (a *App) layout() (leftWidth, topHeight int) {
+	return a.width / 2, (a.height - 2) / 2
+}
+
+// paneSize returns the outer size of a pane
+func (a *App) paneSize(p pane) (width, height int) {
+	leftWidth, topHeight := a.layout()
+	width, height = leftWidth, topHeight
+	if p == seriesPane || p == episodesPane {
+		width = a.width - leftWidth
+	}
+	if p == historyPane || p == episodesPane {
+		height = a.height - 2 - topHeight
+	}
+	return width, height
+}
+
+// visibleRows returns how many rows of the focused pane fit on screen
+func (a *App) visibleRows() int {
+	_, height := a.paneSize(a.focus)
+	return max(1, height-3)
+}
+
+// header shows the panes with their keys and the state of the queue
+func (a *App) header() string {
+	var b strings.Builder
+	b.WriteString(output.Bold(" soncli "))
+	for p := range paneCount {
+		tab := fmt.Sprintf(" %d %s ", p+1, paneTitles[p])
+		if p == a.focus {
+			tab = reverse + tab + reset
+		}
+		b.WriteString(" " + tab)
+	}
+
+	state := ""
+	if a.queue != nil {
+		state = output.Green(a.queue.Status)
+		if a.queue.Paused {
+			state = output.Yellow("Paused")
+		}
+		state = fmt.Sprintf("%s  %s  %s left ", state, a.queue.Speed, a.queue.SizeLeft)
+	}
+	gap := a.width - textWidth(b.String()) - textWidth(state)
+	if gap < 1 {
+		return fit(b.String(), a.width)
+	}
+	return b.String() + strings.Repeat(" ", gap) + state
+}
+
+// statusBar shows a question, the outcome of the last action or help
+func (a *App) statusBar() string {
+	switch {
+	case a.confirm != "":
+		return fit(" "+output.Yellow(a.confirm), a.width)
+	case a.message != "" && a.messageOK:
+		return fit(" "+output.Green(a.message), a.width)
+	case a.message != "":
+		return fit(" "+output.Red(a.message), a.width)
+	}
+	help := "tab/1-4 pane  ↑↓ move  u refresh  q quit"
+	if paneHelp[a.focus] != "" {
+		help = paneHelp[a.focus] + "  │  " + help
+	}
+	return fit(" "+output.Faint(help), a.width)
+}
+
+// pane draws a pane in a box
+func (a *App) pane(p pane) []string {
+	width, height := a.paneSize(p)
+	inner, rows := width-2, height-2
+	title := paneTitles[p]
+	if p == episodesPane && a.episodesOf != nil {
+		title += ": " + a.episodesOf.Title
+	}
+
+	var content []string
+	switch {
+	case (p == queuePane || p == historyPane) && a.sabnzbd == nil:
+		content = wrap("Sabnzbd unavailable: "+errText(a.sabnzbdErr), inner, output.Red)
+	case (p == seriesPane || p == episodesPane) && a.sonarr == nil:
+		content = wrap("Sonarr unavailable: "+errText(a.sonarrErr), inner, output.Red)
+	case a.errs[p] != nil:
+		content = wrap(a.errs[p].Error(), inner, output.Red)
+	case p == episodesPane && a.showPoster:
+		content = strings.Split(a.posterText, "\n")
+		if a.posterText == "" {
+			content = []string{"Loading poster..."}
+		}
+	default:
+		content = a.list(p, inner, rows)
+	}
+
+	style := output.Faint
+	if p == a.focus {
+		style = output.Bold
+	}
+	label := " " + title + " "
+	if textWidth(label) > inner-1 {
+		label = fit(label, inner-1)
+	}
+	top := "┌─" + label + strings.Repeat("─", inner-1-textWidth(label)) + "┐"
+	lines := []string{style(top)}
+	for i := range rows {
+		line := ""
+		if i < len(content) {
+			line = content[i]
+		}
+		lines = append(lines, style("│")+fit(line, inner)+style("│"))
+	}
+	return append(lines, style("└"+strings.Repeat("─", inner)+"┘"))
+}
+
+// list draws the header and the rows of a pane that are in view, with the
+// cursor row highlighted in the focused pane
+func (a *App) list(p pane, width, height int) []string {
+	var header string
+	var rows []string
+	loaded := true
+
+	// Narrow panes leave out the columns that matter least
+	wide := width >= 50
+
+	switch p {
+	case queuePane:
+		widths, barWidth, progress := []int{0, 11, 4, 8}, 0, "   %"
+		if wide {
+			widths, barWidth, progress = []int{0, 11, 15, 8}, 10, "PROGRESS"
+		}
+		header = columns(width, 0, widths, "NAME", "STATUS", progress, "LEFT")
+		if loaded = a.queue != nil; loaded {
+			for _, slot := range a.queue.Slots {
+				percentage, _ := strconv.Atoi(slot.Percentage)
+				rows = append(rows, columns(width, 0, widths,
+					slot.Name, slot.Status, progressBar(percentage, barWidth), slot.TimeLeft))
+			}
+		}
+	case historyPane:
+		widths := []int{0, 10, 9}
+		if wide {
+			widths = append(widths, 8)
+		}
+		header = columns(width, 0, widths, "NAME", "STATUS", "SIZE", "DONE")
+		if loaded = a.history != nil; loaded {
+			for _, slot := range a.history.Slots {
+				done := ""
+				if slot.Completed > 0 {
+					done = output.Ago(time.Unix(slot.Completed, 0))
+				}
+				rows = append(rows, columns(width, 0, widths,
+					slot.Name, slot.Status, output.FormatBytes(slot.Bytes), done))
+			}
+		}
+	case seriesPane:
+		widths := []int{0, 4, 8, 3}
+		header = columns(width, 0, widths, "TITLE", "YEAR", "EPISODES", "MON")
+		loaded = a.series != nil
+		for _, series := range a.series {
+			rows = append(rows, columns(width, 0, widths,
+				series.Title, strconv.Itoa(series.Year),
+				fmt.Sprintf("%d/%d", series.Statistics.EpisodeFileCount, series.Statistics.EpisodeCount),
+				monitoredMark(series.Monitored)))
+		}
+	case episodesPane:
+		widths := []int{6, 0, 11}
+		if wide {
+			widths = append(widths, 10)
+		}
+		header = columns(width, 1, widths, "EP", "TITLE", "STATUS", "AIR DATE")
+		loaded = a.episodes != nil || a.episodesOf == nil
+		for _, episode := range a.episodes {
+			rows = append(rows, columns(width, 1, widths,
+				output.SxE(episode.SeasonNumber, episode.EpisodeNumber), episode.Title,
+				episodeStatus(episode), episode.AirDate))
+		}
+	}
+
+	if !loaded {
+		return []string{output.Faint("Loading...")}
+	}
+	if p == episodesPane && a.episodesOf == nil {
+		return []string{output.Faint("Press enter on a series to show its episodes")}
+	}
+	if len(rows) == 0 {
+		return []string{output.Faint("Nothing to show")}
+	}
+
+	lines := []string{output.Bold(header)}
+	visible := max(1, height-1)
+	offset := max(0, a.cursor[p]-visible+1)
+	for i := offset; i < len(rows) && i < offset+visible; i++ {
+		row := rows[i]
+		if i == a.cursor[p] && p == a.focus {
+			row = reverse + fit(row, width) + reset
+		}
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+// columns lays out cells in the given widths, separated by a space, leaving
+// out cells past the last width. The column at flex takes the width the
+// others leave.
+func columns(width, flex int, widths []int, cells ...string) string {
+	used := len(widths) - 1
+	for i, w := range widths {
+		if i != flex {
+			used += w
+		}
+	}
+	parts := make([]string, len(widths))
+	for i, cell := range cells[:len(widths)] {
+		w := widths[i]
+		if i == flex {
+			w = max(1, width-used)
+		}
+		parts[i] = fit(cell, w)
+	}
+	return strings.Join(parts, " ")
+}
+
+// progressBar draws a percentage as a bar and a number, or just the number
+// when width is 0
+func progressBar(percentage, width int) string {
+	percentage = max(0, min(percentage, 100))
+	if width == 0 {
+		return fmt.Sprintf("%3d%%", percentage)
+	}
+	filled := percentage * width / 100
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + fmt.Sprintf(" %3d%%", percentage)
+}
+
+// monitoredMark shows whether a series is monitored
+func monitoredMark(monitored bool) string {
+	if monitored {
+		return "●"
+	}
+	return "○"
+}
+
+// episodeStatus describes whether an episode is downloaded or wanted
+func episodeStatus(episode models.Episode) string {
+	switch {
+	case episode.HasFile:
+		return "Downloaded"
+	case episode.Monitored:
+		return "Missing"
+	default:
+		return "Unmonitored"
+	}
+}
+
+// errText returns the message of err, which may be nil
+func errText(err error) string {
+	if err == nil {
+		return "not configured"
+	}
+	return err.Error()
+}
+
+// wrap breaks text into styled lines of at most width characters at
+// spaces; words longer than a line are cut when drawn
+func wrap(text string, width int, style func(string) string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case textWidth(line)+1+textWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, style(line))
+			line = word
+		}
+	}
+	return append(lines, style(line))
+}
+
+// plain leaves text unstyled
+func plain(s string) string {
+	return s
+}
+
+// textWidth returns the number of characters shown for s
+func textWidth(s string) int {
+	return utf8.RuneCountInString(output.StripColor(s))
+}
+
+// fit cuts or pads s to width characters, keeping its escape sequences
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	shown, escaped := 0, false
+	for i := 0; i < len(s); {
+		// Copy escape sequences without counting them
+		if s[i] == 0x1b {
+			end := i + 1
+			for end < len(s) && !(s[end] >= 'A' && s[end] <= 'Z' || s[end] >= 'a' && s[end] <= 'z') {
+				end++
+			}
+			end = min(end+1, len(s))
+			b.WriteString(s[i:end])
+			i, escaped = end, true
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if shown == width {
+			break
+		}
+		b.WriteRune(r)
+		shown++
+		i += n
+	}
+	if escaped {
+		b.WriteString(reset)
+	}
+	return b.String() + strings.Repeat(" ", width-shown)
+}